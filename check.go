@@ -0,0 +1,289 @@
+package ileap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Severity is the severity of a [Finding].
+type Severity int
+
+const (
+	// SeverityWarning is the severity of findings that are likely mistakes,
+	// but do not violate the iLEAP Technical Specifications.
+	SeverityWarning Severity = iota + 1
+	// SeverityError is the severity of findings that violate the iLEAP
+	// Technical Specifications.
+	SeverityError
+)
+
+// String returns "warning" or "error".
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Finding is a semantic inconsistency found by [Check].
+type Finding struct {
+	// Severity is the severity of the finding.
+	Severity Severity `json:"severity"`
+	// Path is the path of the inconsistent field, using JSON field names and
+	// starting at the index of the footprint, e.g.
+	// "[0].extensions[0].data.tces[1].arrivalAt".
+	Path string `json:"path"`
+	// RuleID identifies the checked rule, e.g. "ileap.tce.toc_reference".
+	RuleID string `json:"ruleId"`
+	// Message is a human readable description of the finding.
+	Message string `json:"message"`
+}
+
+// String returns the finding formatted as "severity: path: message".
+func (f *Finding) String() string {
+	return f.Severity.String() + ": " + f.Path + ": " + f.Message
+}
+
+// CheckReport is the result of [Check].
+type CheckReport struct {
+	// Findings are the findings of the check.
+	Findings []*Finding
+}
+
+// Errors returns the findings with [SeverityError].
+func (r *CheckReport) Errors() []*Finding {
+	return r.filter(SeverityError)
+}
+
+// Warnings returns the findings with [SeverityWarning].
+func (r *CheckReport) Warnings() []*Finding {
+	return r.filter(SeverityWarning)
+}
+
+// Err returns a [*CheckError] with the error findings of the report, or nil
+// if there are none. Warnings do not fail a check.
+func (r *CheckReport) Err() error {
+	if errs := r.Errors(); len(errs) > 0 {
+		return &CheckError{Findings: errs}
+	}
+	return nil
+}
+
+func (r *CheckReport) filter(severity Severity) []*Finding {
+	var result []*Finding
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// CheckError is returned by [CheckReport.Err] when footprints violate the
+// semantic rules of the iLEAP data model.
+type CheckError struct {
+	// Findings are the error findings of the check.
+	Findings []*Finding
+}
+
+// Error implements the error interface.
+func (e *CheckError) Error() string {
+	var b strings.Builder
+	b.WriteString("inconsistent iLEAP footprints: ")
+	for i, f := range e.Findings {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Path + ": " + f.Message)
+	}
+	return b.String()
+}
+
+// Check checks a set of footprints against the semantic rules of the iLEAP
+// data model that span fields and footprints, and are not covered by
+// [Validate]. Footprints should be validated before publication with both.
+//
+// The rules checked are:
+//
+//   - the relativeShare of the energy carriers of a TOC or HOC sum to 1,
+//   - each TCE references a TOC or HOC, which is in the footprint set,
+//   - the arrivalAt of a TCE follows its departureAt,
+//   - validityPeriodEnd follows validityPeriodStart, and
+//     pcf.referencePeriodEnd follows pcf.referencePeriodStart,
+//   - TOCs use TEUkm only for sea and inland waterway transport,
+//   - precedingPfIds does not contain the footprint's own id.
+//
+// TOCs and HOCs that no TCE in the set references are reported as warnings.
+// Values that are invalid according to [Validate], such as malformed decimals
+// or extension data, are skipped.
+func Check(pfs ...*ileapv1.ProductFootprint) *CheckReport {
+	c := &checker{
+		tocs: make(map[string]bool),
+		hocs: make(map[string]bool),
+	}
+	type indexedExtension struct {
+		path string
+		data proto.Message
+	}
+	var extensions []indexedExtension
+	for i, pf := range pfs {
+		path := "[" + strconv.Itoa(i) + "]"
+		c.checkFootprint(path, pf)
+		for j, ext := range pf.GetExtensions() {
+			data, err := ParseExtension(ext)
+			if err != nil {
+				continue
+			}
+			extPath := path + ".extensions[" + strconv.Itoa(j) + "].data"
+			switch data := data.(type) {
+			case *ileapv1.TOC:
+				c.tocs[data.GetTocId()] = false
+			case *ileapv1.HOC:
+				c.hocs[data.GetHocId()] = false
+			}
+			extensions = append(extensions, indexedExtension{path: extPath, data: data})
+		}
+	}
+	for _, ext := range extensions {
+		switch data := ext.data.(type) {
+		case *ileapv1.ShipmentFootprint:
+			c.checkShipmentFootprint(ext.path, data)
+		case *ileapv1.TOC:
+			c.checkTOC(ext.path, data)
+		case *ileapv1.HOC:
+			c.checkEnergyCarriers(ext.path, data.GetEnergyCarriers())
+		}
+	}
+	for _, ext := range extensions {
+		switch data := ext.data.(type) {
+		case *ileapv1.TOC:
+			if !c.tocs[data.GetTocId()] {
+				c.warnf(ext.path+".tocId", "ileap.toc.unreferenced",
+					"TOC %q is not referenced by any TCE", data.GetTocId())
+			}
+		case *ileapv1.HOC:
+			if !c.hocs[data.GetHocId()] {
+				c.warnf(ext.path+".hocId", "ileap.hoc.unreferenced",
+					"HOC %q is not referenced by any TCE", data.GetHocId())
+			}
+		}
+	}
+	return &CheckReport{Findings: c.findings}
+}
+
+// checker accumulates the findings of a [Check].
+type checker struct {
+	// tocs and hocs are the IDs of the TOCs and HOCs in the set, and whether
+	// a TCE references them.
+	tocs     map[string]bool
+	hocs     map[string]bool
+	findings []*Finding
+}
+
+func (c *checker) checkFootprint(path string, pf *ileapv1.ProductFootprint) {
+	if pf.HasValidityPeriodStart() && pf.HasValidityPeriodEnd() &&
+		!pf.GetValidityPeriodEnd().AsTime().After(pf.GetValidityPeriodStart().AsTime()) {
+		c.errorf(path+".validityPeriodEnd", "pact.validity_period",
+			"validityPeriodEnd must be after validityPeriodStart")
+	}
+	if pcf := pf.GetPcf(); pcf.HasReferencePeriodStart() && pcf.HasReferencePeriodEnd() &&
+		!pcf.GetReferencePeriodEnd().AsTime().After(pcf.GetReferencePeriodStart().AsTime()) {
+		c.errorf(path+".pcf.referencePeriodEnd", "pact.reference_period",
+			"referencePeriodEnd must be after referencePeriodStart")
+	}
+	for i, id := range pf.GetPrecedingPfIds() {
+		if id != "" && id == pf.GetId() {
+			c.errorf(path+".precedingPfIds["+strconv.Itoa(i)+"]", "pact.preceding_pf_ids",
+				"precedingPfIds must not contain the footprint's own id %q", id)
+		}
+	}
+}
+
+func (c *checker) checkShipmentFootprint(path string, sf *ileapv1.ShipmentFootprint) {
+	for i, tce := range sf.GetTces() {
+		tcePath := path + ".tces[" + strconv.Itoa(i) + "]"
+		if tce.GetTocId() == "" && tce.GetHocId() == "" {
+			c.errorf(tcePath, "ileap.tce.missing_reference",
+				"TCE %q references neither a TOC nor a HOC", tce.GetTceId())
+		}
+		if tce.HasTocId() {
+			if _, ok := c.tocs[tce.GetTocId()]; !ok {
+				c.errorf(tcePath+".tocId", "ileap.tce.toc_reference",
+					"TOC %q is not in the footprint set", tce.GetTocId())
+			} else {
+				c.tocs[tce.GetTocId()] = true
+			}
+		}
+		if tce.HasHocId() {
+			if _, ok := c.hocs[tce.GetHocId()]; !ok {
+				c.errorf(tcePath+".hocId", "ileap.tce.hoc_reference",
+					"HOC %q is not in the footprint set", tce.GetHocId())
+			} else {
+				c.hocs[tce.GetHocId()] = true
+			}
+		}
+		if tce.HasDepartureAt() && tce.HasArrivalAt() &&
+			!tce.GetArrivalAt().AsTime().After(tce.GetDepartureAt().AsTime()) {
+			c.errorf(tcePath+".arrivalAt", "ileap.tce.arrival_after_departure",
+				"arrivalAt must be after departureAt")
+		}
+	}
+}
+
+func (c *checker) checkTOC(path string, toc *ileapv1.TOC) {
+	if toc.GetTransportActivityUnit() == "TEUkm" &&
+		toc.GetMode() != "Sea" && toc.GetMode() != "InlandWaterway" {
+		c.errorf(path+".transportActivityUnit", "ileap.toc.teukm_mode",
+			"TEUkm is only used for sea and inland waterway transport, not %s", toc.GetMode())
+	}
+	c.checkEnergyCarriers(path, toc.GetEnergyCarriers())
+}
+
+func (c *checker) checkEnergyCarriers(path string, carriers []*ileapv1.EnergyCarrier) {
+	if len(carriers) == 0 {
+		return
+	}
+	shares := make([]ileapdecimal.Decimal, 0, len(carriers))
+	for _, carrier := range carriers {
+		share, err := carrier.GetRelativeShareDecimal()
+		if err != nil {
+			return
+		}
+		shares = append(shares, share)
+	}
+	if sum := ileapdecimal.Sum(shares...); !sum.Equal(ileapdecimal.NewFromInt(1)) {
+		c.errorf(path+".energyCarriers", "ileap.energy_carriers.relative_share",
+			"the relativeShare of the energy carriers must sum to 1, got %s", sum)
+	}
+}
+
+func (c *checker) errorf(path, ruleID, format string, args ...any) {
+	c.add(SeverityError, path, ruleID, format, args...)
+}
+
+func (c *checker) warnf(path, ruleID, format string, args ...any) {
+	c.add(SeverityWarning, path, ruleID, format, args...)
+}
+
+func (c *checker) add(severity Severity, path, ruleID, format string, args ...any) {
+	c.findings = append(c.findings, &Finding{
+		Severity: severity,
+		Path:     path,
+		RuleID:   ruleID,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,169 @@
+package ileap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	build := func(
+		t *testing.T,
+		modify func(sf *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC,
+	) []*ileapv1.ProductFootprint {
+		t.Helper()
+		sf, tocs := newTestShipment(t), []*ileapv1.TOC{newTestTOC(t)}
+		if modify != nil {
+			tocs = modify(sf, tocs)
+		}
+		pfs, err := newTestFootprintBuilder(now).Build(sf, tocs, []*ileapv1.HOC{newTestHOC(t)})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		return pfs
+	}
+
+	t.Run("consistent", func(t *testing.T) {
+		report := Check(build(t, nil)...)
+		if len(report.Findings) != 0 || report.Err() != nil {
+			t.Errorf("expected no findings, got %v", report.Findings)
+		}
+	})
+
+	t.Run("TCE without TOC or HOC", func(t *testing.T) {
+		// Build rejects such TCEs, so the footprint is assembled directly.
+		tce := newTestTCE(t)
+		tce.ClearTocId()
+		report := Check(newTestShipmentFootprint(t, tce))
+		if len(report.Findings) != 1 {
+			t.Fatalf("expected 1 finding, got %v", report.Findings)
+		}
+		got := report.Findings[0]
+		if got.Severity != SeverityError || got.Path != "[0].extensions[0].data.tces[0]" ||
+			got.RuleID != "ileap.tce.missing_reference" {
+			t.Errorf("got finding %v [%s]", got, got.RuleID)
+		}
+	})
+
+	for _, tc := range []struct {
+		name     string
+		modify   func(sf *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC
+		modifyPF func(pfs []*ileapv1.ProductFootprint)
+		want     Finding
+	}{
+		{
+			name: "relative shares",
+			modify: func(_ *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC {
+				hvo := &ileapv1.EnergyCarrier{}
+				hvo.SetEnergyCarrier("HVO")
+				hvo.SetRelativeShare("0.3")
+				tocs[0].GetEnergyCarriers()[0].SetRelativeShare("0.6")
+				tocs[0].SetEnergyCarriers(append(tocs[0].GetEnergyCarriers(), hvo))
+				return tocs
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[1].extensions[0].data.energyCarriers",
+				RuleID:   "ileap.energy_carriers.relative_share",
+			},
+		},
+		{
+			name: "missing TOC",
+			modify: func(*ileapv1.ShipmentFootprint, []*ileapv1.TOC) []*ileapv1.TOC {
+				return nil
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[0].extensions[0].data.tces[0].tocId",
+				RuleID:   "ileap.tce.toc_reference",
+			},
+		},
+		{
+			name: "arrival before departure",
+			modify: func(sf *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC {
+				tce := sf.GetTces()[0]
+				tce.SetArrivalAt(timestamppb.New(tce.GetDepartureAt().AsTime().Add(-time.Hour)))
+				return tocs
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[0].extensions[0].data.tces[0].arrivalAt",
+				RuleID:   "ileap.tce.arrival_after_departure",
+			},
+		},
+		{
+			name: "TEUkm for road",
+			modify: func(_ *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC {
+				tocs[0].SetTransportActivityUnit("TEUkm")
+				return tocs
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[1].extensions[0].data.transportActivityUnit",
+				RuleID:   "ileap.toc.teukm_mode",
+			},
+		},
+		{
+			name: "unreferenced TOC",
+			modify: func(_ *ileapv1.ShipmentFootprint, tocs []*ileapv1.TOC) []*ileapv1.TOC {
+				unused := newTestTOC(t)
+				unused.SetTocId("toc-unused")
+				return append(tocs, unused)
+			},
+			want: Finding{
+				Severity: SeverityWarning,
+				Path:     "[2].extensions[0].data.tocId",
+				RuleID:   "ileap.toc.unreferenced",
+			},
+		},
+		{
+			name: "validity period",
+			modifyPF: func(pfs []*ileapv1.ProductFootprint) {
+				pfs[0].SetValidityPeriodStart(timestamppb.New(now))
+				pfs[0].SetValidityPeriodEnd(timestamppb.New(now))
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[0].validityPeriodEnd",
+				RuleID:   "pact.validity_period",
+			},
+		},
+		{
+			name: "preceding footprint",
+			modifyPF: func(pfs []*ileapv1.ProductFootprint) {
+				pfs[2].SetPrecedingPfIds([]string{pfs[1].GetId(), pfs[2].GetId()})
+			},
+			want: Finding{
+				Severity: SeverityError,
+				Path:     "[2].precedingPfIds[1]",
+				RuleID:   "pact.preceding_pf_ids",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pfs := build(t, tc.modify)
+			if tc.modifyPF != nil {
+				tc.modifyPF(pfs)
+			}
+			report := Check(pfs...)
+			if len(report.Findings) != 1 {
+				t.Fatalf("expected 1 finding, got %v", report.Findings)
+			}
+			got := report.Findings[0]
+			if got.Severity != tc.want.Severity || got.Path != tc.want.Path ||
+				got.RuleID != tc.want.RuleID || got.Message == "" {
+				t.Errorf("got finding %v [%s], want %s: %s [%s]",
+					got, got.RuleID, tc.want.Severity, tc.want.Path, tc.want.RuleID)
+			}
+			var checkErr *CheckError
+			if err := report.Err(); errors.As(err, &checkErr) !=
+				(tc.want.Severity == SeverityError) {
+				t.Errorf("unexpected error for %s finding: %v", tc.want.Severity, err)
+			}
+		})
+	}
+}
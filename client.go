@@ -35,6 +35,9 @@ func NewClient(opts ...ClientOption) *Client {
 	if config.retryCount > 0 {
 		transport = &retryTransport{maxRetries: config.retryCount, next: transport}
 	}
+	if config.telemetry != nil {
+		transport = config.telemetry(transport)
+	}
 	return &Client{
 		config:     config,
 		httpClient: &http.Client{Transport: transport},
@@ -55,6 +58,14 @@ func (c *Client) newRequest(
 	if err != nil {
 		return nil, fmt.Errorf("invalid request URL: %w", err)
 	}
+	return c.newRequestURL(ctx, method, requestURL, body)
+}
+
+func (c *Client) newRequestURL(
+	ctx context.Context,
+	method, requestURL string,
+	body io.Reader,
+) (*http.Request, error) {
 	request, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,148 @@
+package ileap
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ResponseCache stores the footprint responses of a [Client] for
+// revalidation, see [WithResponseCache]. Implementations must be safe for
+// concurrent use.
+type ResponseCache interface {
+	// Get returns the cached response with the key, if present.
+	Get(key string) (*CachedResponse, bool)
+	// Set caches a response with the key, replacing any previous response.
+	Set(key string, response *CachedResponse)
+}
+
+// CachedResponse is a response cached in a [ResponseCache].
+type CachedResponse struct {
+	// Header is the header of the response, including its ETag and
+	// Last-Modified validators.
+	Header http.Header
+	// Body is the body of the response.
+	Body []byte
+}
+
+// WithResponseCache makes the [Client] cache the responses of
+// [Client.GetFootprint] and [Client.ListFootprints] that carry an ETag or
+// Last-Modified validator. Later requests for the same URL are sent as
+// conditional requests, and a 304 Not Modified response is answered from the
+// cache. Do not share a cache between clients with different credentials.
+func WithResponseCache(cache ResponseCache) ClientOption {
+	return func(cc *ClientConfig) {
+		cc.responseCache = cache
+	}
+}
+
+// doRevalidated sends a GET request, revalidating the cached response of its
+// URL if there is one.
+func (c *Client) doRevalidated(request *http.Request) (*http.Response, error) {
+	cache := c.config.responseCache
+	if cache == nil {
+		return c.httpClient.Do(request)
+	}
+	key := request.URL.String()
+	cached, ok := cache.Get(key)
+	if ok {
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			request.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok && response.StatusCode == http.StatusNotModified:
+		_ = response.Body.Close()
+		// The 304 response carries the current values of the headers it has.
+		header := cached.Header.Clone()
+		for name, values := range response.Header {
+			header[name] = values
+		}
+		response.StatusCode = http.StatusOK
+		response.Status = "200 OK"
+		response.Header = header
+		response.Body = io.NopCloser(bytes.NewReader(cached.Body))
+		response.ContentLength = int64(len(cached.Body))
+	case response.StatusCode == http.StatusOK &&
+		(response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != ""):
+		body, err := io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read response body: %w", err)
+		}
+		cache.Set(key, &CachedResponse{Header: response.Header.Clone(), Body: body})
+		response.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return response, nil
+}
+
+// MemoryResponseCache is an in-memory [ResponseCache] that evicts the least
+// recently used responses beyond a maximum number. It is safe for concurrent
+// use.
+type MemoryResponseCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+var _ ResponseCache = (*MemoryResponseCache)(nil)
+
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+}
+
+// NewMemoryResponseCache creates a new MemoryResponseCache holding up to
+// maxEntries responses.
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements [ResponseCache].
+func (c *MemoryResponseCache) Get(key string) (*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).response, true
+}
+
+// Set implements [ResponseCache].
+func (c *MemoryResponseCache) Set(key string, response *CachedResponse) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &memoryCacheEntry{key: key, response: response}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.lru.PushFront(e)
+	}
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
@@ -0,0 +1,114 @@
+package ileap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"golang.org/x/oauth2"
+)
+
+// statusLog records the status codes of the responses of a handler.
+type statusLog struct {
+	mu       sync.Mutex
+	statuses []int
+}
+
+func (l *statusLog) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.statuses = append(l.statuses, rec.status)
+	})
+}
+
+func (l *statusLog) last() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.statuses[len(l.statuses)-1]
+}
+
+func TestClientResponseCache(t *testing.T) {
+	handler := &mockServiceHandler{}
+	for _, id := range []string{"fp-1", "fp-2"} {
+		fp := &ileapv1.ProductFootprint{}
+		fp.SetId(id)
+		fp.SetVersion(1)
+		handler.footprints = append(handler.footprints, fp)
+	}
+	var log statusLog
+	srv := httptest.NewServer(log.wrap(NewServer(
+		WithAuthHandler(&mockAuthHandler{validToken: true}),
+		WithServiceHandler(handler),
+	)))
+	t.Cleanup(srv.Close)
+	client := NewClient(
+		WithBaseURL(srv.URL),
+		WithReuseTokenAuth(&oauth2.Token{AccessToken: "valid", TokenType: "Bearer"}),
+		WithResponseCache(NewMemoryResponseCache(10)),
+	)
+	getVersion := func(t *testing.T) int32 {
+		t.Helper()
+		fp, err := client.GetFootprint(t.Context(), &GetFootprintRequest{ID: "fp-1"})
+		if err != nil {
+			t.Fatalf("GetFootprint: %v", err)
+		}
+		return fp.GetVersion()
+	}
+	listPage := func(t *testing.T) *FootprintsPage {
+		t.Helper()
+		for page, err := range client.FootprintPages(t.Context(), &ListFootprintsParams{Limit: 1}) {
+			if err != nil {
+				t.Fatalf("FootprintPages: %v", err)
+			}
+			return page
+		}
+		t.Fatal("expected a page")
+		return nil
+	}
+
+	t.Run("get footprint", func(t *testing.T) {
+		if v := getVersion(t); v != 1 || log.last() != http.StatusOK {
+			t.Fatalf("expected version 1 with 200, got %d with %d", v, log.last())
+		}
+		if v := getVersion(t); v != 1 || log.last() != http.StatusNotModified {
+			t.Fatalf("expected cached version 1 with 304, got %d with %d", v, log.last())
+		}
+		handler.footprints[0].SetVersion(2)
+		if v := getVersion(t); v != 2 || log.last() != http.StatusOK {
+			t.Fatalf("expected version 2 with 200, got %d with %d", v, log.last())
+		}
+	})
+
+	t.Run("list footprints", func(t *testing.T) {
+		first := listPage(t)
+		second := listPage(t)
+		if log.last() != http.StatusNotModified {
+			t.Fatalf("expected 304, got %d", log.last())
+		}
+		if len(second.Footprints) != 1 || second.Footprints[0].GetId() != "fp-1" {
+			t.Errorf("unexpected cached page: %v", second.Footprints)
+		}
+		if second.NextURL == "" || second.NextURL != first.NextURL {
+			t.Errorf("NextURL = %q, want %q", second.NextURL, first.NextURL)
+		}
+	})
+}
+
+func TestMemoryResponseCache(t *testing.T) {
+	c := NewMemoryResponseCache(2)
+	for _, key := range []string{"a", "b", "a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			c.Set(key, &CachedResponse{Body: []byte(key)})
+		}
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%q) cached = %v, want %v", key, ok, want)
+		}
+	}
+}
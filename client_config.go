@@ -4,6 +4,9 @@ import (
 	"context"
 	"net/http"
 
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
@@ -13,11 +16,15 @@ const DemoBaseURL = "https://api.ileap.sine.dev"
 
 // ClientConfig is the configuration for a [Client].
 type ClientConfig struct {
-	baseURL      string
-	retryCount   int
-	debug        bool
-	interceptors []func(http.RoundTripper) http.RoundTripper
-	auth         func(http.RoundTripper) http.RoundTripper
+	baseURL       string
+	eventSource   string
+	retryCount    int
+	debug         bool
+	strict        bool
+	responseCache ResponseCache
+	interceptors  []func(http.RoundTripper) http.RoundTripper
+	auth          func(http.RoundTripper) http.RoundTripper
+	telemetry     func(http.RoundTripper) http.RoundTripper
 }
 
 // newClientConfig creates a new default [ClientConfig].
@@ -35,6 +42,17 @@ func WithBaseURL(baseURL string) ClientOption {
 	}
 }
 
+// WithEventSource sets the CloudEvents source URI of events sent by the [Client].
+//
+// The source identifies the sending host system, e.g. "//events.example.com/2/events".
+// Receivers of a ProductFootprintRequest.Created.v1 event send their response
+// to the events endpoint of this source, so it is required to send events.
+func WithEventSource(source string) ClientOption {
+	return func(cc *ClientConfig) {
+		cc.eventSource = source
+	}
+}
+
 // WithOAuth2 authenticates requests using OAuth 2.0.
 func WithOAuth2(clientID, clientSecret string) ClientOption {
 	return func(cc *ClientConfig) {
@@ -72,6 +90,17 @@ func WithRetryCount(retryCount int) ClientOption {
 	}
 }
 
+// WithStrictDecoding toggles strict decoding of footprints and TADs.
+//
+// In strict mode, unknown JSON fields are rejected and decoded messages are
+// checked against the iLEAP data model rules with [Validate]. Violations are
+// returned as a [*ValidationError].
+func WithStrictDecoding(strict bool) ClientOption {
+	return func(cc *ClientConfig) {
+		cc.strict = strict
+	}
+}
+
 // WithDebug toggles debug mode (request/response dumps to stderr).
 func WithDebug(debug bool) ClientOption {
 	return func(cc *ClientConfig) {
@@ -85,3 +114,22 @@ func WithInterceptor(interceptor func(http.RoundTripper) http.RoundTripper) Clie
 		cc.interceptors = append(cc.interceptors, interceptor)
 	}
 }
+
+// WithClientTelemetry traces the requests of the [Client] and records HTTP
+// client metrics with OpenTelemetry. The trace context of the request context
+// is propagated to the server with the global propagator. Retries of a request
+// are traced in the same span. Nil providers default to the global providers.
+func WithClientTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) ClientOption {
+	return func(cc *ClientConfig) {
+		var opts []otelhttp.Option
+		if tp != nil {
+			opts = append(opts, otelhttp.WithTracerProvider(tp))
+		}
+		if mp != nil {
+			opts = append(opts, otelhttp.WithMeterProvider(mp))
+		}
+		cc.telemetry = func(next http.RoundTripper) http.RoundTripper {
+			return otelhttp.NewTransport(next, opts...)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package ileap
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// RequestFootprintParams is the request parameters for the [Client.RequestFootprint] method.
+type RequestFootprintParams struct {
+	// Footprint is a partial ProductFootprint whose set properties describe the
+	// requested footprints, e.g. productIds. Required.
+	Footprint *ileapv1.ProductFootprint
+	// Comment is an optional free-text comment for the data owner.
+	Comment string
+}
+
+// FulfillRequestParams is the request parameters for the [Client.FulfillRequest] method.
+type FulfillRequestParams struct {
+	// RequestEventID is the ID of the ProductFootprintRequest.Created.v1 event
+	// being fulfilled. Required.
+	RequestEventID string
+	// Footprints are the footprints fulfilling the request.
+	Footprints []*ileapv1.ProductFootprint
+}
+
+// RejectRequestParams is the request parameters for the [Client.RejectRequest] method.
+type RejectRequestParams struct {
+	// RequestEventID is the ID of the ProductFootprintRequest.Created.v1 event
+	// being rejected. Required.
+	RequestEventID string
+	// Error is the reason for the rejection. Required.
+	Error *Error
+}
+
+// NotifyPublishedParams is the request parameters for the [Client.NotifyPublished] method.
+type NotifyPublishedParams struct {
+	// FootprintIDs are the IDs of the published footprints. Required.
+	FootprintIDs []string
+}
+
+// RequestFootprint sends a ProductFootprintRequest.Created.v1 event, asking the
+// data owner to asynchronously send the requested footprints.
+//
+// The returned metadata holds the ID of the sent event, which the data owner
+// refers to in its Fulfilled or Rejected response event.
+func (c *Client) RequestFootprint(
+	ctx context.Context,
+	request *RequestFootprintParams,
+) (_ *EventMetadata, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("request iLEAP footprint: %w", err)
+		}
+	}()
+	if request.Footprint == nil {
+		return nil, errors.New("footprint is required")
+	}
+	pf, err := protojson.Marshal(request.Footprint)
+	if err != nil {
+		return nil, fmt.Errorf("marshal footprint: %w", err)
+	}
+	return c.sendEvent(ctx, eventTypeRequestCreatedV1, struct {
+		PF      json.RawMessage `json:"pf"`
+		Comment string          `json:"comment,omitempty"`
+	}{
+		PF:      pf,
+		Comment: request.Comment,
+	})
+}
+
+// FulfillRequest sends a ProductFootprintRequest.Fulfilled.v1 event in response
+// to a footprint request.
+func (c *Client) FulfillRequest(
+	ctx context.Context,
+	request *FulfillRequestParams,
+) (_ *EventMetadata, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("fulfill iLEAP footprint request: %w", err)
+		}
+	}()
+	if request.RequestEventID == "" {
+		return nil, errors.New("request event ID is required")
+	}
+	pfs := make([]json.RawMessage, 0, len(request.Footprints))
+	for _, footprint := range request.Footprints {
+		pf, err := protojson.Marshal(footprint)
+		if err != nil {
+			return nil, fmt.Errorf("marshal footprint: %w", err)
+		}
+		pfs = append(pfs, pf)
+	}
+	return c.sendEvent(ctx, eventTypeRequestFulfilledV1, struct {
+		RequestEventID string            `json:"requestEventId"`
+		PFs            []json.RawMessage `json:"pfs"`
+	}{
+		RequestEventID: request.RequestEventID,
+		PFs:            pfs,
+	})
+}
+
+// RejectRequest sends a ProductFootprintRequest.Rejected.v1 event in response
+// to a footprint request that cannot be fulfilled.
+func (c *Client) RejectRequest(
+	ctx context.Context,
+	request *RejectRequestParams,
+) (_ *EventMetadata, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("reject iLEAP footprint request: %w", err)
+		}
+	}()
+	if request.RequestEventID == "" {
+		return nil, errors.New("request event ID is required")
+	}
+	if request.Error == nil {
+		return nil, errors.New("error is required")
+	}
+	return c.sendEvent(ctx, eventTypeRequestRejectedV1, struct {
+		RequestEventID string `json:"requestEventId"`
+		Error          *Error `json:"error"`
+	}{
+		RequestEventID: request.RequestEventID,
+		Error:          request.Error,
+	})
+}
+
+// NotifyPublished sends a ProductFootprint.Published.v1 event, notifying the
+// data recipient that footprints were published or updated.
+func (c *Client) NotifyPublished(
+	ctx context.Context,
+	request *NotifyPublishedParams,
+) (_ *EventMetadata, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("notify iLEAP footprints published: %w", err)
+		}
+	}()
+	if len(request.FootprintIDs) == 0 {
+		return nil, errors.New("at least one footprint ID is required")
+	}
+	return c.sendEvent(ctx, eventTypePublishedV1, struct {
+		PFIDs []string `json:"pfIds"`
+	}{
+		PFIDs: request.FootprintIDs,
+	})
+}
+
+// sendEvent posts a structured-mode CloudEvent with the given type and data to /2/events.
+func (c *Client) sendEvent(ctx context.Context, t eventType, data any) (*EventMetadata, error) {
+	if c.config.eventSource == "" {
+		return nil, errors.New("event source is not configured")
+	}
+	id, err := newUUID()
+	if err != nil {
+		return nil, fmt.Errorf("generate event ID: %w", err)
+	}
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event data: %w", err)
+	}
+	metadata := &EventMetadata{
+		ID:     id,
+		Source: c.config.eventSource,
+		Time:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+	body, err := json.Marshal(cloudEventEnvelope{
+		Type:            string(t),
+		Specversion:     "1.0",
+		ID:              metadata.ID,
+		Source:          metadata.Source,
+		Time:            metadata.Time.Format(time.RFC3339Nano),
+		DataContentType: "application/json",
+		Data:            rawData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+	httpRequest, err := c.newRequest(ctx, http.MethodPost, "/2/events", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/cloudevents+json; charset=UTF-8")
+	// Receivers deduplicate events by source and id, so resending is safe.
+	httpRequest.Header.Set("Idempotency-Key", metadata.ID)
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer func() { _ = httpResponse.Body.Close() }()
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		return nil, newClientError(httpResponse)
+	}
+	return metadata, nil
+}
+
+// newUUID returns a random (version 4) UUID, e.g. to use as a CloudEvents id.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
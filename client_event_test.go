@@ -0,0 +1,165 @@
+package ileap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"golang.org/x/oauth2"
+)
+
+func newEventTestClient(t *testing.T, handler EventHandler, opts ...ClientOption) *Client {
+	t.Helper()
+	srv := httptest.NewServer(NewServer(
+		WithAuthHandler(&mockAuthHandler{validToken: true}),
+		WithEventHandler(handler),
+	))
+	t.Cleanup(srv.Close)
+	return NewClient(append([]ClientOption{
+		WithBaseURL(srv.URL),
+		WithReuseTokenAuth(&oauth2.Token{AccessToken: "valid", TokenType: "Bearer"}),
+		WithEventSource("//recipient.example.com/2/events"),
+	}, opts...)...)
+}
+
+func TestClientEvents(t *testing.T) {
+	handler := &recordingEventHandler{}
+	client := newEventTestClient(t, handler)
+	eventIDRegexp := regexp.MustCompile(
+		`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+	)
+
+	t.Run("request footprint", func(t *testing.T) {
+		pf := &ileapv1.ProductFootprint{}
+		pf.SetProductIds([]string{"urn:gtin:4712345060507"})
+		sent, err := client.RequestFootprint(t.Context(), &RequestFootprintParams{
+			Footprint: pf,
+			Comment:   "Please send PCF data.",
+		})
+		if err != nil {
+			t.Fatalf("RequestFootprint: %v", err)
+		}
+		if !eventIDRegexp.MatchString(sent.ID) {
+			t.Errorf("ID = %q, want a UUID v4", sent.ID)
+		}
+		got := handler.created
+		if got == nil {
+			t.Fatal("expected HandleRequestCreated to be called")
+		}
+		if got.ID != sent.ID || got.Source != "//recipient.example.com/2/events" ||
+			!got.Time.Equal(sent.Time) {
+			t.Errorf("metadata = %+v, want %+v", got.EventMetadata, *sent)
+		}
+		if ids := got.Footprint.GetProductIds(); len(ids) != 1 ||
+			ids[0] != "urn:gtin:4712345060507" {
+			t.Errorf("ProductIds = %v", ids)
+		}
+		if got.Comment != "Please send PCF data." {
+			t.Errorf("Comment = %q", got.Comment)
+		}
+	})
+
+	t.Run("fulfill request", func(t *testing.T) {
+		pf := &ileapv1.ProductFootprint{}
+		pf.SetId("91715e5e-fd0b-4d1c-8fab-76290c46e6ed")
+		_, err := client.FulfillRequest(t.Context(), &FulfillRequestParams{
+			RequestEventID: "evt-1",
+			Footprints:     []*ileapv1.ProductFootprint{pf},
+		})
+		if err != nil {
+			t.Fatalf("FulfillRequest: %v", err)
+		}
+		got := handler.fulfilled
+		if got == nil {
+			t.Fatal("expected HandleRequestFulfilled to be called")
+		}
+		if got.RequestEventID != "evt-1" {
+			t.Errorf("RequestEventID = %q", got.RequestEventID)
+		}
+		if len(got.Footprints) != 1 || got.Footprints[0].GetId() != pf.GetId() {
+			t.Errorf("Footprints = %v", got.Footprints)
+		}
+	})
+
+	t.Run("reject request", func(t *testing.T) {
+		_, err := client.RejectRequest(t.Context(), &RejectRequestParams{
+			RequestEventID: "evt-1",
+			Error:          &Error{Code: ErrorCodeNotFound, Message: "no footprints"},
+		})
+		if err != nil {
+			t.Fatalf("RejectRequest: %v", err)
+		}
+		got := handler.rejected
+		if got == nil {
+			t.Fatal("expected HandleRequestRejected to be called")
+		}
+		if got.Error.Code != ErrorCodeNotFound || got.Error.Message != "no footprints" {
+			t.Errorf("Error = %+v", got.Error)
+		}
+	})
+
+	t.Run("notify published", func(t *testing.T) {
+		_, err := client.NotifyPublished(t.Context(), &NotifyPublishedParams{
+			FootprintIDs: []string{"91715e5e-fd0b-4d1c-8fab-76290c46e6ed"},
+		})
+		if err != nil {
+			t.Fatalf("NotifyPublished: %v", err)
+		}
+		got := handler.published
+		if got == nil {
+			t.Fatal("expected HandlePublished to be called")
+		}
+		if len(got.FootprintIDs) != 1 ||
+			got.FootprintIDs[0] != "91715e5e-fd0b-4d1c-8fab-76290c46e6ed" {
+			t.Errorf("FootprintIDs = %v", got.FootprintIDs)
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := client.NotifyPublished(t.Context(), &NotifyPublishedParams{
+			FootprintIDs: []string{"not-a-uuid"},
+		})
+		var clientErr *ClientError
+		if !errors.As(err, &clientErr) {
+			t.Fatalf("expected *ClientError, got %v", err)
+		}
+		if clientErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("StatusCode = %d, want 400", clientErr.StatusCode)
+		}
+		var body *Error
+		if !errors.As(clientErr.Body, &body) || body.Code != ErrorCodeBadRequest {
+			t.Errorf("Body = %v, want BadRequest", clientErr.Body)
+		}
+	})
+
+	t.Run("missing required params", func(t *testing.T) {
+		if _, err := client.RequestFootprint(t.Context(), &RequestFootprintParams{}); err == nil {
+			t.Error("RequestFootprint: expected error for missing footprint")
+		}
+		if _, err := client.FulfillRequest(t.Context(), &FulfillRequestParams{}); err == nil {
+			t.Error("FulfillRequest: expected error for missing request event ID")
+		}
+		if _, err := client.RejectRequest(
+			t.Context(),
+			&RejectRequestParams{RequestEventID: "evt-1"},
+		); err == nil {
+			t.Error("RejectRequest: expected error for missing error")
+		}
+		if _, err := client.NotifyPublished(t.Context(), &NotifyPublishedParams{}); err == nil {
+			t.Error("NotifyPublished: expected error for missing footprint IDs")
+		}
+	})
+}
+
+func TestClientEventsWithoutSource(t *testing.T) {
+	client := newEventTestClient(t, NopEventHandler{}, WithEventSource(""))
+	_, err := client.NotifyPublished(t.Context(), &NotifyPublishedParams{
+		FootprintIDs: []string{"91715e5e-fd0b-4d1c-8fab-76290c46e6ed"},
+	})
+	if err == nil {
+		t.Fatal("expected error without event source")
+	}
+}
@@ -0,0 +1,119 @@
+package ileap
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// encodeFootprintFilter encodes filter pairs as an OData $filter expression,
+// conjoined with the raw filter expression if present.
+//
+// Field paths use dot notation. Paths that traverse a repeated field of
+// ProductFootprint are encoded as an OData any() lambda, e.g.
+// productIds/any(item:(item eq 'urn:...')).
+func encodeFootprintFilter(raw string, filters []*ileapv1.Filter) (string, error) {
+	clauses := make([]string, 0, len(filters)+1)
+	if raw = strings.TrimSpace(raw); raw != "" {
+		if len(filters) == 0 {
+			return raw, nil
+		}
+		clauses = append(clauses, "("+raw+")")
+	}
+	desc := (&ileapv1.ProductFootprint{}).ProtoReflect().Descriptor()
+	for _, filter := range filters {
+		clause, err := encodeODataClause(desc, filter)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, "("+clause+")")
+	}
+	return strings.Join(clauses, " and "), nil
+}
+
+func encodeODataClause(
+	desc protoreflect.MessageDescriptor,
+	filter *ileapv1.Filter,
+) (string, error) {
+	if filter.GetFieldPath() == "" {
+		return "", fmt.Errorf("filter: empty field path")
+	}
+	operator, err := odataOperator(filter.GetOperator())
+	if err != nil {
+		return "", err
+	}
+	value := "'" + strings.ReplaceAll(filter.GetValue(), "'", "''") + "'"
+	segments := strings.Split(filter.GetFieldPath(), ".")
+	for i, segment := range segments {
+		if desc == nil {
+			break
+		}
+		field := desc.Fields().ByJSONName(segment)
+		if field == nil {
+			break
+		}
+		if field.IsList() {
+			const alias = "item"
+			inner := append([]string{alias}, segments[i+1:]...)
+			return fmt.Sprintf(
+				"%s/any(%s:(%s %s %s))",
+				strings.Join(segments[:i+1], "/"),
+				alias,
+				strings.Join(inner, "/"),
+				operator,
+				value,
+			), nil
+		}
+		desc = field.Message()
+	}
+	return fmt.Sprintf("%s %s %s", strings.Join(segments, "/"), operator, value), nil
+}
+
+func odataOperator(operator ileapv1.Filter_Operator) (string, error) {
+	switch operator {
+	case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+		return "eq", nil
+	case ileapv1.Filter_NE:
+		return "ne", nil
+	case ileapv1.Filter_LT:
+		return "lt", nil
+	case ileapv1.Filter_LE:
+		return "le", nil
+	case ileapv1.Filter_GT:
+		return "gt", nil
+	case ileapv1.Filter_GE:
+		return "ge", nil
+	default:
+		return "", fmt.Errorf("filter: unsupported operator %v", operator)
+	}
+}
+
+// encodeTADFilters encodes filter pairs as iLEAP TAD key=value query parameters.
+//
+// The iLEAP TAD query syntax only supports equality, so any other operator is
+// rejected.
+func encodeTADFilters(query url.Values, filters []*ileapv1.Filter) error {
+	for _, filter := range filters {
+		if filter.GetFieldPath() == "" {
+			return fmt.Errorf("filter: empty field path")
+		}
+		switch filter.GetFieldPath() {
+		case "limit", "offset":
+			return fmt.Errorf("filter: reserved field path %q", filter.GetFieldPath())
+		}
+		switch filter.GetOperator() {
+		case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+		default:
+			return fmt.Errorf(
+				"filter %q: TAD filters only support the EQ operator, got %v",
+				filter.GetFieldPath(),
+				filter.GetOperator(),
+			)
+		}
+		query.Add(filter.GetFieldPath(), filter.GetValue())
+	}
+	return nil
+}
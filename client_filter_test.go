@@ -0,0 +1,167 @@
+package ileap
+
+import (
+	"net/url"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+func newTestFilter(
+	fieldPath string,
+	operator ileapv1.Filter_Operator,
+	value string,
+) *ileapv1.Filter {
+	filter := new(ileapv1.Filter)
+	filter.SetFieldPath(fieldPath)
+	filter.SetOperator(operator)
+	filter.SetValue(value)
+	return filter
+}
+
+func TestEncodeFootprintFilter(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		filters []*ileapv1.Filter
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: "",
+		},
+		{
+			name: "raw only",
+			raw:  "productCategoryCpc eq '83117'",
+			want: "productCategoryCpc eq '83117'",
+		},
+		{
+			name: "scalar and nested fields",
+			filters: []*ileapv1.Filter{
+				newTestFilter("productCategoryCpc", ileapv1.Filter_EQ, "83117"),
+				newTestFilter("pcf.geographyCountry", ileapv1.Filter_NE, "DE"),
+				newTestFilter("created", ileapv1.Filter_GE, "2024-01-01T00:00:00Z"),
+			},
+			want: "(productCategoryCpc eq '83117') and " +
+				"(pcf/geographyCountry ne 'DE') and " +
+				"(created ge '2024-01-01T00:00:00Z')",
+		},
+		{
+			name: "repeated fields use any",
+			filters: []*ileapv1.Filter{
+				newTestFilter("companyIds", ileapv1.Filter_OPERATOR_UNSPECIFIED, "urn:company:1"),
+				newTestFilter("extensions.dataSchema", ileapv1.Filter_EQ, "https://example.com"),
+			},
+			want: "(companyIds/any(item:(item eq 'urn:company:1'))) and " +
+				"(extensions/any(item:(item/dataSchema eq 'https://example.com')))",
+		},
+		{
+			name:    "raw and filters combined",
+			raw:     "status eq 'Active'",
+			filters: []*ileapv1.Filter{newTestFilter("companyName", ileapv1.Filter_EQ, "O'Brien")},
+			want:    "(status eq 'Active') and (companyName eq 'O''Brien')",
+		},
+		{
+			name:    "empty field path",
+			filters: []*ileapv1.Filter{newTestFilter("", ileapv1.Filter_EQ, "x")},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := encodeFootprintFilter(tc.raw, tc.filters)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncodeTADFilters(t *testing.T) {
+	t.Run("eq filters", func(t *testing.T) {
+		query := url.Values{}
+		err := encodeTADFilters(query, []*ileapv1.Filter{
+			newTestFilter("mode", ileapv1.Filter_EQ, "Road"),
+			newTestFilter(
+				"energyCarriers.feedstocks.feedstock",
+				ileapv1.Filter_OPERATOR_UNSPECIFIED,
+				"Fossil",
+			),
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, want := query.Encode(), "energyCarriers.feedstocks.feedstock=Fossil&mode=Road"; got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("unsupported operator", func(t *testing.T) {
+		err := encodeTADFilters(url.Values{}, []*ileapv1.Filter{
+			newTestFilter("mode", ileapv1.Filter_NE, "Road"),
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("reserved field path", func(t *testing.T) {
+		err := encodeTADFilters(url.Values{}, []*ileapv1.Filter{
+			newTestFilter("limit", ileapv1.Filter_EQ, "1"),
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestClientFiltersRoundTrip(t *testing.T) {
+	handler := &mockServiceHandler{}
+	client := newPaginationTestClient(t, handler)
+
+	t.Run("footprints", func(t *testing.T) {
+		_, err := client.ListFootprints(t.Context(), &ListFootprintsParams{
+			Offset: 3,
+			Filters: []*ileapv1.Filter{
+				newTestFilter("productIds", ileapv1.Filter_EQ, "urn:test:1"),
+				newTestFilter("pcf.geographyCountry", ileapv1.Filter_EQ, "DE"),
+			},
+		})
+		if err != nil {
+			t.Fatalf("ListFootprints: %v", err)
+		}
+		if got := handler.lastListFootprintsReq.GetOffset(); got != 3 {
+			t.Errorf("offset = %d, want 3", got)
+		}
+		assertFootprintFilterSet(
+			t,
+			handler.lastListFootprintsReq.GetFilters(),
+			"productIds|EQ|urn:test:1",
+			"pcf.geographyCountry|EQ|DE",
+		)
+	})
+
+	t.Run("tads", func(t *testing.T) {
+		_, err := client.ListTADs(t.Context(), &ListTADsParams{
+			Offset:  2,
+			Filters: []*ileapv1.Filter{newTestFilter("mode", ileapv1.Filter_EQ, "Road")},
+		})
+		if err != nil {
+			t.Fatalf("ListTADs: %v", err)
+		}
+		if got := handler.lastListTADReq.GetOffset(); got != 2 {
+			t.Errorf("offset = %d, want 2", got)
+		}
+		assertTADFilterSet(t, handler.lastListTADReq.GetFilters(), "mode|EQ|Road")
+	})
+}
@@ -31,7 +31,7 @@ func (c *Client) GetFootprint(
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	httpResponse, err := c.httpClient.Do(httpRequest)
+	httpResponse, err := c.doRevalidated(httpRequest)
 	if err != nil {
 		return nil, fmt.Errorf("send request: %w", err)
 	}
@@ -53,5 +53,10 @@ func (c *Client) GetFootprint(
 	if err := protojson.Unmarshal(response.Data, pf); err != nil {
 		return nil, fmt.Errorf("unmarshal footprint: %w", err)
 	}
+	if c.config.strict {
+		if err := validateData(pf); err != nil {
+			return nil, err
+		}
+	}
 	return pf, nil
 }
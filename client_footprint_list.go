@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,11 +18,34 @@ import (
 type ListFootprintsParams struct {
 	// Limit is the maximum number of footprints to return.
 	Limit int `json:"limit,omitempty"`
+	// Offset is the number of footprints to skip.
+	Offset int `json:"offset,omitempty"`
 	// Filter is the OData filter to apply to the request.
 	Filter string `json:"$filter,omitempty"`
+	// Filters are filter pairs to apply to the request.
+	//
+	// They are encoded into the OData $filter expression and conjoined with
+	// [ListFootprintsParams.Filter] if both are set. Field paths use dot
+	// notation with JSON field names, e.g. "pcf.geographyCountry".
+	Filters []*ileapv1.Filter `json:"-"`
+	// PageURL is the raw URL of a page to fetch, as returned in [FootprintsPage.NextURL].
+	// When set, all other parameters are ignored and listing resumes from that page.
+	PageURL string `json:"-"`
+}
+
+// FootprintsPage is a single page of product carbon footprints.
+type FootprintsPage struct {
+	// Footprints are the footprints on this page.
+	Footprints []*ileapv1.ProductFootprint
+	// NextURL is the raw URL of the next page, taken from the RFC 8288 Link header
+	// with rel="next". Empty on the last page.
+	NextURL string
 }
 
 // ListFootprints fetches a list of product carbon footprints.
+//
+// Only a single page is fetched. Use [Client.AllFootprints] or
+// [Client.FootprintPages] to follow pagination links.
 func (c *Client) ListFootprints(
 	ctx context.Context,
 	request *ListFootprintsParams,
@@ -31,19 +55,88 @@ func (c *Client) ListFootprints(
 			err = fmt.Errorf("get iLEAP footprint: %w", err)
 		}
 	}()
-	httpRequest, err := c.newRequest(ctx, http.MethodGet, "/2/footprints", nil)
+	page, err := c.listFootprintsPage(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
+	}
+	resp := &ileapv1.ListFootprintsResponse{}
+	resp.SetData(page.Footprints)
+	return resp, nil
+}
+
+// FootprintPages iterates over pages of product carbon footprints, following
+// the Link header pagination of the server until the last page is reached.
+//
+// Iteration stops after the first error. To resume a partially completed crawl,
+// start a new iteration with [ListFootprintsParams.PageURL] set to the
+// [FootprintsPage.NextURL] of the last successfully processed page.
+func (c *Client) FootprintPages(
+	ctx context.Context,
+	request *ListFootprintsParams,
+) iter.Seq2[*FootprintsPage, error] {
+	return func(yield func(*FootprintsPage, error) bool) {
+		params := *request
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, fmt.Errorf("list iLEAP footprint pages: %w", err))
+				return
+			}
+			page, err := c.listFootprintsPage(ctx, &params)
+			if err != nil {
+				yield(nil, fmt.Errorf("list iLEAP footprint pages: %w", err))
+				return
+			}
+			if !yield(page, nil) || page.NextURL == "" {
+				return
+			}
+			params = ListFootprintsParams{PageURL: page.NextURL}
+		}
 	}
+}
+
+// AllFootprints iterates over all product carbon footprints across all pages.
+func (c *Client) AllFootprints(
+	ctx context.Context,
+	request *ListFootprintsParams,
+) iter.Seq2[*ileapv1.ProductFootprint, error] {
+	return func(yield func(*ileapv1.ProductFootprint, error) bool) {
+		for page, err := range c.FootprintPages(ctx, request) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, footprint := range page.Footprints {
+				if !yield(footprint, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) listFootprintsPage(
+	ctx context.Context,
+	request *ListFootprintsParams,
+) (*FootprintsPage, error) {
 	query := url.Values{}
 	if request.Limit > 0 {
 		query.Set("limit", strconv.Itoa(request.Limit))
 	}
-	if request.Filter != "" {
-		query.Set("$filter", request.Filter)
+	if request.Offset > 0 {
+		query.Set("offset", strconv.Itoa(request.Offset))
+	}
+	filter, err := encodeFootprintFilter(request.Filter, request.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if filter != "" {
+		query.Set("$filter", filter)
 	}
-	httpRequest.URL.RawQuery = query.Encode()
-	httpResponse, err := c.httpClient.Do(httpRequest)
+	httpRequest, err := c.newPageRequest(ctx, "/2/footprints", query, request.PageURL)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpResponse, err := c.doRevalidated(httpRequest)
 	if err != nil {
 		return nil, fmt.Errorf("send request: %w", err)
 	}
@@ -62,7 +155,7 @@ func (c *Client) ListFootprints(
 		return nil, fmt.Errorf("unmarshal response body: %w", err)
 	}
 	footprints := make([]*ileapv1.ProductFootprint, 0, len(response.Data))
-	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	opts := protojson.UnmarshalOptions{DiscardUnknown: !c.config.strict}
 	for _, raw := range response.Data {
 		pf := &ileapv1.ProductFootprint{}
 		if err := opts.Unmarshal(raw, pf); err != nil {
@@ -70,7 +163,13 @@ func (c *Client) ListFootprints(
 		}
 		footprints = append(footprints, pf)
 	}
-	resp := &ileapv1.ListFootprintsResponse{}
-	resp.SetData(footprints)
-	return resp, nil
+	if c.config.strict {
+		if err := validateAll(footprints); err != nil {
+			return nil, err
+		}
+	}
+	return &FootprintsPage{
+		Footprints: footprints,
+		NextURL:    nextLinkURL(httpResponse),
+	}, nil
 }
@@ -0,0 +1,152 @@
+package ileap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// newPageRequest creates a GET request for a paginated list endpoint.
+// When pageURL is set, it is requested as-is (after validation) and
+// requestPath and query are ignored.
+func (c *Client) newPageRequest(
+	ctx context.Context,
+	requestPath string,
+	query url.Values,
+	pageURL string,
+) (*http.Request, error) {
+	if pageURL == "" {
+		request, err := c.newRequest(ctx, http.MethodGet, requestPath, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.URL.RawQuery = query.Encode()
+		return request, nil
+	}
+	resolved, err := c.resolvePageURL(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	return c.newRequestURL(ctx, http.MethodGet, resolved, nil)
+}
+
+// resolvePageURL resolves a pagination URL against the base URL and ensures
+// it points to the same scheme and host, so that credentials are never sent
+// elsewhere or in plaintext.
+func (c *Client) resolvePageURL(pageURL string) (string, error) {
+	base, err := url.Parse(c.config.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	ref, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid page URL: %w", err)
+	}
+	resolved := base.ResolveReference(ref)
+	if !strings.EqualFold(resolved.Scheme, base.Scheme) {
+		return "", fmt.Errorf(
+			"page URL scheme %q does not match base URL scheme %q",
+			resolved.Scheme,
+			base.Scheme,
+		)
+	}
+	if !strings.EqualFold(resolved.Host, base.Host) {
+		return "", fmt.Errorf(
+			"page URL host %q does not match base URL host %q",
+			resolved.Host,
+			base.Host,
+		)
+	}
+	return resolved.String(), nil
+}
+
+// nextLinkURL returns the target of the RFC 8288 Link header with rel="next",
+// resolved against the request URL. Returns an empty string if there is none.
+func nextLinkURL(response *http.Response) string {
+	for _, header := range response.Header.Values("Link") {
+		for _, link := range splitLinkHeader(header) {
+			target, params, ok := parseLink(link)
+			if !ok || !hasRelation(params["rel"], "next") {
+				continue
+			}
+			ref, err := url.Parse(target)
+			if err != nil {
+				continue
+			}
+			if response.Request != nil && response.Request.URL != nil {
+				ref = response.Request.URL.ResolveReference(ref)
+			}
+			return ref.String()
+		}
+	}
+	return ""
+}
+
+// splitLinkHeader splits a Link header value into individual link-values,
+// ignoring commas inside the URI reference and quoted parameter values.
+func splitLinkHeader(header string) []string {
+	var links []string
+	start := 0
+	inURI, inQuote := false, false
+	for i := 0; i < len(header); i++ {
+		switch ch := header[i]; {
+		case inQuote:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inQuote = false
+			}
+		case ch == '<':
+			inURI = true
+		case ch == '>':
+			inURI = false
+		case ch == '"' && !inURI:
+			inQuote = true
+		case ch == ',' && !inURI:
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, header[start:])
+}
+
+// parseLink parses a single link-value of the form `<target>; param=value`.
+func parseLink(link string) (string, map[string]string, bool) {
+	link = strings.TrimSpace(link)
+	if !strings.HasPrefix(link, "<") {
+		return "", nil, false
+	}
+	end := strings.IndexByte(link, '>')
+	if end < 0 {
+		return "", nil, false
+	}
+	target := link[1:end]
+	params := make(map[string]string)
+	for _, param := range strings.Split(link[end+1:], ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		if _, ok := params[name]; !ok {
+			params[name] = value
+		}
+	}
+	return target, params, true
+}
+
+// hasRelation reports whether a space-separated rel parameter contains relation.
+func hasRelation(rel, relation string) bool {
+	for _, candidate := range strings.Fields(rel) {
+		if strings.EqualFold(candidate, relation) {
+			return true
+		}
+	}
+	return false
+}
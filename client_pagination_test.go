@@ -0,0 +1,214 @@
+package ileap
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"golang.org/x/oauth2"
+)
+
+func newPaginationTestClient(
+	t *testing.T,
+	handler *mockServiceHandler,
+	opts ...ClientOption,
+) *Client {
+	t.Helper()
+	srv := httptest.NewServer(NewServer(
+		WithAuthHandler(&mockAuthHandler{validToken: true}),
+		WithServiceHandler(handler),
+	))
+	t.Cleanup(srv.Close)
+	return NewClient(append([]ClientOption{
+		WithBaseURL(srv.URL),
+		WithReuseTokenAuth(&oauth2.Token{AccessToken: "valid", TokenType: "Bearer"}),
+	}, opts...)...)
+}
+
+func TestClientAllFootprints(t *testing.T) {
+	handler := &mockServiceHandler{
+		footprints: []*ileapv1.ProductFootprint{
+			func() *ileapv1.ProductFootprint { p := &ileapv1.ProductFootprint{}; p.SetId("fp-1"); return p }(),
+			func() *ileapv1.ProductFootprint { p := &ileapv1.ProductFootprint{}; p.SetId("fp-2"); return p }(),
+			func() *ileapv1.ProductFootprint { p := &ileapv1.ProductFootprint{}; p.SetId("fp-3"); return p }(),
+		},
+	}
+	client := newPaginationTestClient(t, handler)
+
+	t.Run("follows next links", func(t *testing.T) {
+		var got []string
+		for fp, err := range client.AllFootprints(t.Context(), &ListFootprintsParams{Limit: 1}) {
+			if err != nil {
+				t.Fatalf("AllFootprints: %v", err)
+			}
+			got = append(got, fp.GetId())
+		}
+		if len(got) != 3 || got[0] != "fp-1" || got[1] != "fp-2" || got[2] != "fp-3" {
+			t.Errorf("got %v, want [fp-1 fp-2 fp-3]", got)
+		}
+	})
+
+	t.Run("resume from next URL", func(t *testing.T) {
+		var nextURL string
+		for page, err := range client.FootprintPages(t.Context(), &ListFootprintsParams{Limit: 2}) {
+			if err != nil {
+				t.Fatalf("FootprintPages: %v", err)
+			}
+			nextURL = page.NextURL
+			break
+		}
+		if nextURL == "" {
+			t.Fatal("expected next URL after first page")
+		}
+		var got []string
+		for fp, err := range client.AllFootprints(t.Context(), &ListFootprintsParams{PageURL: nextURL}) {
+			if err != nil {
+				t.Fatalf("AllFootprints: %v", err)
+			}
+			got = append(got, fp.GetId())
+		}
+		if len(got) != 1 || got[0] != "fp-3" {
+			t.Errorf("got %v, want [fp-3]", got)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		for _, err := range client.AllFootprints(ctx, &ListFootprintsParams{Limit: 1}) {
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			return
+		}
+		t.Fatal("expected an error")
+	})
+
+	t.Run("foreign next URL rejected", func(t *testing.T) {
+		pageURL := "https://evil.example.com/2/footprints?offset=1"
+		for _, err := range client.AllFootprints(t.Context(), &ListFootprintsParams{PageURL: pageURL}) {
+			if err == nil {
+				t.Fatal("expected error for foreign page URL")
+			}
+			return
+		}
+		t.Fatal("expected an error")
+	})
+}
+
+func TestClientAllTADs(t *testing.T) {
+	handler := &mockServiceHandler{
+		tads: []*ileapv1.TAD{
+			func() *ileapv1.TAD { t := &ileapv1.TAD{}; t.SetActivityId("tad-1"); return t }(),
+			func() *ileapv1.TAD { t := &ileapv1.TAD{}; t.SetActivityId("tad-2"); return t }(),
+			func() *ileapv1.TAD { t := &ileapv1.TAD{}; t.SetActivityId("tad-3"); return t }(),
+		},
+	}
+	client := newPaginationTestClient(t, handler)
+	var pages int
+	var got []string
+	for page, err := range client.TADPages(t.Context(), &ListTADsParams{Limit: 2}) {
+		if err != nil {
+			t.Fatalf("TADPages: %v", err)
+		}
+		pages++
+		for _, tad := range page.TADs {
+			got = append(got, tad.GetActivityId())
+		}
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+	if len(got) != 3 {
+		t.Errorf("expected 3 TADs, got %v", got)
+	}
+}
+
+func TestResolvePageURL(t *testing.T) {
+	client := NewClient(WithBaseURL("https://api.example.com"))
+	for _, tc := range []struct {
+		pageURL string
+		want    string
+	}{
+		{"/2/footprints?offset=1", "https://api.example.com/2/footprints?offset=1"},
+		{
+			"https://API.example.com/2/footprints?offset=1",
+			"https://API.example.com/2/footprints?offset=1",
+		},
+		{"http://api.example.com/2/footprints?offset=1", ""},
+		{"https://evil.example.com/2/footprints?offset=1", ""},
+	} {
+		got, err := client.resolvePageURL(tc.pageURL)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("resolvePageURL(%q) = %q, want error", tc.pageURL, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("resolvePageURL(%q) = %q, %v, want %q", tc.pageURL, got, err, tc.want)
+		}
+	}
+}
+
+func TestNextLinkURL(t *testing.T) {
+	requestURL, _ := url.Parse("https://example.com/2/footprints?limit=2")
+	testCases := []struct {
+		name   string
+		header []string
+		want   string
+	}{
+		{
+			name: "none",
+			want: "",
+		},
+		{
+			name:   "absolute",
+			header: []string{`<https://example.com/2/footprints?limit=2&offset=2>; rel="next"`},
+			want:   "https://example.com/2/footprints?limit=2&offset=2",
+		},
+		{
+			name:   "relative",
+			header: []string{`</2/footprints?offset=2>; rel=next`},
+			want:   "https://example.com/2/footprints?offset=2",
+		},
+		{
+			name: "multiple links in one header",
+			header: []string{
+				`<https://example.com/a,b>; rel="prev", <https://example.com/c>; rel="next"`,
+			},
+			want: "https://example.com/c",
+		},
+		{
+			name:   "multiple relation types",
+			header: []string{`<https://example.com/c>; rel="last next"`},
+			want:   "https://example.com/c",
+		},
+		{
+			name: "multiple headers",
+			header: []string{
+				`<https://example.com/a>; rel="prev"`,
+				`<https://example.com/b>; REL="Next"`,
+			},
+			want: "https://example.com/b",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := &http.Response{
+				Header:  http.Header{},
+				Request: &http.Request{URL: requestURL},
+			}
+			for _, value := range tc.header {
+				response.Header.Add("Link", value)
+			}
+			if got := nextLinkURL(response); got != tc.want {
+				t.Errorf("nextLinkURL() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -17,9 +18,32 @@ import (
 type ListTADsParams struct {
 	// Limit is the maximum number of TADs to return.
 	Limit int `json:"limit,omitempty"`
+	// Offset is the number of TADs to skip.
+	Offset int `json:"offset,omitempty"`
+	// Filters are filter pairs to apply to the request.
+	//
+	// They are encoded as iLEAP key=value query parameters, e.g.
+	// "mode=Road", so only the EQ operator is supported. Field paths use dot
+	// notation with JSON field names, e.g. "energyCarriers.feedstocks.feedstock".
+	Filters []*ileapv1.Filter `json:"-"`
+	// PageURL is the raw URL of a page to fetch, as returned in [TADsPage.NextURL].
+	// When set, all other parameters are ignored and listing resumes from that page.
+	PageURL string `json:"-"`
+}
+
+// TADsPage is a single page of transport activity data.
+type TADsPage struct {
+	// TADs are the transport activity data on this page.
+	TADs []*ileapv1.TAD
+	// NextURL is the raw URL of the next page, taken from the RFC 8288 Link header
+	// with rel="next". Empty on the last page.
+	NextURL string
 }
 
 // ListTADs lists transport activity data.
+//
+// Only a single page is fetched. Use [Client.AllTADs] or [Client.TADPages]
+// to follow pagination links.
 func (c *Client) ListTADs(
 	ctx context.Context,
 	request *ListTADsParams,
@@ -29,15 +53,83 @@ func (c *Client) ListTADs(
 			err = fmt.Errorf("list iLEAP TADs: %w", err)
 		}
 	}()
-	httpRequest, err := c.newRequest(ctx, http.MethodGet, "/2/ileap/tad", nil)
+	page, err := c.listTADsPage(ctx, request)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
+	}
+	resp := &ileapv1.ListTransportActivityDataResponse{}
+	resp.SetData(page.TADs)
+	return resp, nil
+}
+
+// TADPages iterates over pages of transport activity data, following the
+// Link header pagination of the server until the last page is reached.
+//
+// Iteration stops after the first error. To resume a partially completed crawl,
+// start a new iteration with [ListTADsParams.PageURL] set to the
+// [TADsPage.NextURL] of the last successfully processed page.
+func (c *Client) TADPages(
+	ctx context.Context,
+	request *ListTADsParams,
+) iter.Seq2[*TADsPage, error] {
+	return func(yield func(*TADsPage, error) bool) {
+		params := *request
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, fmt.Errorf("list iLEAP TAD pages: %w", err))
+				return
+			}
+			page, err := c.listTADsPage(ctx, &params)
+			if err != nil {
+				yield(nil, fmt.Errorf("list iLEAP TAD pages: %w", err))
+				return
+			}
+			if !yield(page, nil) || page.NextURL == "" {
+				return
+			}
+			params = ListTADsParams{PageURL: page.NextURL}
+		}
+	}
+}
+
+// AllTADs iterates over all transport activity data across all pages.
+func (c *Client) AllTADs(
+	ctx context.Context,
+	request *ListTADsParams,
+) iter.Seq2[*ileapv1.TAD, error] {
+	return func(yield func(*ileapv1.TAD, error) bool) {
+		for page, err := range c.TADPages(ctx, request) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, tad := range page.TADs {
+				if !yield(tad, nil) {
+					return
+				}
+			}
+		}
 	}
+}
+
+func (c *Client) listTADsPage(
+	ctx context.Context,
+	request *ListTADsParams,
+) (*TADsPage, error) {
 	query := url.Values{}
 	if request.Limit > 0 {
 		query.Set("limit", strconv.Itoa(request.Limit))
 	}
-	httpRequest.URL.RawQuery = query.Encode()
+	if request.Offset > 0 {
+		query.Set("offset", strconv.Itoa(request.Offset))
+	}
+	if err := encodeTADFilters(query, request.Filters); err != nil {
+		return nil, err
+	}
+	httpRequest, err := c.newPageRequest(ctx, "/2/ileap/tad", query, request.PageURL)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
 	httpResponse, err := c.httpClient.Do(httpRequest)
 	if err != nil {
 		return nil, fmt.Errorf("send request: %w", err)
@@ -57,7 +149,7 @@ func (c *Client) ListTADs(
 		return nil, fmt.Errorf("unmarshal response body: %w", err)
 	}
 	tads := make([]*ileapv1.TAD, 0, len(response.Data))
-	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	opts := protojson.UnmarshalOptions{DiscardUnknown: !c.config.strict}
 	for _, raw := range response.Data {
 		tad := &ileapv1.TAD{}
 		if err := opts.Unmarshal(raw, tad); err != nil {
@@ -65,7 +157,13 @@ func (c *Client) ListTADs(
 		}
 		tads = append(tads, tad)
 	}
-	resp := &ileapv1.ListTransportActivityDataResponse{}
-	resp.SetData(tads)
-	return resp, nil
+	if c.config.strict {
+		if err := validateAll(tads); err != nil {
+			return nil, err
+		}
+	}
+	return &TADsPage{
+		TADs:    tads,
+		NextURL: nextLinkURL(httpResponse),
+	}, nil
 }
@@ -3,6 +3,8 @@ package demoserver
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -10,10 +12,12 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/handlers/ileapasync"
 	"github.com/way-platform/ileap-go/handlers/ileapclerk"
 	"github.com/way-platform/ileap-go/handlers/ileapdemo"
 )
@@ -30,11 +34,43 @@ func NewCommand() *cobra.Command {
 		Short: "Start the iLEAP demo server",
 	}
 	cmd.Flags().Int("port", 8080, "port to listen on")
-	cmd.Flags().String("auth-backend", "demo", "auth backend to use (demo, clerk)")
+	cmd.Flags().String("auth-backend", "demo", "auth backend to use (demo, clerk, file)")
 	cmd.Flags().
 		String("clerk-fapi-domain", "", "Clerk FAPI domain (required when auth-backend=clerk)")
 	cmd.Flags().
 		String("clerk-organization-id", "", "Clerk organization ID to activate upon login (optional)")
+	cmd.Flags().
+		String("clients-file", "", "YAML or JSON client registry file (required when auth-backend=file)")
+	cmd.Flags().
+		String("signing-key-file", "", "PEM signing key file, generated if missing (used when auth-backend=file)")
+	cmd.Flags().
+		Duration("key-rotation-interval", 0, "interval to rotate the signing key at, 0 disables rotation (used when auth-backend=demo or file)")
+	cmd.Flags().
+		String("tls-cert-file", "", "PEM certificate file, serves HTTPS and accepts TLS client certificates")
+	cmd.Flags().
+		String("tls-key-file", "", "PEM private key file of the TLS certificate")
+	cmd.Flags().
+		String("client-ca-file", "", "PEM CA certificates that client certificates are verified against (optional)")
+	cmd.Flags().
+		String("client-certificate-header", "", "header with the client certificate set by a TLS-terminating proxy")
+	cmd.Flags().
+		Int("rate-limit", 0, "requests per minute allowed per client IP address and per client, 0 disables rate limiting")
+	cmd.Flags().
+		Int("lockout-max-failures", 0, "failed token requests before a client is locked out, 0 disables lockout")
+	cmd.Flags().
+		Duration("lockout-duration", 15*time.Minute, "time a locked out client waits for each further token request")
+	cmd.Flags().
+		String("audit-log", "", "JSON lines file to append audit records of data requests to")
+	cmd.Flags().
+		String("event-source", "", "CloudEvents source URI of this server, enables fulfilling async footprint requests")
+	cmd.Flags().
+		String("requester-base-url", "", "base URL of the host system trusted to send async footprint requests")
+	cmd.Flags().
+		String("requester-subject", "", "token subject the trusted requester of async footprint requests authenticates as")
+	cmd.Flags().
+		String("requester-client-id", "", "client ID to authenticate against the trusted requester")
+	cmd.Flags().
+		String("requester-client-secret", "", "client secret to authenticate against the trusted requester")
 	_ = v.BindPFlag(
 		"port",
 		cmd.Flags().Lookup("port"),
@@ -51,6 +87,26 @@ func NewCommand() *cobra.Command {
 		"clerk-organization-id",
 		cmd.Flags().Lookup("clerk-organization-id"),
 	)
+	for _, name := range []string{
+		"clients-file",
+		"signing-key-file",
+		"key-rotation-interval",
+		"tls-cert-file",
+		"tls-key-file",
+		"client-ca-file",
+		"client-certificate-header",
+		"rate-limit",
+		"lockout-max-failures",
+		"lockout-duration",
+		"audit-log",
+		"event-source",
+		"requester-base-url",
+		"requester-subject",
+		"requester-client-id",
+		"requester-client-secret",
+	} {
+		_ = v.BindPFlag(name, cmd.Flags().Lookup(name))
+	}
 	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			Level: slog.LevelDebug,
@@ -66,13 +122,24 @@ func run(ctx context.Context, v *viper.Viper) error {
 	if err != nil {
 		return err
 	}
+	tlsConfig, err := buildTLSConfig(v)
+	if err != nil {
+		return err
+	}
 	address := fmt.Sprintf(":%d", port)
-	slog.InfoContext(ctx, "iLEAP demo server listening", "address", address)
+	logAttrs := []any{"address", address, "tls", tlsConfig != nil}
+	slog.InfoContext(ctx, "iLEAP demo server listening", logAttrs...)
 	lis, err := (&net.ListenConfig{}).Listen(ctx, "tcp", address)
 	if err != nil {
 		return err
 	}
-	if err := (&http.Server{Handler: logRequests(handler)}).Serve(lis); err != nil {
+	server := &http.Server{Handler: logRequests(handler), TLSConfig: tlsConfig}
+	if tlsConfig != nil {
+		err = server.ServeTLS(lis, v.GetString("tls-cert-file"), v.GetString("tls-key-file"))
+	} else {
+		err = server.Serve(lis)
+	}
+	if err != nil {
 		if errors.Is(err, http.ErrServerClosed) {
 			return nil
 		}
@@ -81,6 +148,33 @@ func run(ctx context.Context, v *viper.Viper) error {
 	return nil
 }
 
+// buildTLSConfig returns the TLS configuration of the server, or nil to serve
+// plain HTTP. Client certificates are requested but not required, so that
+// clients can authenticate with mutual TLS or with other methods.
+func buildTLSConfig(v *viper.Viper) (*tls.Config, error) {
+	certFile, keyFile := v.GetString("tls-cert-file"), v.GetString("tls-key-file")
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("--tls-cert-file and --tls-key-file must be set together")
+	}
+	cfg := &tls.Config{ClientAuth: tls.RequestClientCert}
+	if caFile := v.GetString("client-ca-file"); caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates in client CA file %s", caFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return cfg, nil
+}
+
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
@@ -110,30 +204,93 @@ func buildHandler(v *viper.Viper) (http.Handler, error) {
 	if err != nil {
 		return nil, err
 	}
+	opts := []ileap.ServerOption{
+		ileap.WithServiceHandler(handler),
+	}
+	if header := v.GetString("client-certificate-header"); header != "" {
+		opts = append(opts, ileap.WithClientCertificateHeader(header))
+	}
+	rateLimit, maxFailures := v.GetInt("rate-limit"), v.GetInt("lockout-max-failures")
+	if rateLimit > 0 || maxFailures > 0 {
+		opts = append(opts, ileap.WithRateLimit(ileap.RateLimitConfig{
+			PerIP:     ileap.PerMinute(rateLimit),
+			PerClient: ileap.PerMinute(rateLimit),
+			Lockout: ileap.LockoutPolicy{
+				MaxFailures: maxFailures,
+				Duration:    v.GetDuration("lockout-duration"),
+			},
+		}))
+	}
+	if auditLog := v.GetString("audit-log"); auditLog != "" {
+		sink, err := ileap.OpenJSONLinesAuditSink(auditLog)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("writing audit log", "path", auditLog)
+		opts = append(opts, ileap.WithAuditSink(sink))
+	}
+	if eventSource := v.GetString("event-source"); eventSource != "" {
+		slog.Info("fulfilling async footprint requests", "event-source", eventSource)
+		asyncHandler, err := buildAsyncHandler(v, handler)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, ileap.WithEventHandler(asyncHandler))
+	}
 	switch authBackend {
 	case "demo":
-		auth, err := ileapdemo.NewAuthProvider()
+		auth, err := ileapdemo.NewAuthProvider(
+			ileapdemo.WithRotationInterval(v.GetDuration("key-rotation-interval")),
+		)
 		if err != nil {
 			return nil, err
 		}
-		return ileap.NewServer(
-			ileap.WithServiceHandler(handler),
-			ileap.WithAuthHandler(auth),
-		), nil
+		return ileap.NewServer(append(opts, ileap.WithAuthHandler(auth))...), nil
 	case "clerk":
 		auth, err := buildClerkAuth(v)
 		if err != nil {
 			return nil, err
 		}
-		return ileap.NewServer(
-			ileap.WithServiceHandler(handler),
-			ileap.WithAuthHandler(auth),
-		), nil
+		return ileap.NewServer(append(opts, ileap.WithAuthHandler(auth))...), nil
+	case "file":
+		auth, err := buildFileAuth(v)
+		if err != nil {
+			return nil, err
+		}
+		return ileap.NewServer(append(opts, ileap.WithAuthHandler(auth))...), nil
 	default:
 		return nil, fmt.Errorf("unknown auth-backend: %s", authBackend)
 	}
 }
 
+func buildAsyncHandler(v *viper.Viper, handler *ileapdemo.Handler) (*ileapasync.Handler, error) {
+	var requesters []ileapasync.Requester
+	if baseURL := v.GetString("requester-base-url"); baseURL != "" {
+		subject := v.GetString("requester-subject")
+		if subject == "" {
+			return nil, fmt.Errorf("--requester-subject required with --requester-base-url")
+		}
+		slog.Info("trusting requester", "base-url", baseURL, "subject", subject)
+		requesters = append(requesters, ileapasync.Requester{
+			BaseURL:      baseURL,
+			Subject:      subject,
+			ClientID:     v.GetString("requester-client-id"),
+			ClientSecret: v.GetString("requester-client-secret"),
+		})
+	} else {
+		slog.Warn("no --requester-base-url, all async footprint requests are rejected")
+	}
+	registry, err := ileapasync.NewStaticRegistry(requesters...)
+	if err != nil {
+		return nil, err
+	}
+	return ileapasync.NewHandler(
+		v.GetString("event-source"),
+		ileapasync.NewServiceResolver(handler),
+		registry,
+	), nil
+}
+
 func buildClerkAuth(v *viper.Viper) (*ileapclerk.AuthHandler, error) {
 	fapiDomain := v.GetString("clerk-fapi-domain")
 	if fapiDomain == "" {
@@ -147,3 +304,20 @@ func buildClerkAuth(v *viper.Viper) (*ileapclerk.AuthHandler, error) {
 		ileapclerk.WithActiveOrganization(activeOrgID),
 	), nil
 }
+
+func buildFileAuth(v *viper.Viper) (*ileapdemo.FileAuthProvider, error) {
+	clientsFile := v.GetString("clients-file")
+	if clientsFile == "" {
+		return nil, fmt.Errorf("--clients-file required when --auth-backend=file")
+	}
+	slog.Info("using file auth backend", "clients-file", clientsFile)
+	opts := []ileapdemo.FileAuthProviderOption{
+		ileapdemo.WithKeyManagerOptions(
+			ileapdemo.WithRotationInterval(v.GetDuration("key-rotation-interval")),
+		),
+	}
+	if signingKeyFile := v.GetString("signing-key-file"); signingKeyFile != "" {
+		opts = append(opts, ileapdemo.WithSigningKeyFile(signingKeyFile))
+	}
+	return ileapdemo.NewFileAuthProvider(clientsFile, opts...)
+}
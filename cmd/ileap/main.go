@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"image/color"
 	"os"
+	"strings"
 
 	"charm.land/lipgloss/v2"
 	"github.com/charmbracelet/fang"
@@ -12,6 +13,7 @@ import (
 	"github.com/way-platform/ileap-go"
 	"github.com/way-platform/ileap-go/cmd/ileap/internal/auth"
 	"github.com/way-platform/ileap-go/cmd/ileap/internal/demoserver"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -122,6 +124,7 @@ func newListFootprintsCommand() *cobra.Command {
 		GroupID: "pcf",
 	}
 	limit := cmd.Flags().Int("limit", 100, "max footprints queried")
+	offset := cmd.Flags().Int("offset", 0, "number of footprints to skip")
 	filter := cmd.Flags().String("filter", "", "filter footprints by OData filter")
 	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		client, err := newClient(cmd)
@@ -130,6 +133,7 @@ func newListFootprintsCommand() *cobra.Command {
 		}
 		response, err := client.ListFootprints(cmd.Context(), &ileap.ListFootprintsParams{
 			Limit:  *limit,
+			Offset: *offset,
 			Filter: *filter,
 		})
 		if err != nil {
@@ -147,13 +151,29 @@ func newListTADsCommand() *cobra.Command {
 		GroupID: "tad",
 	}
 	limit := cmd.Flags().Int("limit", 100, "max TADs queried")
+	offset := cmd.Flags().Int("offset", 0, "number of TADs to skip")
+	filters := cmd.Flags().StringArray("filter", nil, "filter TADs by field (e.g. mode=Road)")
 	cmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		client, err := newClient(cmd)
 		if err != nil {
 			return err
 		}
+		tadFilters := make([]*ileapv1.Filter, 0, len(*filters))
+		for _, raw := range *filters {
+			fieldPath, value, ok := strings.Cut(raw, "=")
+			if !ok || fieldPath == "" {
+				return fmt.Errorf("invalid filter %q: expected field=value", raw)
+			}
+			filter := new(ileapv1.Filter)
+			filter.SetFieldPath(fieldPath)
+			filter.SetOperator(ileapv1.Filter_EQ)
+			filter.SetValue(value)
+			tadFilters = append(tadFilters, filter)
+		}
 		response, err := client.ListTADs(cmd.Context(), &ileap.ListTADsParams{
-			Limit: *limit,
+			Limit:   *limit,
+			Offset:  *offset,
+			Filters: tadFilters,
 		})
 		if err != nil {
 			return err
@@ -33,6 +33,10 @@ const (
 	ErrorCodeNoSuchFootprint ErrorCode = "NoSuchFootprint"
 )
 
+// ErrorCodeTooManyRequests is returned with HTTP 429 when a rate limit is
+// exceeded. It is not a PACT error code, as PACT does not define one.
+const ErrorCodeTooManyRequests ErrorCode = "TooManyRequests"
+
 // Error implements the error interface.
 func (e *Error) Error() string {
 	return fmt.Sprintf("iLEAP error %s: %s", e.Code, e.Message)
@@ -1,5 +1,15 @@
 package ileap
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
 // eventType is a PACT CloudEvents event type string.
 type eventType string
 
@@ -9,6 +19,7 @@ type event struct {
 	Specversion string
 	ID          string
 	Source      string
+	Time        string
 	Data        []byte
 }
 
@@ -32,3 +43,200 @@ func isKnownEventType(t eventType) bool {
 		return false
 	}
 }
+
+// EventMetadata is the CloudEvents context of a received PACT event.
+type EventMetadata struct {
+	// ID is the CloudEvents id, unique per source.
+	ID string
+	// Source is the CloudEvents source URI of the sending host system.
+	Source string
+	// Time is the CloudEvents time, if provided by the sender.
+	Time time.Time
+}
+
+// RequestCreatedEvent is a ProductFootprintRequest.Created.v1 event, sent by a
+// data recipient to asynchronously request footprints from a data owner.
+type RequestCreatedEvent struct {
+	EventMetadata
+	// Footprint is a partial ProductFootprint whose set properties describe the
+	// requested footprints, e.g. productIds.
+	Footprint *ileapv1.ProductFootprint
+	// Comment is an optional free-text comment from the requester.
+	Comment string
+}
+
+// RequestFulfilledEvent is a ProductFootprintRequest.Fulfilled.v1 event, sent by
+// a data owner in response to a [RequestCreatedEvent].
+type RequestFulfilledEvent struct {
+	EventMetadata
+	// RequestEventID is the ID of the originating request event.
+	RequestEventID string
+	// Footprints are the footprints fulfilling the request.
+	Footprints []*ileapv1.ProductFootprint
+}
+
+// RequestRejectedEvent is a ProductFootprintRequest.Rejected.v1 event, sent by
+// a data owner when it cannot fulfill a [RequestCreatedEvent].
+type RequestRejectedEvent struct {
+	EventMetadata
+	// RequestEventID is the ID of the originating request event.
+	RequestEventID string
+	// Error is the reason for the rejection.
+	Error *Error
+}
+
+// PublishedEvent is a ProductFootprint.Published.v1 event, sent by a data owner
+// to notify a data recipient that footprints were published or updated.
+type PublishedEvent struct {
+	EventMetadata
+	// FootprintIDs are the IDs of the published footprints.
+	FootprintIDs []string
+}
+
+// NopEventHandler is an [EventHandler] that accepts and discards all events.
+// Embed it to implement only a subset of the EventHandler methods.
+type NopEventHandler struct{}
+
+var _ EventHandler = NopEventHandler{}
+
+// HandleRequestCreated implements [EventHandler].
+func (NopEventHandler) HandleRequestCreated(
+	context.Context,
+	*TokenInfo,
+	*RequestCreatedEvent,
+) error {
+	return nil
+}
+
+// HandleRequestFulfilled implements [EventHandler].
+func (NopEventHandler) HandleRequestFulfilled(
+	context.Context,
+	*TokenInfo,
+	*RequestFulfilledEvent,
+) error {
+	return nil
+}
+
+// HandleRequestRejected implements [EventHandler].
+func (NopEventHandler) HandleRequestRejected(
+	context.Context,
+	*TokenInfo,
+	*RequestRejectedEvent,
+) error {
+	return nil
+}
+
+// HandlePublished implements [EventHandler].
+func (NopEventHandler) HandlePublished(context.Context, *TokenInfo, *PublishedEvent) error {
+	return nil
+}
+
+// dispatchEvent decodes the typed event payload and calls the matching handler method.
+// Payload decoding errors are wrapped in an *eventPayloadError.
+func dispatchEvent(
+	ctx context.Context,
+	handler EventHandler,
+	info *TokenInfo,
+	e *event,
+) error {
+	metadata := EventMetadata{ID: e.ID, Source: e.Source}
+	if e.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, e.Time)
+		if err != nil {
+			return &eventPayloadError{err: fmt.Errorf("invalid event time: %w", err)}
+		}
+		metadata.Time = t
+	}
+	opts := protojson.UnmarshalOptions{DiscardUnknown: true}
+	switch eventType(e.Type) {
+	case eventTypeRequestCreatedV1:
+		var payload struct {
+			PF      json.RawMessage `json:"pf"`
+			Comment string          `json:"comment"`
+		}
+		if err := json.Unmarshal(e.Data, &payload); err != nil {
+			return &eventPayloadError{err: err}
+		}
+		if len(payload.PF) == 0 {
+			return &eventPayloadError{err: fmt.Errorf("missing pf")}
+		}
+		pf := &ileapv1.ProductFootprint{}
+		if err := opts.Unmarshal(payload.PF, pf); err != nil {
+			return &eventPayloadError{err: fmt.Errorf("invalid pf: %w", err)}
+		}
+		return handler.HandleRequestCreated(ctx, info, &RequestCreatedEvent{
+			EventMetadata: metadata,
+			Footprint:     pf,
+			Comment:       payload.Comment,
+		})
+	case eventTypeRequestFulfilledV1:
+		var payload struct {
+			RequestEventID string            `json:"requestEventId"`
+			PFs            []json.RawMessage `json:"pfs"`
+		}
+		if err := json.Unmarshal(e.Data, &payload); err != nil {
+			return &eventPayloadError{err: err}
+		}
+		if payload.RequestEventID == "" {
+			return &eventPayloadError{err: fmt.Errorf("missing requestEventId")}
+		}
+		pfs := make([]*ileapv1.ProductFootprint, 0, len(payload.PFs))
+		for _, raw := range payload.PFs {
+			pf := &ileapv1.ProductFootprint{}
+			if err := opts.Unmarshal(raw, pf); err != nil {
+				return &eventPayloadError{err: fmt.Errorf("invalid pfs: %w", err)}
+			}
+			pfs = append(pfs, pf)
+		}
+		return handler.HandleRequestFulfilled(ctx, info, &RequestFulfilledEvent{
+			EventMetadata:  metadata,
+			RequestEventID: payload.RequestEventID,
+			Footprints:     pfs,
+		})
+	case eventTypeRequestRejectedV1:
+		var payload struct {
+			RequestEventID string `json:"requestEventId"`
+			Error          *Error `json:"error"`
+		}
+		if err := json.Unmarshal(e.Data, &payload); err != nil {
+			return &eventPayloadError{err: err}
+		}
+		if payload.RequestEventID == "" {
+			return &eventPayloadError{err: fmt.Errorf("missing requestEventId")}
+		}
+		if payload.Error == nil {
+			return &eventPayloadError{err: fmt.Errorf("missing error")}
+		}
+		return handler.HandleRequestRejected(ctx, info, &RequestRejectedEvent{
+			EventMetadata:  metadata,
+			RequestEventID: payload.RequestEventID,
+			Error:          payload.Error,
+		})
+	case eventTypePublishedV1:
+		var payload struct {
+			PFIDs []string `json:"pfIds"`
+		}
+		if err := json.Unmarshal(e.Data, &payload); err != nil {
+			return &eventPayloadError{err: err}
+		}
+		return handler.HandlePublished(ctx, info, &PublishedEvent{
+			EventMetadata: metadata,
+			FootprintIDs:  payload.PFIDs,
+		})
+	default:
+		return &eventPayloadError{err: fmt.Errorf("unknown event type: %s", e.Type)}
+	}
+}
+
+// eventPayloadError is returned by dispatchEvent when the event payload is malformed.
+type eventPayloadError struct {
+	err error
+}
+
+func (e *eventPayloadError) Error() string {
+	return fmt.Sprintf("invalid event payload: %v", e.err)
+}
+
+func (e *eventPayloadError) Unwrap() error {
+	return e.err
+}
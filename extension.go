@@ -1,6 +1,10 @@
 package ileap
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
@@ -49,3 +53,177 @@ func newExtension(m proto.Message, dataSchema string) (*ileapv1.DataModelExtensi
 	ext.SetData(s)
 	return ext, nil
 }
+
+// Errors reported when parsing extensions.
+var (
+	// ErrUnknownDataSchema is reported for extensions that are not iLEAP extensions.
+	ErrUnknownDataSchema = errors.New("unknown data schema")
+	// ErrSpecVersionMismatch is reported for iLEAP extensions with a different
+	// major spec version than [ExtensionSpecVersion].
+	ErrSpecVersionMismatch = errors.New("spec version mismatch")
+)
+
+// ExtensionError is an error parsing an extension of a ProductFootprint.
+type ExtensionError struct {
+	// Index is the index of the extension in the footprint's extensions.
+	Index int
+	// DataSchema is the data schema of the extension.
+	DataSchema string
+	// SpecVersion is the spec version of the extension.
+	SpecVersion string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ExtensionError) Error() string {
+	return fmt.Sprintf("extension %d (%s): %v", e.Index, e.DataSchema, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ExtensionError) Unwrap() error {
+	return e.Err
+}
+
+// Extensions are the typed iLEAP extensions of a ProductFootprint.
+type Extensions struct {
+	// ShipmentFootprints are the decoded ShipmentFootprint extensions.
+	ShipmentFootprints []*ileapv1.ShipmentFootprint
+	// TOCs are the decoded TOC extensions.
+	TOCs []*ileapv1.TOC
+	// HOCs are the decoded HOC extensions.
+	HOCs []*ileapv1.HOC
+	// Unknown are the extensions with a data schema other than the iLEAP schemas.
+	Unknown []*ileapv1.DataModelExtension
+}
+
+// ParseShipmentFootprint converts a ShipmentFootprint DataModelExtension to its typed form.
+func ParseShipmentFootprint(ext *ileapv1.DataModelExtension) (*ileapv1.ShipmentFootprint, error) {
+	sf := &ileapv1.ShipmentFootprint{}
+	if err := parseExtension(ext, DataSchemaShipmentFootprint, sf); err != nil {
+		return nil, err
+	}
+	return sf, nil
+}
+
+// ParseTOC converts a TOC DataModelExtension to its typed form.
+func ParseTOC(ext *ileapv1.DataModelExtension) (*ileapv1.TOC, error) {
+	toc := &ileapv1.TOC{}
+	if err := parseExtension(ext, DataSchemaTOC, toc); err != nil {
+		return nil, err
+	}
+	return toc, nil
+}
+
+// ParseHOC converts a HOC DataModelExtension to its typed form.
+func ParseHOC(ext *ileapv1.DataModelExtension) (*ileapv1.HOC, error) {
+	hoc := &ileapv1.HOC{}
+	if err := parseExtension(ext, DataSchemaHOC, hoc); err != nil {
+		return nil, err
+	}
+	return hoc, nil
+}
+
+// ParseExtension converts an iLEAP DataModelExtension to its typed form,
+// dispatching on its data schema. The result is a [*ileapv1.ShipmentFootprint],
+// [*ileapv1.TOC] or [*ileapv1.HOC].
+//
+// Extensions with other data schemas return an error wrapping [ErrUnknownDataSchema].
+func ParseExtension(ext *ileapv1.DataModelExtension) (proto.Message, error) {
+	data := newExtensionData(ext.GetDataSchema())
+	if data == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownDataSchema, ext.GetDataSchema())
+	}
+	if err := parseExtension(ext, ext.GetDataSchema(), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ParseExtensions converts all extensions of a ProductFootprint to their typed form.
+//
+// Extensions that cannot be parsed are reported in the returned error, which
+// joins an [*ExtensionError] per extension. Unknown data schemas are reported
+// with [ErrUnknownDataSchema] and spec version mismatches with
+// [ErrSpecVersionMismatch]. All other extensions are still returned.
+func ParseExtensions(pf *ileapv1.ProductFootprint) (*Extensions, error) {
+	result := &Extensions{}
+	var errs []error
+	for i, ext := range pf.GetExtensions() {
+		data, err := ParseExtension(ext)
+		if err != nil {
+			if errors.Is(err, ErrUnknownDataSchema) {
+				result.Unknown = append(result.Unknown, ext)
+			}
+			errs = append(errs, &ExtensionError{
+				Index:       i,
+				DataSchema:  ext.GetDataSchema(),
+				SpecVersion: ext.GetSpecVersion(),
+				Err:         err,
+			})
+			continue
+		}
+		switch data := data.(type) {
+		case *ileapv1.ShipmentFootprint:
+			result.ShipmentFootprints = append(result.ShipmentFootprints, data)
+		case *ileapv1.TOC:
+			result.TOCs = append(result.TOCs, data)
+		case *ileapv1.HOC:
+			result.HOCs = append(result.HOCs, data)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// newExtensionData returns an empty typed message for an iLEAP data schema,
+// or nil for unknown data schemas.
+func newExtensionData(dataSchema string) proto.Message {
+	switch dataSchema {
+	case DataSchemaShipmentFootprint:
+		return &ileapv1.ShipmentFootprint{}
+	case DataSchemaTOC:
+		return &ileapv1.TOC{}
+	case DataSchemaHOC:
+		return &ileapv1.HOC{}
+	default:
+		return nil
+	}
+}
+
+func parseExtension(ext *ileapv1.DataModelExtension, dataSchema string, m proto.Message) error {
+	if ext.GetDataSchema() != dataSchema {
+		return fmt.Errorf(
+			"%w: got %q, want %q",
+			ErrUnknownDataSchema,
+			ext.GetDataSchema(),
+			dataSchema,
+		)
+	}
+	if majorVersion(ext.GetSpecVersion()) != majorVersion(ExtensionSpecVersion) {
+		return fmt.Errorf(
+			"%w: got %q, want %q",
+			ErrSpecVersionMismatch,
+			ext.GetSpecVersion(),
+			ExtensionSpecVersion,
+		)
+	}
+	if err := decodeExtensionData(ext, m); err != nil {
+		return fmt.Errorf("decode %s: %w", m.ProtoReflect().Descriptor().Name(), err)
+	}
+	return nil
+}
+
+// decodeExtensionData decodes the data of an extension into a typed message.
+// Fields not in the iLEAP data model are discarded.
+func decodeExtensionData(ext *ileapv1.DataModelExtension, m proto.Message) error {
+	data, err := protojson.Marshal(ext.GetData())
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+}
+
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
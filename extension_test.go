@@ -0,0 +1,160 @@
+package ileap
+
+import (
+	"errors"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseExtension(t *testing.T) {
+	sf := &ileapv1.ShipmentFootprint{}
+	sf.SetMass("1000")
+	sf.SetShipmentId("shipment-1")
+	toc := &ileapv1.TOC{}
+	toc.SetTocId("toc-1")
+	toc.SetMode("Road")
+	hoc := &ileapv1.HOC{}
+	hoc.SetHocId("hoc-1")
+
+	t.Run("round trip", func(t *testing.T) {
+		sfExt, err := NewShipmentFootprintExtension(sf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotSF, err := ParseShipmentFootprint(sfExt)
+		if err != nil {
+			t.Fatalf("ParseShipmentFootprint: %v", err)
+		}
+		if !proto.Equal(gotSF, sf) {
+			t.Errorf("got %v, want %v", gotSF, sf)
+		}
+		tocExt, err := NewTOCExtension(toc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotTOC, err := ParseTOC(tocExt)
+		if err != nil {
+			t.Fatalf("ParseTOC: %v", err)
+		}
+		if !proto.Equal(gotTOC, toc) {
+			t.Errorf("got %v, want %v", gotTOC, toc)
+		}
+		hocExt, err := NewHOCExtension(hoc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotHOC, err := ParseHOC(hocExt)
+		if err != nil {
+			t.Fatalf("ParseHOC: %v", err)
+		}
+		if !proto.Equal(gotHOC, hoc) {
+			t.Errorf("got %v, want %v", gotHOC, hoc)
+		}
+	})
+
+	t.Run("dispatch on data schema", func(t *testing.T) {
+		ext, err := NewTOCExtension(toc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseExtension(ext)
+		if err != nil {
+			t.Fatalf("ParseExtension: %v", err)
+		}
+		if _, ok := got.(*ileapv1.TOC); !ok {
+			t.Errorf("got %T, want *ileapv1.TOC", got)
+		}
+	})
+
+	t.Run("wrong data schema", func(t *testing.T) {
+		ext, err := NewTOCExtension(toc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseHOC(ext); !errors.Is(err, ErrUnknownDataSchema) {
+			t.Errorf("expected ErrUnknownDataSchema, got %v", err)
+		}
+	})
+
+	t.Run("spec version mismatch", func(t *testing.T) {
+		ext, err := NewTOCExtension(toc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext.SetSpecVersion("1.0.0")
+		if _, err := ParseTOC(ext); !errors.Is(err, ErrSpecVersionMismatch) {
+			t.Errorf("expected ErrSpecVersionMismatch, got %v", err)
+		}
+		ext.SetSpecVersion("2.1.0")
+		if _, err := ParseTOC(ext); err != nil {
+			t.Errorf("expected compatible minor version, got %v", err)
+		}
+	})
+
+	t.Run("decode error", func(t *testing.T) {
+		data, err := structpb.NewStruct(map[string]any{"mass": 1000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ext := &ileapv1.DataModelExtension{}
+		ext.SetDataSchema(DataSchemaShipmentFootprint)
+		ext.SetSpecVersion(ExtensionSpecVersion)
+		ext.SetData(data)
+		if _, err := ParseShipmentFootprint(ext); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+}
+
+func TestParseExtensions(t *testing.T) {
+	sf := &ileapv1.ShipmentFootprint{}
+	sf.SetShipmentId("shipment-1")
+	sfExt, err := NewShipmentFootprintExtension(sf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toc := &ileapv1.TOC{}
+	toc.SetTocId("toc-1")
+	tocExt, err := NewTOCExtension(toc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldExt, err := NewHOCExtension(&ileapv1.HOC{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldExt.SetSpecVersion("1.0.0")
+	otherExt := &ileapv1.DataModelExtension{}
+	otherExt.SetDataSchema("https://example.com/other.json")
+	otherExt.SetSpecVersion("1.0.0")
+	pf := &ileapv1.ProductFootprint{}
+	pf.SetExtensions([]*ileapv1.DataModelExtension{sfExt, otherExt, tocExt, oldExt})
+
+	got, err := ParseExtensions(pf)
+	if len(got.ShipmentFootprints) != 1 ||
+		got.ShipmentFootprints[0].GetShipmentId() != "shipment-1" {
+		t.Errorf("ShipmentFootprints = %v", got.ShipmentFootprints)
+	}
+	if len(got.TOCs) != 1 || got.TOCs[0].GetTocId() != "toc-1" {
+		t.Errorf("TOCs = %v", got.TOCs)
+	}
+	if len(got.HOCs) != 0 {
+		t.Errorf("HOCs = %v, want none", got.HOCs)
+	}
+	if len(got.Unknown) != 1 || got.Unknown[0] != otherExt {
+		t.Errorf("Unknown = %v", got.Unknown)
+	}
+	if !errors.Is(err, ErrUnknownDataSchema) {
+		t.Errorf("expected ErrUnknownDataSchema in %v", err)
+	}
+	if !errors.Is(err, ErrSpecVersionMismatch) {
+		t.Errorf("expected ErrSpecVersionMismatch in %v", err)
+	}
+	var extErr *ExtensionError
+	if !errors.As(err, &extErr) || extErr.Index != 1 {
+		t.Errorf("expected *ExtensionError for index 1, got %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package ileap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PACTSpecVersion is the PACT data model version of the footprints built by a
+// [FootprintBuilder].
+const PACTSpecVersion = "2.1.0"
+
+// PACT mapping of iLEAP footprints.
+const (
+	productCategoryCPC       = "83117"
+	productIDPrefixShipment  = "urn:pathfinder:product:customcode:vendor-assigned:shipment:"
+	productIDPrefixTOC       = "urn:pathfinder:product:customcode:vendor-assigned:toc:"
+	productIDPrefixHOC       = "urn:pathfinder:product:customcode:vendor-assigned:hoc:"
+	declaredUnitTonKilometer = "ton kilometer"
+	declaredUnitKilogram     = "kilogram"
+)
+
+// FootprintBuilderOption configures a [FootprintBuilder].
+type FootprintBuilderOption func(*FootprintBuilder)
+
+// WithPreviousFootprints sets the previously published versions of the
+// footprints, matched by product ID. A rebuilt footprint keeps the ID and
+// creation time of its previous version, and gets the next version number and
+// an update time if its content changed. Unchanged footprints are returned
+// as previously published.
+func WithPreviousFootprints(pfs ...*ileapv1.ProductFootprint) FootprintBuilderOption {
+	return func(b *FootprintBuilder) {
+		for _, pf := range pfs {
+			for _, productID := range pf.GetProductIds() {
+				b.previous[productID] = pf
+			}
+		}
+	}
+}
+
+// WithReferencePeriod sets the reference period of the footprints. Defaults
+// to the period from the earliest departure to the latest arrival of the
+// shipment's TCEs.
+func WithReferencePeriod(start, end time.Time) FootprintBuilderOption {
+	return func(b *FootprintBuilder) {
+		b.referencePeriodStart = start
+		b.referencePeriodEnd = end
+	}
+}
+
+// FootprintBuilder builds conformant iLEAP ProductFootprints from
+// ShipmentFootprints, TOCs and HOCs, following the PCF mapping of the iLEAP
+// Technical Specifications.
+type FootprintBuilder struct {
+	companyName          string
+	companyIDs           []string
+	referencePeriodStart time.Time
+	referencePeriodEnd   time.Time
+	previous             map[string]*ileapv1.ProductFootprint
+	now                  func() time.Time
+}
+
+// NewFootprintBuilder creates a new FootprintBuilder for footprints owned by
+// the company with the name and URN IDs.
+func NewFootprintBuilder(
+	companyName string,
+	companyIDs []string,
+	opts ...FootprintBuilderOption,
+) *FootprintBuilder {
+	b := &FootprintBuilder{
+		companyName: companyName,
+		companyIDs:  companyIDs,
+		previous:    make(map[string]*ileapv1.ProductFootprint),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Build builds the footprint of a ShipmentFootprint, followed by a footprint
+// for each of the TOCs and HOCs, since an iLEAP footprint carries exactly one
+// extension.
+//
+// The shipment's footprint is declared in ton kilometers: its
+// unitaryProductAmount is the sum of the TCEs' transportActivity, and its
+// pCfExcludingBiogenic the sum of their co2eWTW. TOC and HOC footprints carry
+// their co2eIntensityWTW per ton kilometer and per 1000 kilograms. The
+// geography is the country of the TCE or hub locations if they are all in the
+// same country, and global otherwise. The footprints are validated with
+// [Validate], whose errors are returned as a [*ValidationError].
+func (b *FootprintBuilder) Build(
+	sf *ileapv1.ShipmentFootprint,
+	tocs []*ileapv1.TOC,
+	hocs []*ileapv1.HOC,
+) (_ []*ileapv1.ProductFootprint, err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("build footprints: %w", err)
+		}
+	}()
+	if len(sf.GetTces()) == 0 {
+		return nil, errors.New("shipment footprint has no TCEs")
+	}
+	start, end, err := b.referencePeriod(sf)
+	if err != nil {
+		return nil, err
+	}
+	var activity, co2e ileapdecimal.Decimal
+	var locations []*ileapv1.Location
+	for i, tce := range sf.GetTces() {
+		tceActivity, err := tce.GetTransportActivityDecimal()
+		if err != nil {
+			return nil, fmt.Errorf("tces[%d].%w", i, err)
+		}
+		tceCO2e, err := tce.GetCo2EWtwDecimal()
+		if err != nil {
+			return nil, fmt.Errorf("tces[%d].%w", i, err)
+		}
+		activity = activity.Add(tceActivity)
+		co2e = co2e.Add(tceCO2e)
+		locations = append(locations, tce.GetOrigin(), tce.GetDestination())
+	}
+	ext, err := NewShipmentFootprintExtension(sf)
+	if err != nil {
+		return nil, err
+	}
+	shipmentID := sf.GetShipmentId()
+	pcf := newCarbonFootprint(
+		declaredUnitTonKilometer, activity.String(), co2e.String(), start, end,
+	)
+	if country := commonCountry(locations); country != "" {
+		pcf.SetGeographyCountry(country)
+	}
+	pf, err := b.newFootprint(
+		productIDPrefixShipment+shipmentID,
+		"Shipment with ID "+shipmentID,
+		"Logistics emissions related to shipment with ID "+shipmentID,
+		pcf,
+		ext,
+	)
+	if err != nil {
+		return nil, err
+	}
+	result := []*ileapv1.ProductFootprint{pf}
+	for _, toc := range tocs {
+		ext, err := NewTOCExtension(toc)
+		if err != nil {
+			return nil, err
+		}
+		pcf := newCarbonFootprint(
+			declaredUnitTonKilometer, "1", toc.GetCo2EIntensityWtw(), start, end,
+		)
+		pf, err := b.newFootprint(
+			productIDPrefixTOC+toc.GetTocId(),
+			"TOC "+toc.GetTocId(),
+			description(toc.GetDescription(), "Transport operation category "+toc.GetTocId()),
+			pcf,
+			ext,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pf)
+	}
+	for _, hoc := range hocs {
+		ext, err := NewHOCExtension(hoc)
+		if err != nil {
+			return nil, err
+		}
+		pcf := newCarbonFootprint(
+			declaredUnitKilogram, "1000", hoc.GetCo2EIntensityWtw(), start, end,
+		)
+		if country := commonCountry([]*ileapv1.Location{hoc.GetHubLocation()}); country != "" {
+			pcf.SetGeographyCountry(country)
+		}
+		pf, err := b.newFootprint(
+			productIDPrefixHOC+hoc.GetHocId(),
+			"HOC "+hoc.GetHocId(),
+			description(hoc.GetDescription(), "Hub operation category "+hoc.GetHocId()),
+			pcf,
+			ext,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, pf)
+	}
+	return result, nil
+}
+
+// referencePeriod returns the configured reference period, or the period of
+// the shipment's TCEs.
+func (b *FootprintBuilder) referencePeriod(
+	sf *ileapv1.ShipmentFootprint,
+) (time.Time, time.Time, error) {
+	if !b.referencePeriodStart.IsZero() && !b.referencePeriodEnd.IsZero() {
+		return b.referencePeriodStart, b.referencePeriodEnd, nil
+	}
+	var start, end time.Time
+	for _, tce := range sf.GetTces() {
+		for _, ts := range []*timestamppb.Timestamp{tce.GetDepartureAt(), tce.GetArrivalAt()} {
+			if ts == nil {
+				continue
+			}
+			t := ts.AsTime()
+			if start.IsZero() || t.Before(start) {
+				start = t
+			}
+			if end.IsZero() || t.After(end) {
+				end = t
+			}
+		}
+	}
+	if start.IsZero() || !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New(
+			"no reference period: the TCEs have no departure and arrival times",
+		)
+	}
+	return start, end, nil
+}
+
+// newFootprint returns a validated footprint of a product with a single
+// extension, versioned against its previous version.
+func (b *FootprintBuilder) newFootprint(
+	productID, name, productDescription string,
+	pcf *ileapv1.CarbonFootprint,
+	ext *ileapv1.DataModelExtension,
+) (*ileapv1.ProductFootprint, error) {
+	pf := new(ileapv1.ProductFootprint)
+	pf.SetSpecVersion(PACTSpecVersion)
+	pf.SetStatus("Active")
+	pf.SetCompanyName(b.companyName)
+	pf.SetOrganizationName(b.companyName)
+	pf.SetCompanyIds(b.companyIDs)
+	pf.SetProductDescription(productDescription)
+	pf.SetProductIds([]string{productID})
+	pf.SetProductCategoryCpc(productCategoryCPC)
+	pf.SetProductNameCompany(name)
+	pf.SetComment("")
+	pf.SetPcf(pcf)
+	pf.SetExtensions([]*ileapv1.DataModelExtension{ext})
+	now := timestamppb.New(b.now())
+	if prev, ok := b.previous[productID]; ok {
+		pf.SetId(prev.GetId())
+		pf.SetVersion(prev.GetVersion())
+		pf.SetCreated(prev.GetCreated())
+		if prev.HasUpdated() {
+			pf.SetUpdated(prev.GetUpdated())
+		}
+		if proto.Equal(pf, prev) {
+			return proto.CloneOf(prev), nil
+		}
+		pf.SetVersion(prev.GetVersion() + 1)
+		pf.SetUpdated(now)
+	} else {
+		id, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		pf.SetId(id)
+		pf.SetVersion(0)
+		pf.SetCreated(now)
+	}
+	if err := Validate(pf); err != nil {
+		return nil, fmt.Errorf("%s: %w", productID, err)
+	}
+	return pf, nil
+}
+
+// newCarbonFootprint returns a carbon footprint with the iLEAP defaults for
+// the PACT required fields.
+func newCarbonFootprint(
+	declaredUnit, unitaryProductAmount, co2eWTW string,
+	start, end time.Time,
+) *ileapv1.CarbonFootprint {
+	pcf := new(ileapv1.CarbonFootprint)
+	pcf.SetDeclaredUnit(declaredUnit)
+	pcf.SetUnitaryProductAmount(unitaryProductAmount)
+	pcf.SetPCfExcludingBiogenic(co2eWTW)
+	pcf.SetFossilGhgEmissions(co2eWTW)
+	pcf.SetFossilCarbonContent("0")
+	pcf.SetBiogenicCarbonContent("0")
+	pcf.SetCharacterizationFactors("AR6")
+	pcf.SetIpccCharacterizationFactorsSources([]string{"AR6"})
+	pcf.SetCrossSectoralStandardsUsed([]string{"GHG Protocol Product standard"})
+	pcf.SetBoundaryProcessesDescription(
+		"Well-to-wheel emissions calculated in accordance with the GLEC Framework and ISO 14083",
+	)
+	pcf.SetReferencePeriodStart(timestamppb.New(start))
+	pcf.SetReferencePeriodEnd(timestamppb.New(end))
+	pcf.SetExemptedEmissionsPercent(0)
+	pcf.SetExemptedEmissionsDescription("")
+	pcf.SetPackagingEmissionsIncluded(false)
+	return pcf
+}
+
+// commonCountry returns the country of the locations if they are all in the
+// same country, or an empty string for a global geography.
+func commonCountry(locations []*ileapv1.Location) string {
+	var country string
+	for _, location := range locations {
+		if location == nil {
+			continue
+		}
+		if location.GetCountry() == "" || country != "" && location.GetCountry() != country {
+			return ""
+		}
+		country = location.GetCountry()
+	}
+	return country
+}
+
+func description(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,172 @@
+package ileap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/way-platform/ileap-go/internal/testfixture"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// newTestShipment returns the shipment footprint of the shared test fixture,
+// with a TCE of the fixture TOC and a TCE of the fixture HOC.
+func newTestShipment(t *testing.T) *ileapv1.ShipmentFootprint {
+	t.Helper()
+	sf := &ileapv1.ShipmentFootprint{}
+	testfixture.Load(t, "shipmentFootprint", sf)
+	return sf
+}
+
+// newTestTOC returns the TOC of the shared test fixture.
+func newTestTOC(t *testing.T) *ileapv1.TOC {
+	t.Helper()
+	toc := &ileapv1.TOC{}
+	testfixture.Load(t, "toc", toc)
+	return toc
+}
+
+// newTestHOC returns the HOC of the shared test fixture.
+func newTestHOC(t *testing.T) *ileapv1.HOC {
+	t.Helper()
+	hoc := &ileapv1.HOC{}
+	testfixture.Load(t, "hoc", hoc)
+	return hoc
+}
+
+func newTestFootprintBuilder(now time.Time, opts ...FootprintBuilderOption) *FootprintBuilder {
+	b := NewFootprintBuilder("Acme Logistics", []string{"urn:acme:logistics"}, opts...)
+	b.now = func() time.Time { return now }
+	return b
+}
+
+func TestFootprintBuilder(t *testing.T) {
+	now := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	pfs, err := newTestFootprintBuilder(now).Build(
+		newTestShipment(t),
+		[]*ileapv1.TOC{newTestTOC(t)},
+		[]*ileapv1.HOC{newTestHOC(t)},
+	)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(pfs) != 3 {
+		t.Fatalf("expected 3 footprints, got %d", len(pfs))
+	}
+
+	t.Run("shipment footprint", func(t *testing.T) {
+		pf := pfs[0]
+		pcf := pf.GetPcf()
+		for _, check := range []struct{ field, got, want string }{
+			{"productIds", pf.GetProductIds()[0], productIDPrefixShipment + "shipment-1"},
+			{"productCategoryCpc", pf.GetProductCategoryCpc(), "83117"},
+			{"declaredUnit", pcf.GetDeclaredUnit(), "ton kilometer"},
+			{"unitaryProductAmount", pcf.GetUnitaryProductAmount(), "16920"},
+			{"pCfExcludingBiogenic", pcf.GetPCfExcludingBiogenic(), "3282.72"},
+			{"geographyCountry", pcf.GetGeographyCountry(), "DE"},
+			{"extension", pf.GetExtensions()[0].GetDataSchema(), DataSchemaShipmentFootprint},
+			{
+				"referencePeriodStart",
+				pcf.GetReferencePeriodStart().AsTime().Format(time.RFC3339),
+				"2025-03-01T08:00:00Z",
+			},
+			{
+				"referencePeriodEnd",
+				pcf.GetReferencePeriodEnd().AsTime().Format(time.RFC3339),
+				"2025-03-02T08:00:00Z",
+			},
+		} {
+			if check.got != check.want {
+				t.Errorf("%s = %q, want %q", check.field, check.got, check.want)
+			}
+		}
+		if pf.GetVersion() != 0 || !pf.GetCreated().AsTime().Equal(now) || pf.HasUpdated() {
+			t.Errorf("expected version 0 created now, got version %d created %v updated %v",
+				pf.GetVersion(), pf.GetCreated().AsTime(), pf.GetUpdated())
+		}
+		sf, err := ParseShipmentFootprint(pf.GetExtensions()[0])
+		if err != nil {
+			t.Fatalf("ParseShipmentFootprint: %v", err)
+		}
+		if len(sf.GetTces()) != 2 {
+			t.Errorf("expected 2 TCEs in extension, got %d", len(sf.GetTces()))
+		}
+	})
+
+	t.Run("operation category footprints", func(t *testing.T) {
+		for _, tc := range []struct {
+			pf                               *ileapv1.ProductFootprint
+			productID, unit, amount, co2e, c string
+		}{
+			{pfs[1], productIDPrefixTOC + "toc-road-1", "ton kilometer", "1", "0.116", ""},
+			{pfs[2], productIDPrefixHOC + "hoc-1", "kilogram", "1000", "33", "DE"},
+		} {
+			pcf := tc.pf.GetPcf()
+			if tc.pf.GetProductIds()[0] != tc.productID ||
+				pcf.GetDeclaredUnit() != tc.unit ||
+				pcf.GetUnitaryProductAmount() != tc.amount ||
+				pcf.GetPCfExcludingBiogenic() != tc.co2e ||
+				pcf.GetGeographyCountry() != tc.c {
+				t.Errorf("unexpected footprint %s: %v", tc.productID, tc.pf)
+			}
+			if len(tc.pf.GetExtensions()) != 1 {
+				t.Errorf("expected 1 extension, got %d", len(tc.pf.GetExtensions()))
+			}
+		}
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		later := now.Add(time.Hour)
+		b := newTestFootprintBuilder(later, WithPreviousFootprints(pfs...))
+		sf := newTestShipment(t)
+		unchanged, err := b.Build(sf, nil, nil)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if got := unchanged[0]; got.GetId() != pfs[0].GetId() || got.GetVersion() != 0 ||
+			got.HasUpdated() {
+			t.Errorf("expected unchanged footprint, got %v", got)
+		}
+		sf.GetTces()[0].SetCo2EWtw("1962.7")
+		changed, err := b.Build(sf, nil, nil)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		got := changed[0]
+		if got.GetId() != pfs[0].GetId() || got.GetVersion() != 1 ||
+			!got.GetCreated().AsTime().Equal(now) || !got.GetUpdated().AsTime().Equal(later) {
+			t.Errorf("expected version 1 of %s updated at %v, got %v", pfs[0].GetId(), later, got)
+		}
+		if got.GetPcf().GetPCfExcludingBiogenic() != "3282.7" {
+			t.Errorf("pCfExcludingBiogenic = %q, want %q",
+				got.GetPcf().GetPCfExcludingBiogenic(), "3282.7")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sf := newTestShipment(t)
+		sf.GetTces()[0].SetCo2EWtw("1e3")
+		if _, err := newTestFootprintBuilder(now).Build(sf, nil, nil); err == nil {
+			t.Error("expected error for invalid decimal")
+		}
+		b := NewFootprintBuilder("", nil)
+		_, err := b.Build(newTestShipment(t), nil, nil)
+		if validationErr := (*ValidationError)(nil); !errors.As(err, &validationErr) {
+			t.Errorf("expected *ValidationError for missing company, got %v", err)
+		}
+		sf = newTestShipment(t)
+		for _, tce := range sf.GetTces() {
+			tce.ClearDepartureAt()
+			tce.ClearArrivalAt()
+		}
+		if _, err := newTestFootprintBuilder(now).Build(sf, nil, nil); err == nil {
+			t.Error("expected error without reference period")
+		}
+		start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		_, err = newTestFootprintBuilder(now, WithReferencePeriod(start, start.AddDate(1, 0, 0))).
+			Build(sf, nil, nil)
+		if err != nil {
+			t.Errorf("Build with reference period: %v", err)
+		}
+	})
+}
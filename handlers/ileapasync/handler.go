@@ -0,0 +1,467 @@
+// Package ileapasync provides an event handler that fulfills asynchronous PACT
+// footprint requests.
+//
+// The [Handler] accepts ProductFootprintRequest.Created.v1 events, records them
+// in a [Store], resolves them to footprints with a [Resolver], and delivers a
+// ProductFootprintRequest.Fulfilled.v1 or ProductFootprintRequest.Rejected.v1
+// event back to the /2/events endpoint of the requester's host system,
+// discovered from the event source.
+//
+// Only requests of trusted requesters in a [Registry] are accepted, and each
+// requester must authenticate as the token subject registered for the host
+// of its event source.
+package ileapasync
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoffBase = time.Second
+	defaultBackoffMax  = time.Minute
+	defaultMaxClients  = 100
+)
+
+// Option configures a [Handler].
+type Option func(*Handler)
+
+// WithStore sets the store for footprint requests. Defaults to a [MemoryStore].
+func WithStore(store Store) Option {
+	return func(h *Handler) { h.store = store }
+}
+
+// WithClientOptions appends options to the [ileap.Client] used to deliver events.
+func WithClientOptions(opts ...ileap.ClientOption) Option {
+	return func(h *Handler) { h.clientOpts = append(h.clientOpts, opts...) }
+}
+
+// WithMaxAttempts sets the maximum number of delivery attempts per request.
+// Defaults to 5.
+func WithMaxAttempts(n int) Option {
+	return func(h *Handler) { h.maxAttempts = n }
+}
+
+// WithBackoff sets the base and maximum delay between delivery attempts.
+// The delay doubles after each attempt, with full jitter. Defaults to 1s and 1m.
+func WithBackoff(base, maxDelay time.Duration) Option {
+	return func(h *Handler) {
+		h.backoffBase = base
+		h.backoffMax = maxDelay
+	}
+}
+
+// WithMaxClients sets the maximum number of clients, and their access tokens,
+// kept for delivering events to requesters. The least recently used clients
+// are evicted first. Defaults to 100.
+func WithMaxClients(n int) Option {
+	return func(h *Handler) { h.maxClients = n }
+}
+
+// WithNextHandler sets the handler for all events other than
+// ProductFootprintRequest.Created.v1. Defaults to [ileap.NopEventHandler].
+func WithNextHandler(next ileap.EventHandler) Option {
+	return func(h *Handler) { h.next = next }
+}
+
+// Handler is an [ileap.EventHandler] that fulfills asynchronous footprint requests.
+type Handler struct {
+	source      string
+	resolver    Resolver
+	registry    Registry
+	store       Store
+	clientOpts  []ileap.ClientOption
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	maxClients  int
+	next        ileap.EventHandler
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+	clients map[Requester]*list.Element
+	lru     *list.List
+}
+
+type clientEntry struct {
+	requester Requester
+	client    *ileap.Client
+}
+
+var _ ileap.EventHandler = (*Handler)(nil)
+
+// NewHandler creates a new [Handler].
+//
+// The source is the CloudEvents source URI of this host system, set on all
+// delivered events. The registry holds the trusted requesters and their
+// credentials. Requests are processed in the background until [Handler.Close]
+// is called.
+func NewHandler(source string, resolver Resolver, registry Registry, opts ...Option) *Handler {
+	h := &Handler{
+		source:      source,
+		resolver:    resolver,
+		registry:    registry,
+		store:       NewMemoryStore(),
+		maxAttempts: defaultMaxAttempts,
+		backoffBase: defaultBackoffBase,
+		backoffMax:  defaultBackoffMax,
+		maxClients:  defaultMaxClients,
+		next:        ileap.NopEventHandler{},
+		clients:     make(map[Requester]*list.Element),
+		lru:         list.New(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.ctx, h.cancel = context.WithCancel(context.Background())
+	return h
+}
+
+// HandleRequestCreated implements [ileap.EventHandler].
+//
+// The request is stored and processed in the background. Duplicate events,
+// with the same source and ID as a stored request, are ignored. Requests from
+// sources that are not registered, or that were sent with the token of
+// another subject than the registered requester, are rejected as
+// connect.CodePermissionDenied. After [Handler.Close], requests are refused
+// as connect.CodeUnavailable.
+func (h *Handler) HandleRequestCreated(
+	ctx context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.RequestCreatedEvent,
+) error {
+	if h.isClosed() {
+		return errClosed()
+	}
+	requester, err := h.requester(ctx, event.Source)
+	if err != nil {
+		return err
+	}
+	if info == nil || info.Subject != requester.Subject {
+		return connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("event source %q does not belong to the caller", event.Source),
+		)
+	}
+	request := &Request{
+		EventID:    event.ID,
+		Source:     event.Source,
+		TokenInfo:  info,
+		Footprint:  event.Footprint,
+		Comment:    event.Comment,
+		ReceivedAt: time.Now(),
+		Status:     StatusPending,
+	}
+	created, err := h.store.Create(ctx, request)
+	if err != nil {
+		return fmt.Errorf("store request: %w", err)
+	}
+	if created {
+		return h.start(request)
+	}
+	return nil
+}
+
+// HandleRequestFulfilled implements [ileap.EventHandler].
+func (h *Handler) HandleRequestFulfilled(
+	ctx context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.RequestFulfilledEvent,
+) error {
+	return h.next.HandleRequestFulfilled(ctx, info, event)
+}
+
+// HandleRequestRejected implements [ileap.EventHandler].
+func (h *Handler) HandleRequestRejected(
+	ctx context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.RequestRejectedEvent,
+) error {
+	return h.next.HandleRequestRejected(ctx, info, event)
+}
+
+// HandlePublished implements [ileap.EventHandler].
+func (h *Handler) HandlePublished(
+	ctx context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.PublishedEvent,
+) error {
+	return h.next.HandlePublished(ctx, info, event)
+}
+
+// Resume restarts processing of all pending requests in the store. Call it
+// once at startup when using a persistent store.
+func (h *Handler) Resume(ctx context.Context) error {
+	requests, err := h.store.ListPending(ctx)
+	if err != nil {
+		return fmt.Errorf("list pending requests: %w", err)
+	}
+	for _, request := range requests {
+		if err := h.start(request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops background processing and waits for in-flight work to finish.
+// Requests that have not been answered remain pending in the store.
+func (h *Handler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	h.mu.Unlock()
+	h.cancel()
+	h.wg.Wait()
+	return nil
+}
+
+func (h *Handler) isClosed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+// start processes the request in the background, unless the handler is closed.
+// The closed check and [sync.WaitGroup.Go] happen under the lock, so that
+// [Handler.Close] never waits concurrently with a new goroutine being added.
+func (h *Handler) start(request *Request) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return errClosed()
+	}
+	h.wg.Go(func() {
+		if err := h.process(h.ctx, request); err != nil && h.ctx.Err() == nil {
+			slog.WarnContext(
+				h.ctx,
+				"failed to process footprint request",
+				"eventId", request.EventID,
+				"source", request.Source,
+				"error", err,
+			)
+		}
+	})
+	return nil
+}
+
+func (h *Handler) process(ctx context.Context, request *Request) error {
+	if !request.resolved() {
+		resolveCtx := ileap.WithTokenInfo(ctx, request.TokenInfo)
+		footprints, err := h.resolver.Resolve(resolveCtx, request)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			request.Rejection = rejectionFromError(err)
+		case len(footprints) == 0:
+			request.Rejection = &ileap.Error{
+				Code:    ileap.ErrorCodeNotFound,
+				Message: "no matching footprints",
+			}
+		default:
+			request.Footprints = footprints
+		}
+		if err := h.store.Update(ctx, request); err != nil {
+			return fmt.Errorf("store resolved request: %w", err)
+		}
+	}
+	if request.Status == StatusPending && request.Attempts >= h.maxAttempts {
+		// Exhausted before a restart, or the max attempts were lowered since.
+		request.Status = StatusFailed
+		if request.LastError == "" {
+			request.LastError = "max delivery attempts exceeded"
+		}
+		if err := h.store.Update(ctx, request); err != nil {
+			return fmt.Errorf("store delivery status: %w", err)
+		}
+		return fmt.Errorf("deliver response: %s", request.LastError)
+	}
+	for request.Attempts < h.maxAttempts {
+		if request.Attempts > 0 {
+			if err := sleep(ctx, h.backoff(request.Attempts)); err != nil {
+				return err
+			}
+		}
+		err := h.deliver(ctx, request)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		request.Attempts++
+		switch {
+		case err == nil:
+			request.Status = StatusFulfilled
+			if request.Rejection != nil {
+				request.Status = StatusRejected
+			}
+			request.LastError = ""
+		case !isRetryable(err) || request.Attempts >= h.maxAttempts:
+			request.Status = StatusFailed
+			request.LastError = err.Error()
+		default:
+			request.LastError = err.Error()
+		}
+		if err := h.store.Update(ctx, request); err != nil {
+			return fmt.Errorf("store delivery status: %w", err)
+		}
+		if request.Status != StatusPending {
+			if request.Status == StatusFailed {
+				return fmt.Errorf("deliver response: %s", request.LastError)
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (h *Handler) deliver(ctx context.Context, request *Request) error {
+	client, err := h.client(ctx, request.Source)
+	if err != nil {
+		return err
+	}
+	if request.Rejection != nil {
+		_, err = client.RejectRequest(ctx, &ileap.RejectRequestParams{
+			RequestEventID: request.EventID,
+			Error:          request.Rejection,
+		})
+		return err
+	}
+	_, err = client.FulfillRequest(ctx, &ileap.FulfillRequestParams{
+		RequestEventID: request.EventID,
+		Footprints:     request.Footprints,
+	})
+	return err
+}
+
+// requester returns the registered requester of an event source.
+func (h *Handler) requester(ctx context.Context, source string) (*Requester, error) {
+	baseURL, err := requesterBaseURL(source)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	requester, err := h.registry.Lookup(ctx, baseURL)
+	if err != nil {
+		if connect.CodeOf(err) == connect.CodeNotFound {
+			return nil, connect.NewError(
+				connect.CodePermissionDenied,
+				fmt.Errorf("event source %q is not a trusted requester", source),
+			)
+		}
+		return nil, fmt.Errorf("look up requester %s: %w", baseURL, err)
+	}
+	return requester, nil
+}
+
+// client returns the client for the requester's host system, reusing clients
+// and their access tokens across deliveries to the same requester.
+func (h *Handler) client(ctx context.Context, source string) (*ileap.Client, error) {
+	requester, err := h.requester(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if elem, ok := h.clients[*requester]; ok {
+		h.lru.MoveToFront(elem)
+		return elem.Value.(*clientEntry).client, nil
+	}
+	opts := []ileap.ClientOption{
+		ileap.WithBaseURL(requester.BaseURL),
+		ileap.WithEventSource(h.source),
+	}
+	if requester.ClientID != "" {
+		opts = append(opts, ileap.WithOAuth2(requester.ClientID, requester.ClientSecret))
+	}
+	client := ileap.NewClient(append(opts, h.clientOpts...)...)
+	if h.maxClients > 0 {
+		h.clients[*requester] = h.lru.PushFront(&clientEntry{
+			requester: *requester,
+			client:    client,
+		})
+	}
+	for h.lru.Len() > max(h.maxClients, 0) {
+		oldest := h.lru.Back()
+		h.lru.Remove(oldest)
+		delete(h.clients, oldest.Value.(*clientEntry).requester)
+	}
+	return client, nil
+}
+
+// backoff returns the delay before the next attempt, after the given number of attempts.
+func (h *Handler) backoff(attempts int) time.Duration {
+	delay := h.backoffMax
+	if shift := attempts - 1; shift < 32 && h.backoffBase<<shift < h.backoffMax {
+		delay = h.backoffBase << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay) + 1
+}
+
+func errClosed() error {
+	return connect.NewError(connect.CodeUnavailable, errors.New("handler is closed"))
+}
+
+// requesterBaseURL returns the base URL of the requester's host system from
+// the event source, e.g. "https://example.com" for "//example.com/webhook".
+func requesterBaseURL(source string) (string, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("parse event source: %w", err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("event source %q has no host", source)
+	}
+	switch u.Scheme {
+	case "":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("event source %q has unsupported scheme", source)
+	}
+	return u.Scheme + "://" + u.Host, nil
+}
+
+// isRetryable reports whether a failed delivery may succeed when retried.
+func isRetryable(err error) bool {
+	switch connect.CodeOf(err) {
+	case connect.CodeInvalidArgument, connect.CodePermissionDenied:
+		return false
+	}
+	var clientErr *ileap.ClientError
+	if !errors.As(err, &clientErr) {
+		return true
+	}
+	switch clientErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return clientErr.StatusCode >= 500
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
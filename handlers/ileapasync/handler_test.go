@@ -0,0 +1,521 @@
+package ileapasync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/handlers/ileapdemo"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+const demoProductID = "urn:pathfinder:product:customcode:vendor-assigned:shipment:shipment-simple-1"
+
+// requesterHandler records response events received by the requester.
+type requesterHandler struct {
+	ileap.NopEventHandler
+	mu        sync.Mutex
+	subjects  []string
+	fulfilled []*ileap.RequestFulfilledEvent
+	rejected  []*ileap.RequestRejectedEvent
+}
+
+func (h *requesterHandler) HandleRequestFulfilled(
+	_ context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.RequestFulfilledEvent,
+) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subjects = append(h.subjects, info.Subject)
+	h.fulfilled = append(h.fulfilled, event)
+	return nil
+}
+
+func (h *requesterHandler) HandleRequestRejected(
+	_ context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.RequestRejectedEvent,
+) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subjects = append(h.subjects, info.Subject)
+	h.rejected = append(h.rejected, event)
+	return nil
+}
+
+// newRequester starts a requester host system that fails the first
+// failures event deliveries with the given status code.
+func newRequester(
+	t *testing.T,
+	failures int32,
+	failureStatus int,
+) (*httptest.Server, *requesterHandler) {
+	t.Helper()
+	auth, err := ileapdemo.NewAuthProvider()
+	if err != nil {
+		t.Fatalf("create auth provider: %v", err)
+	}
+	events := &requesterHandler{}
+	server := ileap.NewServer(
+		ileap.WithAuthHandler(auth),
+		ileap.WithEventHandler(events),
+	)
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/2/events" && count.Add(1) <= failures {
+			w.WriteHeader(failureStatus)
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, events
+}
+
+// newTestHandler creates a handler that trusts the requester at requesterURL,
+// authenticating as the subject "requester".
+func newTestHandler(t *testing.T, requesterURL string, opts ...Option) (*Handler, *MemoryStore) {
+	t.Helper()
+	demo, err := ileapdemo.NewHandler()
+	if err != nil {
+		t.Fatalf("create demo handler: %v", err)
+	}
+	registry, err := NewStaticRegistry(Requester{
+		BaseURL:      requesterURL,
+		Subject:      "requester",
+		ClientID:     "hello",
+		ClientSecret: "pathfinder",
+	})
+	if err != nil {
+		t.Fatalf("create registry: %v", err)
+	}
+	store := NewMemoryStore()
+	h := NewHandler(
+		"//owner.example.com",
+		NewServiceResolver(demo),
+		registry,
+		append([]Option{
+			WithStore(store),
+			WithBackoff(time.Millisecond, 5*time.Millisecond),
+		}, opts...)...,
+	)
+	t.Cleanup(func() { _ = h.Close() })
+	return h, store
+}
+
+func sendRequest(t *testing.T, h *Handler, source, eventID string, productIDs ...string) {
+	t.Helper()
+	pf := new(ileapv1.ProductFootprint)
+	pf.SetProductIds(productIDs)
+	err := h.HandleRequestCreated(
+		t.Context(),
+		&ileap.TokenInfo{Subject: "requester"},
+		&ileap.RequestCreatedEvent{
+			EventMetadata: ileap.EventMetadata{ID: eventID, Source: source},
+			Footprint:     pf,
+		},
+	)
+	if err != nil {
+		t.Fatalf("HandleRequestCreated: %v", err)
+	}
+}
+
+func waitForStatus(t *testing.T, store Store, source, eventID string) *Request {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		request, err := store.Get(t.Context(), source, eventID)
+		if err != nil {
+			t.Fatalf("get request: %v", err)
+		}
+		if request.Status != StatusPending {
+			return request
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("request %s still pending", eventID)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("fulfilled", func(t *testing.T) {
+		requester, events := newRequester(t, 0, 0)
+		h, store := newTestHandler(t, requester.URL)
+		sendRequest(t, h, requester.URL, "evt-fulfilled", demoProductID)
+		request := waitForStatus(t, store, requester.URL, "evt-fulfilled")
+		if request.Status != StatusFulfilled {
+			t.Fatalf(
+				"status = %s, want Fulfilled (last error: %s)",
+				request.Status,
+				request.LastError,
+			)
+		}
+		if request.TokenInfo.Subject != "requester" {
+			t.Errorf("subject = %q, want requester", request.TokenInfo.Subject)
+		}
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		if len(events.fulfilled) != 1 {
+			t.Fatalf("expected 1 fulfilled event, got %d", len(events.fulfilled))
+		}
+		got := events.fulfilled[0]
+		if got.RequestEventID != "evt-fulfilled" {
+			t.Errorf("RequestEventID = %q", got.RequestEventID)
+		}
+		if got.Source != "//owner.example.com" {
+			t.Errorf("Source = %q", got.Source)
+		}
+		if len(got.Footprints) == 0 {
+			t.Fatal("expected footprints")
+		}
+		for _, fp := range got.Footprints {
+			if len(fp.GetProductIds()) == 0 || fp.GetProductIds()[0] != demoProductID {
+				t.Errorf("unexpected footprint product IDs %v", fp.GetProductIds())
+			}
+		}
+		if events.subjects[0] != "hello" {
+			t.Errorf("delivered as %q, want hello", events.subjects[0])
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		requester, events := newRequester(t, 0, 0)
+		h, store := newTestHandler(t, requester.URL)
+		sendRequest(t, h, requester.URL, "evt-rejected", "urn:pact:null")
+		request := waitForStatus(t, store, requester.URL, "evt-rejected")
+		if request.Status != StatusRejected {
+			t.Fatalf(
+				"status = %s, want Rejected (last error: %s)",
+				request.Status,
+				request.LastError,
+			)
+		}
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		if len(events.rejected) != 1 {
+			t.Fatalf("expected 1 rejected event, got %d", len(events.rejected))
+		}
+		if got := events.rejected[0].Error.Code; got != ileap.ErrorCodeNotFound {
+			t.Errorf("error code = %s, want NotFound", got)
+		}
+	})
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		requester, events := newRequester(t, 2, http.StatusServiceUnavailable)
+		h, store := newTestHandler(t, requester.URL)
+		sendRequest(t, h, requester.URL, "evt-retry", demoProductID)
+		request := waitForStatus(t, store, requester.URL, "evt-retry")
+		if request.Status != StatusFulfilled {
+			t.Fatalf(
+				"status = %s, want Fulfilled (last error: %s)",
+				request.Status,
+				request.LastError,
+			)
+		}
+		if request.Attempts != 3 {
+			t.Errorf("attempts = %d, want 3", request.Attempts)
+		}
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		if len(events.fulfilled) != 1 {
+			t.Errorf("expected 1 fulfilled event, got %d", len(events.fulfilled))
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		requester, _ := newRequester(t, 10, http.StatusServiceUnavailable)
+		h, store := newTestHandler(t, requester.URL, WithMaxAttempts(3))
+		sendRequest(t, h, requester.URL, "evt-exhausted", demoProductID)
+		request := waitForStatus(t, store, requester.URL, "evt-exhausted")
+		if request.Status != StatusFailed {
+			t.Fatalf("status = %s, want Failed", request.Status)
+		}
+		if request.Attempts != 3 {
+			t.Errorf("attempts = %d, want 3", request.Attempts)
+		}
+		if request.LastError == "" {
+			t.Error("expected last error to be recorded")
+		}
+	})
+
+	t.Run("does not retry permanent failures", func(t *testing.T) {
+		requester, _ := newRequester(t, 10, http.StatusBadRequest)
+		h, store := newTestHandler(t, requester.URL)
+		sendRequest(t, h, requester.URL, "evt-permanent", demoProductID)
+		request := waitForStatus(t, store, requester.URL, "evt-permanent")
+		if request.Status != StatusFailed {
+			t.Fatalf("status = %s, want Failed", request.Status)
+		}
+		if request.Attempts != 1 {
+			t.Errorf("attempts = %d, want 1", request.Attempts)
+		}
+	})
+
+	t.Run("ignores duplicate events", func(t *testing.T) {
+		requester, events := newRequester(t, 0, 0)
+		h, store := newTestHandler(t, requester.URL)
+		sendRequest(t, h, requester.URL, "evt-duplicate", demoProductID)
+		waitForStatus(t, store, requester.URL, "evt-duplicate")
+		sendRequest(t, h, requester.URL, "evt-duplicate", demoProductID)
+		if err := h.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		if len(events.fulfilled) != 1 {
+			t.Errorf("expected 1 fulfilled event, got %d", len(events.fulfilled))
+		}
+	})
+
+	t.Run("fails exhausted requests on resume", func(t *testing.T) {
+		requester, events := newRequester(t, 0, 0)
+		h, store := newTestHandler(t, requester.URL, WithMaxAttempts(3))
+		if _, err := store.Create(t.Context(), &Request{
+			EventID:    "evt-exhausted",
+			Source:     requester.URL,
+			TokenInfo:  &ileap.TokenInfo{Subject: "requester"},
+			ReceivedAt: time.Now(),
+			Status:     StatusPending,
+			Attempts:   3,
+			LastError:  "unavailable",
+			Rejection:  &ileap.Error{Code: ileap.ErrorCodeNotFound, Message: "not found"},
+		}); err != nil {
+			t.Fatalf("create request: %v", err)
+		}
+		if err := h.Resume(t.Context()); err != nil {
+			t.Fatalf("Resume: %v", err)
+		}
+		request := waitForStatus(t, store, requester.URL, "evt-exhausted")
+		if request.Status != StatusFailed {
+			t.Errorf("status = %v, want %v", request.Status, StatusFailed)
+		}
+		if request.Attempts != 3 {
+			t.Errorf("attempts = %d, want 3", request.Attempts)
+		}
+		events.mu.Lock()
+		defer events.mu.Unlock()
+		if len(events.rejected) != 0 {
+			t.Errorf("expected no delivered events, got %d", len(events.rejected))
+		}
+	})
+
+	t.Run("refuses requests after close", func(t *testing.T) {
+		requester, _ := newRequester(t, 0, 0)
+		h, store := newTestHandler(t, requester.URL)
+		if err := h.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		pf := new(ileapv1.ProductFootprint)
+		pf.SetProductIds([]string{demoProductID})
+		err := h.HandleRequestCreated(
+			t.Context(),
+			&ileap.TokenInfo{Subject: "requester"},
+			&ileap.RequestCreatedEvent{
+				EventMetadata: ileap.EventMetadata{ID: "evt-closed", Source: requester.URL},
+				Footprint:     pf,
+			},
+		)
+		if connect.CodeOf(err) != connect.CodeUnavailable {
+			t.Errorf("expected Unavailable after Close, got %v", err)
+		}
+		pending, err := store.ListPending(t.Context())
+		if err != nil || len(pending) != 0 {
+			t.Errorf("expected no stored requests, got %v, %v", pending, err)
+		}
+	})
+
+	t.Run("invalid source", func(t *testing.T) {
+		h, _ := newTestHandler(t, "https://requester.example.com")
+		err := h.HandleRequestCreated(t.Context(), nil, &ileap.RequestCreatedEvent{
+			EventMetadata: ileap.EventMetadata{ID: "evt-invalid", Source: "urn:no-host"},
+		})
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Fatalf("expected InvalidArgument for source without host, got %v", err)
+		}
+	})
+
+	t.Run("untrusted requester", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			source string
+			info   *ileap.TokenInfo
+		}{
+			{"unknown source", "//internal.example.com", &ileap.TokenInfo{Subject: "requester"}},
+			{"other subject", "//requester.example.com", &ileap.TokenInfo{Subject: "other"}},
+			{"unauthenticated", "//requester.example.com", nil},
+		} {
+			h, store := newTestHandler(t, "https://requester.example.com")
+			err := h.HandleRequestCreated(t.Context(), tc.info, &ileap.RequestCreatedEvent{
+				EventMetadata: ileap.EventMetadata{ID: "evt-untrusted", Source: tc.source},
+			})
+			if connect.CodeOf(err) != connect.CodePermissionDenied {
+				t.Errorf("%s: expected PermissionDenied, got %v", tc.name, err)
+			}
+			pending, err := store.ListPending(t.Context())
+			if err != nil || len(pending) != 0 {
+				t.Errorf("%s: expected no stored requests, got %v, %v", tc.name, pending, err)
+			}
+		}
+	})
+
+	t.Run("resolves with requester identity", func(t *testing.T) {
+		requester, _ := newRequester(t, 0, 0)
+		resolved := make(chan *ileap.TokenInfo, 1)
+		registry, err := NewStaticRegistry(Requester{BaseURL: requester.URL, Subject: "requester"})
+		if err != nil {
+			t.Fatalf("create registry: %v", err)
+		}
+		h := NewHandler(
+			"//owner.example.com",
+			ResolverFunc(
+				func(ctx context.Context, _ *Request) ([]*ileapv1.ProductFootprint, error) {
+					info, _ := ileap.TokenInfoFromContext(ctx)
+					resolved <- info
+					return nil, nil
+				},
+			),
+			registry,
+			WithMaxAttempts(1),
+		)
+		t.Cleanup(func() { _ = h.Close() })
+		sendRequest(t, h, requester.URL, "evt-identity", demoProductID)
+		if info := <-resolved; info == nil || info.Subject != "requester" {
+			t.Errorf("resolved with token info %+v, want subject requester", info)
+		}
+	})
+
+	t.Run("bounds clients", func(t *testing.T) {
+		registry, err := NewStaticRegistry(
+			Requester{BaseURL: "https://a.example.com", ClientID: "a"},
+			Requester{BaseURL: "https://b.example.com", ClientID: "b"},
+			Requester{BaseURL: "https://c.example.com", ClientID: "c"},
+		)
+		if err != nil {
+			t.Fatalf("create registry: %v", err)
+		}
+		h := NewHandler("//owner.example.com", nil, registry, WithMaxClients(2))
+		t.Cleanup(func() { _ = h.Close() })
+		for _, source := range []string{"//a.example.com", "//b.example.com", "//c.example.com"} {
+			if _, err := h.client(t.Context(), source); err != nil {
+				t.Fatalf("client(%s): %v", source, err)
+			}
+		}
+		if n := h.lru.Len(); n != 2 || len(h.clients) != 2 {
+			t.Errorf("kept %d clients, want 2", n)
+		}
+		if _, err := h.client(t.Context(), "//d.example.com"); connect.CodeOf(err) !=
+			connect.CodePermissionDenied {
+			t.Errorf("expected PermissionDenied for unknown requester, got %v", err)
+		}
+	})
+}
+
+func TestNewStaticRegistry(t *testing.T) {
+	registry, err := NewStaticRegistry(Requester{BaseURL: "https://example.com/", Subject: "a"})
+	if err != nil {
+		t.Fatalf("NewStaticRegistry: %v", err)
+	}
+	requester, err := registry.Lookup(t.Context(), "https://example.com")
+	if err != nil || requester.Subject != "a" {
+		t.Errorf("Lookup = %+v, %v", requester, err)
+	}
+	if _, err := registry.Lookup(t.Context(), "http://example.com"); connect.CodeOf(err) !=
+		connect.CodeNotFound {
+		t.Errorf("expected NotFound for other scheme, got %v", err)
+	}
+	_, err = NewStaticRegistry(
+		Requester{BaseURL: "https://example.com"},
+		Requester{BaseURL: "//example.com/webhook"},
+	)
+	if err == nil {
+		t.Error("expected error for duplicate requester")
+	}
+	if _, err := NewStaticRegistry(Requester{BaseURL: "example.com"}); err == nil {
+		t.Error("expected error for base URL without host")
+	}
+}
+
+func TestRequesterBaseURL(t *testing.T) {
+	testCases := []struct {
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{source: "//test.example.com", want: "https://test.example.com"},
+		{source: "//test.example.com/webhook", want: "https://test.example.com"},
+		{source: "http://localhost:8080/events", want: "http://localhost:8080"},
+		{source: "urn:example", wantErr: true},
+		{source: "ftp://example.com", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.source, func(t *testing.T) {
+			got, err := requesterBaseURL(tc.source)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// denyPolicy denies access to all footprints and TADs, and records the
+// subjects of the checked callers.
+type denyPolicy struct {
+	subjects []string
+}
+
+func (p *denyPolicy) CanAccessFootprint(
+	_ context.Context, info *ileap.TokenInfo, _ *ileapv1.ProductFootprint,
+) (bool, error) {
+	p.subjects = append(p.subjects, info.Subject)
+	return false, nil
+}
+
+func (p *denyPolicy) CanAccessTAD(
+	context.Context, *ileap.TokenInfo, *ileapv1.TAD,
+) (bool, error) {
+	return false, nil
+}
+
+func TestNewServiceResolver(t *testing.T) {
+	demo, err := ileapdemo.NewHandler()
+	if err != nil {
+		t.Fatalf("create demo handler: %v", err)
+	}
+	pf := new(ileapv1.ProductFootprint)
+	pf.SetProductIds([]string{demoProductID})
+	request := &Request{Footprint: pf}
+	ctx := ileap.WithTokenInfo(t.Context(), &ileap.TokenInfo{Subject: "requester"})
+
+	footprints, err := NewServiceResolver(demo).Resolve(ctx, request)
+	if err != nil || len(footprints) == 0 {
+		t.Fatalf("Resolve = %d footprints, %v, want footprints", len(footprints), err)
+	}
+
+	t.Run("access policy", func(t *testing.T) {
+		policy := &denyPolicy{}
+		footprints, err := NewServiceResolver(demo, WithAccessPolicy(policy)).
+			Resolve(ctx, request)
+		if err != nil || len(footprints) != 0 {
+			t.Errorf("Resolve = %d footprints, %v, want none", len(footprints), err)
+		}
+		if len(policy.subjects) == 0 || policy.subjects[0] != "requester" {
+			t.Errorf("policy checked subjects %v, want requester", policy.subjects)
+		}
+	})
+}
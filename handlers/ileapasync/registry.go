@@ -0,0 +1,70 @@
+package ileapasync
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+)
+
+// Requester is a trusted host system that may send footprint requests.
+type Requester struct {
+	// BaseURL is the base URL of the requester's host system, e.g.
+	// "https://example.com". Requests are accepted from event sources on this
+	// host, and responses are delivered to its /2/events endpoint.
+	BaseURL string
+	// Subject is the subject of the access tokens the requester authenticates
+	// with. Requests sent with tokens of other subjects are rejected.
+	Subject string
+	// ClientID and ClientSecret are the OAuth 2.0 client credentials to
+	// authenticate against the requester's host system. Without a client ID,
+	// responses are delivered unauthenticated.
+	ClientID     string
+	ClientSecret string
+}
+
+// Registry looks up trusted requesters.
+type Registry interface {
+	// Lookup returns the requester with the given base URL, e.g.
+	// "https://example.com". It must return connect.CodeNotFound for untrusted
+	// host systems.
+	Lookup(ctx context.Context, baseURL string) (*Requester, error)
+}
+
+// StaticRegistry is a [Registry] of a fixed set of requesters.
+type StaticRegistry struct {
+	requesters map[string]*Requester
+}
+
+var _ Registry = (*StaticRegistry)(nil)
+
+// NewStaticRegistry creates a new [StaticRegistry] of the given requesters.
+// It returns an error for invalid or duplicate base URLs.
+func NewStaticRegistry(requesters ...Requester) (*StaticRegistry, error) {
+	r := &StaticRegistry{requesters: make(map[string]*Requester, len(requesters))}
+	for _, requester := range requesters {
+		baseURL, err := requesterBaseURL(requester.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := r.requesters[baseURL]; ok {
+			return nil, fmt.Errorf("duplicate requester %s", baseURL)
+		}
+		requester.BaseURL = baseURL
+		r.requesters[baseURL] = &requester
+	}
+	return r, nil
+}
+
+// Lookup implements [Registry].
+func (r *StaticRegistry) Lookup(_ context.Context, baseURL string) (*Requester, error) {
+	requester, ok := r.requesters[baseURL]
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeNotFound,
+			fmt.Errorf("unknown requester %s", baseURL),
+		)
+	}
+	result := *requester
+	return &result, nil
+}
@@ -0,0 +1,135 @@
+package ileapasync
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+)
+
+// Resolver resolves a footprint request to the footprints that fulfill it.
+//
+// The context carries the [ileap.TokenInfo] of the requester, see
+// [ileap.TokenInfoFromContext], so that only footprints the requester may
+// access are resolved.
+//
+// Returning no footprints rejects the request with a NotFound error.
+// Returning an [*ileap.Error] rejects the request with that error; other
+// errors are mapped to a PACT error using their connect.Code.
+type Resolver interface {
+	Resolve(ctx context.Context, request *Request) ([]*ileapv1.ProductFootprint, error)
+}
+
+// ResolverFunc is a function that implements [Resolver].
+type ResolverFunc func(ctx context.Context, request *Request) ([]*ileapv1.ProductFootprint, error)
+
+// Resolve implements [Resolver].
+func (f ResolverFunc) Resolve(
+	ctx context.Context,
+	request *Request,
+) ([]*ileapv1.ProductFootprint, error) {
+	return f(ctx, request)
+}
+
+// ResolverOption configures the [Resolver] of [NewServiceResolver].
+type ResolverOption func(*serviceResolver)
+
+// WithAccessPolicy leaves the footprints the requester may not access out of
+// the resolved footprints. Use the policy of the iLEAP server, so that
+// requesters cannot obtain footprints by request that they could not list.
+func WithAccessPolicy(policy ileap.AccessPolicy) ResolverOption {
+	return func(r *serviceResolver) { r.policy = policy }
+}
+
+// NewServiceResolver returns a [Resolver] that lists the footprints of the
+// requested product IDs from an ILeapServiceHandler.
+//
+// Each product ID is looked up with a productIds EQ filter, with the context
+// of the requester, and the results are merged. Requests without product IDs
+// are rejected as bad requests.
+func NewServiceResolver(
+	handler ileapv1connect.ILeapServiceHandler,
+	opts ...ResolverOption,
+) Resolver {
+	r := &serviceResolver{handler: handler}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type serviceResolver struct {
+	handler ileapv1connect.ILeapServiceHandler
+	policy  ileap.AccessPolicy
+}
+
+// Resolve implements [Resolver].
+func (r *serviceResolver) Resolve(
+	ctx context.Context,
+	request *Request,
+) ([]*ileapv1.ProductFootprint, error) {
+	productIDs := request.Footprint.GetProductIds()
+	if len(productIDs) == 0 {
+		return nil, &ileap.Error{
+			Code:    ileap.ErrorCodeBadRequest,
+			Message: "request must specify productIds",
+		}
+	}
+	info, _ := ileap.TokenInfoFromContext(ctx)
+	var result []*ileapv1.ProductFootprint
+	seen := make(map[string]struct{})
+	for _, productID := range productIDs {
+		filter := new(ileapv1.Filter)
+		filter.SetFieldPath("productIds")
+		filter.SetOperator(ileapv1.Filter_EQ)
+		filter.SetValue(productID)
+		listRequest := new(ileapv1.ListFootprintsRequest)
+		listRequest.SetFilters([]*ileapv1.Filter{filter})
+		resp, err := r.handler.ListFootprints(ctx, listRequest)
+		if err != nil {
+			return nil, err
+		}
+		for _, fp := range resp.GetData() {
+			if _, ok := seen[fp.GetId()]; ok {
+				continue
+			}
+			seen[fp.GetId()] = struct{}{}
+			if r.policy != nil {
+				ok, err := r.policy.CanAccessFootprint(ctx, info, fp)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			result = append(result, fp)
+		}
+	}
+	return result, nil
+}
+
+// rejectionFromError maps a resolver error to a PACT error.
+func rejectionFromError(err error) *ileap.Error {
+	var pactErr *ileap.Error
+	if errors.As(err, &pactErr) {
+		return pactErr
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		switch connectErr.Code() {
+		case connect.CodeNotFound:
+			return &ileap.Error{Code: ileap.ErrorCodeNotFound, Message: "no matching footprints"}
+		case connect.CodeInvalidArgument:
+			return &ileap.Error{Code: ileap.ErrorCodeBadRequest, Message: connectErr.Message()}
+		case connect.CodePermissionDenied, connect.CodeUnauthenticated:
+			return &ileap.Error{Code: ileap.ErrorCodeAccessDenied, Message: "access denied"}
+		case connect.CodeUnimplemented:
+			return &ileap.Error{Code: ileap.ErrorCodeNotImplemented, Message: "not implemented"}
+		}
+	}
+	return &ileap.Error{Code: ileap.ErrorCodeInternalError, Message: "internal error"}
+}
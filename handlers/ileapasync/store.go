@@ -0,0 +1,139 @@
+package ileapasync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// Status is the processing status of a footprint request.
+type Status string
+
+// Known request statuses.
+const (
+	// StatusPending is a request that has not yet been answered.
+	StatusPending Status = "Pending"
+	// StatusFulfilled is a request answered with a Fulfilled event.
+	StatusFulfilled Status = "Fulfilled"
+	// StatusRejected is a request answered with a Rejected event.
+	StatusRejected Status = "Rejected"
+	// StatusFailed is a request whose response could not be delivered.
+	StatusFailed Status = "Failed"
+)
+
+// Request is an asynchronous footprint request received from a data recipient.
+type Request struct {
+	// EventID is the ID of the ProductFootprintRequest.Created.v1 event.
+	EventID string
+	// Source is the CloudEvents source of the requester's host system.
+	Source string
+	// TokenInfo is the token info of the access token the request was sent
+	// with. It is passed to the resolver in the context, see
+	// [ileap.TokenInfoFromContext].
+	TokenInfo *ileap.TokenInfo
+	// Footprint is the partial footprint describing the requested footprints.
+	Footprint *ileapv1.ProductFootprint
+	// Comment is the optional comment of the requester.
+	Comment string
+	// ReceivedAt is the time the request was received.
+	ReceivedAt time.Time
+	// Status is the processing status of the request.
+	Status Status
+	// Footprints are the resolved footprints, once the request is resolved.
+	Footprints []*ileapv1.ProductFootprint
+	// Rejection is the rejection reason, once the request is resolved and rejected.
+	Rejection *ileap.Error
+	// Attempts is the number of delivery attempts made.
+	Attempts int
+	// LastError is the error of the last failed delivery attempt.
+	LastError string
+}
+
+// resolved reports whether the outcome of the request has been determined.
+func (r *Request) resolved() bool {
+	return r.Footprints != nil || r.Rejection != nil
+}
+
+// Store persists footprint requests and their processing status.
+type Store interface {
+	// Create stores a new request. It reports false, without modifying the
+	// store, when a request with the same Source and EventID already exists.
+	Create(ctx context.Context, request *Request) (bool, error)
+	// Update replaces a stored request.
+	Update(ctx context.Context, request *Request) error
+	// Get returns the request with the given source and event ID.
+	// It must return connect.CodeNotFound when no such request exists.
+	Get(ctx context.Context, source, eventID string) (*Request, error)
+	// ListPending returns all requests in [StatusPending].
+	ListPending(ctx context.Context) ([]*Request, error)
+}
+
+// MemoryStore is an in-memory [Store].
+type MemoryStore struct {
+	mu       sync.Mutex
+	requests map[memoryStoreKey]Request
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+type memoryStoreKey struct {
+	source  string
+	eventID string
+}
+
+// NewMemoryStore creates a new empty [MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{requests: make(map[memoryStoreKey]Request)}
+}
+
+// Create implements [Store].
+func (s *MemoryStore) Create(_ context.Context, request *Request) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryStoreKey{source: request.Source, eventID: request.EventID}
+	if _, ok := s.requests[key]; ok {
+		return false, nil
+	}
+	s.requests[key] = *request
+	return true, nil
+}
+
+// Update implements [Store].
+func (s *MemoryStore) Update(_ context.Context, request *Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := memoryStoreKey{source: request.Source, eventID: request.EventID}
+	if _, ok := s.requests[key]; !ok {
+		return connect.NewError(connect.CodeNotFound, nil)
+	}
+	s.requests[key] = *request
+	return nil
+}
+
+// Get implements [Store].
+func (s *MemoryStore) Get(_ context.Context, source, eventID string) (*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	request, ok := s.requests[memoryStoreKey{source: source, eventID: eventID}]
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+	return &request, nil
+}
+
+// ListPending implements [Store].
+func (s *MemoryStore) ListPending(_ context.Context) ([]*Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*Request
+	for _, request := range s.requests {
+		if request.Status == StatusPending {
+			result = append(result, &request)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,329 @@
+// Package ileapcache provides a caching ILeapServiceHandler decorator.
+//
+// The [Handler] caches the responses of an expensive service handler, such as
+// an ileapconnect backend, for a TTL and up to a maximum number of entries.
+// Entries are keyed by the caller's identity and the normalized request, so
+// that callers never see each other's responses. Concurrent identical
+// requests are de-duplicated into a single backend call.
+//
+// Published footprints are invalidated by wrapping the server's event handler
+// with [Handler.InvalidateOnPublished]:
+//
+//	cache := ileapcache.NewHandler(backend)
+//	server := ileap.NewServer(
+//		ileap.WithServiceHandler(cache),
+//		ileap.WithEventHandler(cache.InvalidateOnPublished(ileap.NopEventHandler{})),
+//	)
+package ileapcache
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/way-platform/ileap-go"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultTTL        = time.Minute
+	defaultMaxEntries = 1000
+)
+
+// Methods of the cached calls, used as key prefixes.
+const (
+	methodListFootprints = "ListFootprints"
+	methodGetFootprint   = "GetFootprint"
+	methodListTADs       = "ListTransportActivityData"
+)
+
+// IdentityFunc returns the identity of the caller of a request. Requests of
+// callers with the same identity share cache entries.
+type IdentityFunc func(ctx context.Context) string
+
+// Option configures a [Handler].
+type Option func(*Handler)
+
+// WithTTL sets how long responses are cached. Defaults to 1m.
+func WithTTL(ttl time.Duration) Option {
+	return func(h *Handler) { h.ttl = ttl }
+}
+
+// WithMaxEntries sets the maximum number of cached responses. The least
+// recently used responses are evicted first. Defaults to 1000.
+func WithMaxEntries(n int) Option {
+	return func(h *Handler) { h.maxEntries = n }
+}
+
+// WithIdentity sets the function returning the caller identity of a request.
+// Defaults to the organization and subject of the caller's [ileap.TokenInfo].
+// Use a coarser identity, such as the organization alone, only if the backend
+// returns the same data to all callers with that identity.
+func WithIdentity(identity IdentityFunc) Option {
+	return func(h *Handler) { h.identity = identity }
+}
+
+// Handler is a caching [ileapv1connect.ILeapServiceHandler] decorator. Errors
+// are not cached. It is safe for concurrent use.
+type Handler struct {
+	next       ileapv1connect.ILeapServiceHandler
+	ttl        time.Duration
+	maxEntries int
+	identity   IdentityFunc
+	now        func() time.Time
+	group      singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation is incremented on invalidation, so that backend calls
+	// started before an invalidation do not store stale responses.
+	generation uint64
+}
+
+var _ ileapv1connect.ILeapServiceHandler = (*Handler)(nil)
+
+// entry is a cached response.
+type entry struct {
+	key         string
+	method      string
+	footprintID string
+	response    proto.Message
+	expires     time.Time
+}
+
+// NewHandler creates a new caching Handler for the next service handler.
+func NewHandler(next ileapv1connect.ILeapServiceHandler, opts ...Option) *Handler {
+	h := &Handler{
+		next:       next,
+		ttl:        defaultTTL,
+		maxEntries: defaultMaxEntries,
+		identity:   tokenIdentity,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ListFootprints implements [ileapv1connect.ILeapServiceHandler].
+func (h *Handler) ListFootprints(
+	ctx context.Context,
+	req *ileapv1.ListFootprintsRequest,
+) (*ileapv1.ListFootprintsResponse, error) {
+	normalized := proto.CloneOf(req)
+	normalized.SetFilters(normalizeFilters(req.GetFilters()))
+	return cached(ctx, h, methodListFootprints, "", normalized, h.next.ListFootprints)
+}
+
+// GetFootprint implements [ileapv1connect.ILeapServiceHandler].
+func (h *Handler) GetFootprint(
+	ctx context.Context,
+	req *ileapv1.GetFootprintRequest,
+) (*ileapv1.GetFootprintResponse, error) {
+	return cached(ctx, h, methodGetFootprint, req.GetId(), req, h.next.GetFootprint)
+}
+
+// ListTransportActivityData implements [ileapv1connect.ILeapServiceHandler].
+func (h *Handler) ListTransportActivityData(
+	ctx context.Context,
+	req *ileapv1.ListTransportActivityDataRequest,
+) (*ileapv1.ListTransportActivityDataResponse, error) {
+	normalized := proto.CloneOf(req)
+	normalized.SetFilters(normalizeFilters(req.GetFilters()))
+	return cached(ctx, h, methodListTADs, "", normalized, h.next.ListTransportActivityData)
+}
+
+// InvalidateFootprints removes the cached responses that may contain the
+// footprints: all footprint lists, and the footprints with the IDs.
+func (h *Handler) InvalidateFootprints(ids ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.generation++
+	for key, elem := range h.entries {
+		e := elem.Value.(*entry)
+		if e.method == methodListFootprints ||
+			e.method == methodGetFootprint && slices.Contains(ids, e.footprintID) {
+			h.lru.Remove(elem)
+			delete(h.entries, key)
+		}
+	}
+}
+
+// Purge removes all cached responses.
+func (h *Handler) Purge() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.generation++
+	clear(h.entries)
+	h.lru.Init()
+}
+
+// Len returns the number of cached responses, including expired responses not
+// yet removed.
+func (h *Handler) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lru.Len()
+}
+
+// InvalidateOnPublished wraps an event handler to invalidate the published
+// footprints of ProductFootprint.Published.v1 events, before passing the
+// event on to next.
+func (h *Handler) InvalidateOnPublished(next ileap.EventHandler) ileap.EventHandler {
+	return &invalidatingEventHandler{EventHandler: next, cache: h}
+}
+
+type invalidatingEventHandler struct {
+	ileap.EventHandler
+	cache *Handler
+}
+
+// HandlePublished implements [ileap.EventHandler].
+func (h *invalidatingEventHandler) HandlePublished(
+	ctx context.Context,
+	info *ileap.TokenInfo,
+	event *ileap.PublishedEvent,
+) error {
+	h.cache.InvalidateFootprints(event.FootprintIDs...)
+	return h.EventHandler.HandlePublished(ctx, info, event)
+}
+
+// cached returns the cached response of the request, or calls the backend.
+// Concurrent calls with the same key share one backend call, which is not
+// canceled when one of the callers gives up.
+func cached[Req, Resp proto.Message](
+	ctx context.Context,
+	h *Handler,
+	method, footprintID string,
+	req Req,
+	call func(context.Context, Req) (Resp, error),
+) (Resp, error) {
+	var zero Resp
+	key, err := h.key(ctx, method, req)
+	if err != nil {
+		return zero, err
+	}
+	response, generation, ok := h.lookup(key)
+	if ok {
+		return proto.Clone(response).(Resp), nil
+	}
+	results := h.group.DoChan(key, func() (any, error) {
+		resp, err := call(context.WithoutCancel(ctx), req)
+		if err != nil {
+			return nil, err
+		}
+		h.store(key, method, footprintID, resp, generation)
+		return resp, nil
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return proto.Clone(result.Val.(Resp)).(Resp), nil
+	}
+}
+
+// key returns the cache key of a request: the method, the caller identity,
+// and the deterministic encoding of the normalized request.
+func (h *Handler) key(ctx context.Context, method string, req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("cache key: %w", err)
+	}
+	return fmt.Sprintf("%s\x00%q\x00%s", method, h.identity(ctx), data), nil
+}
+
+// lookup returns the cached response with the key, if present and not
+// expired, and the current generation.
+func (h *Handler) lookup(key string) (proto.Message, uint64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	elem, ok := h.entries[key]
+	if !ok {
+		return nil, h.generation, false
+	}
+	e := elem.Value.(*entry)
+	if !h.now().Before(e.expires) {
+		h.lru.Remove(elem)
+		delete(h.entries, key)
+		return nil, h.generation, false
+	}
+	h.lru.MoveToFront(elem)
+	return e.response, h.generation, true
+}
+
+// store caches a response, unless the cache was invalidated since the
+// generation, and evicts the least recently used entries beyond the maximum.
+func (h *Handler) store(
+	key, method, footprintID string,
+	response proto.Message,
+	generation uint64,
+) {
+	if h.ttl <= 0 || h.maxEntries <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if generation != h.generation {
+		return
+	}
+	e := &entry{
+		key:         key,
+		method:      method,
+		footprintID: footprintID,
+		response:    response,
+		expires:     h.now().Add(h.ttl),
+	}
+	if elem, ok := h.entries[key]; ok {
+		elem.Value = e
+		h.lru.MoveToFront(elem)
+	} else {
+		h.entries[key] = h.lru.PushFront(e)
+	}
+	for h.lru.Len() > h.maxEntries {
+		oldest := h.lru.Back()
+		h.lru.Remove(oldest)
+		delete(h.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// tokenIdentity is the default IdentityFunc.
+func tokenIdentity(ctx context.Context) string {
+	info, ok := ileap.TokenInfoFromContext(ctx)
+	if !ok || info == nil {
+		return ""
+	}
+	return info.Organization + "\x00" + info.Subject
+}
+
+// normalizeFilters returns the filters sorted and without duplicates, since
+// their order does not affect the result. Filters of GET /2/ileap/tad are
+// built from the query parameters in random order.
+func normalizeFilters(filters []*ileapv1.Filter) []*ileapv1.Filter {
+	sorted := slices.Clone(filters)
+	compare := func(a, b *ileapv1.Filter) int {
+		return cmp.Or(
+			cmp.Compare(a.GetFieldPath(), b.GetFieldPath()),
+			cmp.Compare(a.GetOperator(), b.GetOperator()),
+			cmp.Compare(a.GetValue(), b.GetValue()),
+		)
+	}
+	slices.SortFunc(sorted, compare)
+	return slices.CompactFunc(sorted, func(a, b *ileapv1.Filter) bool {
+		return compare(a, b) == 0
+	})
+}
@@ -0,0 +1,344 @@
+package ileapcache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"golang.org/x/oauth2"
+)
+
+// countingBackend counts the calls per method. Calls block while release is
+// non-nil and open.
+type countingBackend struct {
+	ileapv1connect.UnimplementedILeapServiceHandler
+
+	mu      sync.Mutex
+	calls   map[string]int
+	err     error
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newCountingBackend() *countingBackend {
+	return &countingBackend{calls: make(map[string]int)}
+}
+
+func (b *countingBackend) call(method string) error {
+	b.mu.Lock()
+	b.calls[method]++
+	entered, release, err := b.entered, b.release, b.err
+	b.mu.Unlock()
+	if entered != nil {
+		entered <- struct{}{}
+	}
+	if release != nil {
+		<-release
+	}
+	return err
+}
+
+func (b *countingBackend) count(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[method]
+}
+
+func (b *countingBackend) ListFootprints(
+	_ context.Context,
+	_ *ileapv1.ListFootprintsRequest,
+) (*ileapv1.ListFootprintsResponse, error) {
+	if err := b.call(methodListFootprints); err != nil {
+		return nil, err
+	}
+	fp := new(ileapv1.ProductFootprint)
+	fp.SetId("fp-1")
+	resp := new(ileapv1.ListFootprintsResponse)
+	resp.SetData([]*ileapv1.ProductFootprint{fp})
+	resp.SetTotal(1)
+	return resp, nil
+}
+
+func (b *countingBackend) GetFootprint(
+	_ context.Context,
+	req *ileapv1.GetFootprintRequest,
+) (*ileapv1.GetFootprintResponse, error) {
+	if err := b.call(methodGetFootprint); err != nil {
+		return nil, err
+	}
+	fp := new(ileapv1.ProductFootprint)
+	fp.SetId(req.GetId())
+	resp := new(ileapv1.GetFootprintResponse)
+	resp.SetData(fp)
+	return resp, nil
+}
+
+func (b *countingBackend) ListTransportActivityData(
+	_ context.Context,
+	_ *ileapv1.ListTransportActivityDataRequest,
+) (*ileapv1.ListTransportActivityDataResponse, error) {
+	if err := b.call(methodListTADs); err != nil {
+		return nil, err
+	}
+	return new(ileapv1.ListTransportActivityDataResponse), nil
+}
+
+func newFilter(fieldPath, value string) *ileapv1.Filter {
+	f := new(ileapv1.Filter)
+	f.SetFieldPath(fieldPath)
+	f.SetOperator(ileapv1.Filter_EQ)
+	f.SetValue(value)
+	return f
+}
+
+func listFootprints(
+	t *testing.T,
+	ctx context.Context,
+	h *Handler,
+	filters ...*ileapv1.Filter,
+) {
+	t.Helper()
+	req := new(ileapv1.ListFootprintsRequest)
+	req.SetFilters(filters)
+	if _, err := h.ListFootprints(ctx, req); err != nil {
+		t.Fatalf("ListFootprints() error: %v", err)
+	}
+}
+
+func getFootprint(t *testing.T, h *Handler, id string) {
+	t.Helper()
+	req := new(ileapv1.GetFootprintRequest)
+	req.SetId(id)
+	if _, err := h.GetFootprint(t.Context(), req); err != nil {
+		t.Fatalf("GetFootprint() error: %v", err)
+	}
+}
+
+func callerContext(ctx context.Context, subject string) context.Context {
+	return ileap.WithTokenInfo(ctx, &ileap.TokenInfo{Subject: subject})
+}
+
+func checkCalls(t *testing.T, b *countingBackend, method string, want int) {
+	t.Helper()
+	if got := b.count(method); got != want {
+		t.Errorf("%s backend calls = %d, want %d", method, got, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("caches responses", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend)
+		ctx := callerContext(t.Context(), "alice")
+		resp, err := h.ListFootprints(ctx, new(ileapv1.ListFootprintsRequest))
+		if err != nil {
+			t.Fatalf("ListFootprints() error: %v", err)
+		}
+		// Responses are copies, so callers cannot modify the cached response.
+		resp.GetData()[0].SetId("modified")
+		resp, err = h.ListFootprints(ctx, new(ileapv1.ListFootprintsRequest))
+		if err != nil {
+			t.Fatalf("ListFootprints() error: %v", err)
+		}
+		if got := resp.GetData()[0].GetId(); got != "fp-1" {
+			t.Errorf("cached footprint id = %q, want %q", got, "fp-1")
+		}
+		checkCalls(t, backend, methodListFootprints, 1)
+	})
+
+	t.Run("keyed by caller identity", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend)
+		listFootprints(t, callerContext(t.Context(), "alice"), h)
+		listFootprints(t, callerContext(t.Context(), "bob"), h)
+		listFootprints(t, callerContext(t.Context(), "alice"), h)
+		checkCalls(t, backend, methodListFootprints, 2)
+	})
+
+	t.Run("keyed by request", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend)
+		ctx := callerContext(t.Context(), "alice")
+		listFootprints(t, ctx, h, newFilter("companyName", "Acme"))
+		listFootprints(t, ctx, h, newFilter("companyName", "Other"))
+		req := new(ileapv1.ListFootprintsRequest)
+		req.SetLimit(10)
+		if _, err := h.ListFootprints(ctx, req); err != nil {
+			t.Fatalf("ListFootprints() error: %v", err)
+		}
+		checkCalls(t, backend, methodListFootprints, 3)
+	})
+
+	t.Run("normalizes filters", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend)
+		ctx := callerContext(t.Context(), "alice")
+		a, b := newFilter("mode", "Road"), newFilter("feedstock", "Diesel")
+		for _, filters := range [][]*ileapv1.Filter{{a, b}, {b, a}, {b, a, b}} {
+			req := new(ileapv1.ListTransportActivityDataRequest)
+			req.SetFilters(filters)
+			if _, err := h.ListTransportActivityData(ctx, req); err != nil {
+				t.Fatalf("ListTransportActivityData() error: %v", err)
+			}
+		}
+		checkCalls(t, backend, methodListTADs, 1)
+	})
+
+	t.Run("expires after TTL", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend, WithTTL(time.Minute))
+		now := time.Now()
+		h.now = func() time.Time { return now }
+		getFootprint(t, h, "fp-1")
+		now = now.Add(59 * time.Second)
+		getFootprint(t, h, "fp-1")
+		checkCalls(t, backend, methodGetFootprint, 1)
+		now = now.Add(time.Second)
+		getFootprint(t, h, "fp-1")
+		checkCalls(t, backend, methodGetFootprint, 2)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		backend := newCountingBackend()
+		h := NewHandler(backend, WithMaxEntries(2))
+		getFootprint(t, h, "fp-1")
+		getFootprint(t, h, "fp-2")
+		getFootprint(t, h, "fp-1")
+		getFootprint(t, h, "fp-3")
+		if h.Len() != 2 {
+			t.Errorf("Len() = %d, want 2", h.Len())
+		}
+		getFootprint(t, h, "fp-1")
+		checkCalls(t, backend, methodGetFootprint, 3)
+		getFootprint(t, h, "fp-2")
+		checkCalls(t, backend, methodGetFootprint, 4)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		backend := newCountingBackend()
+		backend.err = connect.NewError(connect.CodeUnavailable, errors.New("backend down"))
+		h := NewHandler(backend)
+		for range 2 {
+			_, err := h.ListFootprints(t.Context(), new(ileapv1.ListFootprintsRequest))
+			if connect.CodeOf(err) != connect.CodeUnavailable {
+				t.Fatalf("ListFootprints() error = %v, want CodeUnavailable", err)
+			}
+		}
+		checkCalls(t, backend, methodListFootprints, 2)
+	})
+
+	t.Run("de-duplicates concurrent requests", func(t *testing.T) {
+		backend := newCountingBackend()
+		backend.entered = make(chan struct{}, 1)
+		backend.release = make(chan struct{})
+		h := NewHandler(backend)
+		ctx := callerContext(t.Context(), "alice")
+		var wg sync.WaitGroup
+		wg.Go(func() { listFootprints(t, ctx, h) })
+		<-backend.entered
+		for range 4 {
+			wg.Go(func() { listFootprints(t, ctx, h) })
+		}
+		// Give the callers time to join the pending call.
+		time.Sleep(10 * time.Millisecond)
+		close(backend.release)
+		wg.Wait()
+		checkCalls(t, backend, methodListFootprints, 1)
+	})
+
+	t.Run("canceled caller", func(t *testing.T) {
+		backend := newCountingBackend()
+		backend.entered = make(chan struct{}, 1)
+		backend.release = make(chan struct{})
+		h := NewHandler(backend)
+		ctx, cancel := context.WithCancel(t.Context())
+		errs := make(chan error, 1)
+		go func() {
+			_, err := h.ListFootprints(ctx, new(ileapv1.ListFootprintsRequest))
+			errs <- err
+		}()
+		<-backend.entered
+		cancel()
+		if err := <-errs; !errors.Is(err, context.Canceled) {
+			t.Errorf("ListFootprints() error = %v, want context.Canceled", err)
+		}
+		close(backend.release)
+		// The backend call completes and its response is cached.
+		for h.Len() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		listFootprints(t, t.Context(), h)
+		checkCalls(t, backend, methodListFootprints, 1)
+	})
+}
+
+func TestInvalidateOnPublished(t *testing.T) {
+	backend := newCountingBackend()
+	h := NewHandler(backend)
+	events := h.InvalidateOnPublished(ileap.NopEventHandler{})
+	ctx := t.Context()
+	fill := func() {
+		listFootprints(t, ctx, h)
+		getFootprint(t, h, "fp-1")
+		getFootprint(t, h, "fp-2")
+		if _, err := h.ListTransportActivityData(
+			ctx,
+			new(ileapv1.ListTransportActivityDataRequest),
+		); err != nil {
+			t.Fatalf("ListTransportActivityData() error: %v", err)
+		}
+	}
+	fill()
+	err := events.HandlePublished(ctx, nil, &ileap.PublishedEvent{FootprintIDs: []string{"fp-1"}})
+	if err != nil {
+		t.Fatalf("HandlePublished() error: %v", err)
+	}
+	fill()
+	checkCalls(t, backend, methodListFootprints, 2)
+	checkCalls(t, backend, methodGetFootprint, 3)
+	checkCalls(t, backend, methodListTADs, 1)
+	h.Purge()
+	fill()
+	checkCalls(t, backend, methodListTADs, 2)
+}
+
+func TestServer(t *testing.T) {
+	backend := newCountingBackend()
+	srv := ileap.NewServer(
+		ileap.WithAuthHandler(staticAuthHandler{}),
+		ileap.WithServiceHandler(NewHandler(backend)),
+	)
+	for range 2 {
+		req := httptest.NewRequest("GET", "/2/footprints", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	checkCalls(t, backend, methodListFootprints, 1)
+}
+
+// staticAuthHandler accepts all tokens.
+type staticAuthHandler struct{}
+
+func (staticAuthHandler) IssueToken(context.Context, string, string) (*oauth2.Token, error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, nil)
+}
+
+func (staticAuthHandler) ValidateToken(context.Context, string) (*ileap.TokenInfo, error) {
+	return &ileap.TokenInfo{Subject: "alice"}, nil
+}
+
+func (staticAuthHandler) OpenIDConfiguration(string) *ileap.OpenIDConfiguration { return nil }
+
+func (staticAuthHandler) JWKS() *ileap.JWKSet { return nil }
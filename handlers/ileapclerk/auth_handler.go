@@ -235,7 +235,44 @@ func (a *AuthHandler) ValidateToken(
 	if !ok || sub == "" {
 		return nil, fmt.Errorf("missing or invalid sub claim")
 	}
-	return &ileap.TokenInfo{Subject: sub}, nil
+	return &ileap.TokenInfo{
+		Subject:      sub,
+		Audience:     audience(claims),
+		Organization: organizationID(claims),
+		Claims:       claims,
+	}, nil
+}
+
+// organizationID returns the active organization of a Clerk session token,
+// from the org_id claim of version 1 tokens or the o.id claim of version 2.
+func organizationID(claims map[string]any) string {
+	if id, ok := claims["org_id"].(string); ok {
+		return id
+	}
+	if o, ok := claims["o"].(map[string]any); ok {
+		id, _ := o["id"].(string)
+		return id
+	}
+	return ""
+}
+
+// audience returns the aud claim, which is either a string or an array of
+// strings.
+func audience(claims map[string]any) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, aud := range v {
+			if s, ok := aud.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
 }
 
 func extractUnixClaim(claims map[string]any, name string) (int64, bool, error) {
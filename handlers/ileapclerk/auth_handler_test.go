@@ -421,6 +421,29 @@ func TestAuthHandler_ValidateToken(t *testing.T) {
 		}
 	})
 
+	t.Run("organization", func(t *testing.T) {
+		srv := jwksServerForKey(t, key)
+		defer srv.Close()
+		c := NewClient("unused", WithHTTPClient(&http.Client{
+			Transport: &testTransport{target: srv},
+		}))
+		auth := NewAuthHandler(c)
+		for _, claims := range []map[string]any{
+			{"org_id": "org_123"},
+			{"o": map[string]any{"id": "org_123"}},
+		} {
+			claims["sub"] = "user@example.com"
+			claims["exp"] = float64(time.Now().Add(time.Hour).Unix())
+			info, err := auth.ValidateToken(context.Background(), makeTestJWT(t, key, claims))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if info.Organization != "org_123" {
+				t.Errorf("expected organization org_123, got %q", info.Organization)
+			}
+		}
+	})
+
 	t.Run("expired token", func(t *testing.T) {
 		srv := jwksServerForKey(t, key)
 		defer srv.Close()
@@ -7,11 +7,15 @@
 package ileapconnect
 
 import (
+	"log/slog"
 	"net/http"
 
 	"connectrpc.com/connect"
+	"connectrpc.com/otelconnect"
 	ileap "github.com/way-platform/ileap-go"
 	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Option configures the client created by NewClient.
@@ -20,6 +24,7 @@ type Option func(*options)
 type options struct {
 	httpClient connect.HTTPClient
 	clientOpts []connect.ClientOption
+	telemetry  []otelconnect.Option
 }
 
 // WithHTTPClient sets the HTTP client used for Connect RPC calls.
@@ -33,9 +38,27 @@ func WithClientOptions(opts ...connect.ClientOption) Option {
 	return func(o *options) { o.clientOpts = append(o.clientOpts, opts...) }
 }
 
+// WithTelemetry traces the Connect calls and records RPC metrics with
+// OpenTelemetry. The trace context of the incoming request is propagated to the
+// backend with the global propagator, so that the backend's spans join the
+// trace of the iLEAP request. Nil providers default to the global providers.
+func WithTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.telemetry = []otelconnect.Option{}
+		if tp != nil {
+			o.telemetry = append(o.telemetry, otelconnect.WithTracerProvider(tp))
+		}
+		if mp != nil {
+			o.telemetry = append(o.telemetry, otelconnect.WithMeterProvider(mp))
+		}
+	}
+}
+
 // NewClient creates an ILeapServiceClient that forwards to the Connect backend
 // at backendURL. The incoming Authorization header is automatically forwarded
-// on all outgoing Connect calls via ileap.AuthForwardInterceptor.
+// on all outgoing Connect calls via ileap.AuthForwardInterceptor, and the
+// ETag and Last-Modified headers of the backend's responses are used as the
+// validators of the server's responses via ileap.ValidatorForwardInterceptor.
 //
 // The returned client satisfies ileapv1connect.ILeapServiceHandler and can be
 // passed directly to ileap.WithServiceHandler.
@@ -46,8 +69,27 @@ func NewClient(backendURL string, opts ...Option) ileapv1connect.ILeapServiceCli
 	for _, opt := range opts {
 		opt(&o)
 	}
+	interceptors := []connect.Interceptor{
+		ileap.AuthForwardInterceptor(),
+		ileap.ValidatorForwardInterceptor(),
+	}
+	if o.telemetry != nil {
+		interceptors = append(interceptors, newTelemetryInterceptor(o.telemetry))
+	}
 	clientOpts := append([]connect.ClientOption{
-		connect.WithInterceptors(ileap.AuthForwardInterceptor()),
+		connect.WithInterceptors(interceptors...),
 	}, o.clientOpts...)
 	return ileapv1connect.NewILeapServiceClient(o.httpClient, backendURL, clientOpts...)
 }
+
+// newTelemetryInterceptor creates the OpenTelemetry interceptor. Creating the
+// metric instruments only fails with a broken meter provider, in which case
+// the calls are traced without metrics.
+func newTelemetryInterceptor(opts []otelconnect.Option) connect.Interceptor {
+	interceptor, err := otelconnect.NewInterceptor(opts...)
+	if err != nil {
+		slog.Warn("failed to create Connect metrics, recording traces only", "error", err)
+		interceptor, _ = otelconnect.NewInterceptor(append(opts, otelconnect.WithoutMetrics())...)
+	}
+	return interceptor
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 
@@ -12,6 +13,11 @@ import (
 	ileap "github.com/way-platform/ileap-go"
 	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/testing/protocmp"
 )
 
@@ -82,11 +88,14 @@ type headerCapture struct {
 	mu      sync.Mutex
 	handler http.Handler
 	last    string
+
+	lastTraceparent string
 }
 
 func (h *headerCapture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.mu.Lock()
 	h.last = r.Header.Get("Authorization")
+	h.lastTraceparent = r.Header.Get("Traceparent")
 	h.mu.Unlock()
 	h.handler.ServeHTTP(w, r)
 }
@@ -97,7 +106,10 @@ func (h *headerCapture) lastAuthHeader() string {
 	return h.last
 }
 
-func newTestFixtures(t *testing.T) (ileapv1connect.ILeapServiceClient, *headerCapture) {
+func newTestFixtures(
+	t *testing.T,
+	opts ...Option,
+) (ileapv1connect.ILeapServiceClient, *headerCapture) {
 	t.Helper()
 	fp1 := new(ileapv1.ProductFootprint)
 	fp1.SetId("fp-1")
@@ -119,7 +131,7 @@ func newTestFixtures(t *testing.T) (ileapv1connect.ILeapServiceClient, *headerCa
 	capture := &headerCapture{handler: mux}
 	server := httptest.NewServer(capture)
 	t.Cleanup(server.Close)
-	client := NewClient(server.URL)
+	client := NewClient(server.URL, opts...)
 	return client, capture
 }
 
@@ -253,6 +265,40 @@ func TestAuthForwarding(t *testing.T) {
 	}
 }
 
+func TestTelemetry(t *testing.T) {
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	client, capture := newTestFixtures(t, WithTelemetry(tp, nil))
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.ListFootprints(ctx, new(ileapv1.ListFootprintsRequest))
+	parent.End()
+	if err != nil {
+		t.Fatalf("ListFootprints() error: %v", err)
+	}
+	capture.mu.Lock()
+	traceparent := capture.lastTraceparent
+	capture.mu.Unlock()
+	traceID := parent.SpanContext().TraceID().String()
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, traceID)
+	}
+	var rpcSpan sdktrace.ReadOnlySpan
+	for _, span := range spans.Ended() {
+		if span.SpanKind() == trace.SpanKindClient {
+			rpcSpan = span
+		}
+	}
+	if rpcSpan == nil {
+		t.Fatal("expected a client span for the Connect call")
+	}
+	if rpcSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("expected the Connect call span to be a child of the parent span")
+	}
+}
+
 func TestGetFootprintResponse(t *testing.T) {
 	client, _ := newTestFixtures(t)
 	req := new(ileapv1.GetFootprintRequest)
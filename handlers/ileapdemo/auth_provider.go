@@ -2,6 +2,7 @@ package ileapdemo
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"time"
 
@@ -10,43 +11,40 @@ import (
 	"golang.org/x/oauth2"
 )
 
-// AuthProvider implements ileap.AuthHandler using demo credentials and a local RSA keypair.
+// AuthProvider implements ileap.AuthHandler using demo credentials and local RSA keys.
 type AuthProvider struct {
-	keypair *KeyPair
+	keys *KeyManager
 }
 
 const accessTokenTTL = time.Hour
 
-// NewAuthProvider creates a new AuthProvider with the embedded demo keypair.
-func NewAuthProvider() (*AuthProvider, error) {
+// NewAuthProvider creates a new AuthProvider with the embedded demo keypair as
+// its initial signing key. The options configure its KeyManager.
+func NewAuthProvider(opts ...KeyManagerOption) (*AuthProvider, error) {
 	kp, err := LoadKeyPair()
 	if err != nil {
 		return nil, err
 	}
-	return &AuthProvider{keypair: kp}, nil
+	return &AuthProvider{keys: NewKeyManager(kp, opts...)}, nil
+}
+
+// Keys returns the KeyManager holding the signing keys, e.g. to rotate them.
+func (a *AuthProvider) Keys() *KeyManager {
+	return a.keys
 }
 
 // IssueToken validates demo credentials and returns a signed JWT.
 func (a *AuthProvider) IssueToken(
 	_ context.Context, clientID, clientSecret string,
 ) (*oauth2.Token, error) {
-	var authorized bool
-	for _, user := range Users() {
-		if clientID == user.Username && clientSecret == user.Password {
-			authorized = true
-			break
-		}
-	}
-	if !authorized {
-		return nil, connect.NewError(
-			connect.CodePermissionDenied,
-			errors.New("invalid credentials"),
-		)
+	if !authenticateUser(clientID, clientSecret) {
+		return nil, errInvalidCredentials()
 	}
 	issuedAt := time.Now()
 	expiresAt := issuedAt.Add(accessTokenTTL)
-	accessToken, err := a.keypair.CreateJWT(JWTClaims{
+	accessToken, err := a.keys.CreateJWT(JWTClaims{
 		Username:   clientID,
+		JWTID:      rand.Text(),
 		IssuedAt:   issuedAt.Unix(),
 		Expiration: expiresAt.Unix(),
 	})
@@ -66,13 +64,46 @@ func (a *AuthProvider) ValidateToken(
 	_ context.Context,
 	token string,
 ) (*ileap.TokenInfo, error) {
-	claims, err := a.keypair.ValidateJWT(token)
+	claims, err := a.keys.ValidateJWT(token)
 	if err != nil {
 		return nil, err
 	}
 	return &ileap.TokenInfo{Subject: claims.Username}, nil
 }
 
+// IntrospectToken authenticates a demo user and introspects the token.
+func (a *AuthProvider) IntrospectToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (*ileap.TokenIntrospection, error) {
+	if !authenticateUser(clientID, clientSecret) {
+		return nil, errInvalidCredentials()
+	}
+	claims, err := a.keys.ValidateJWT(token)
+	if err != nil {
+		return &ileap.TokenIntrospection{Active: false}, nil
+	}
+	return introspection(claims), nil
+}
+
+// RevokeToken authenticates a demo user and checks that the token was issued
+// to it. It returns the expiration time of the token.
+func (a *AuthProvider) RevokeToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (time.Time, error) {
+	if !authenticateUser(clientID, clientSecret) {
+		return time.Time{}, errInvalidCredentials()
+	}
+	claims, err := a.keys.ValidateJWT(token)
+	if err != nil {
+		// Invalid tokens need no revocation (RFC 7009, section 2.2).
+		return time.Time{}, nil
+	}
+	if err := checkTokenClient(claims, clientID); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(claims.Expiration, 0), nil
+}
+
 // OpenIDConfiguration returns the OIDC configuration for the given base URL.
 func (a *AuthProvider) OpenIDConfiguration(baseURL string) *ileap.OpenIDConfiguration {
 	return &ileap.OpenIDConfiguration{
@@ -86,17 +117,48 @@ func (a *AuthProvider) OpenIDConfiguration(baseURL string) *ileap.OpenIDConfigur
 	}
 }
 
-// JWKS returns the JSON Web Key Set containing the public key.
+// JWKS returns the JSON Web Key Set containing the active and previous public keys.
 func (a *AuthProvider) JWKS() *ileap.JWKSet {
-	jwk := a.keypair.JWK()
-	return &ileap.JWKSet{
-		Keys: []ileap.JWK{{
-			KeyType:   jwk.KeyType,
-			Use:       jwk.Use,
-			Algorithm: jwk.Algorithm,
-			KeyID:     jwk.KeyID,
-			N:         jwk.N,
-			E:         jwk.E,
-		}},
+	return a.keys.JWKS()
+}
+
+// authenticateUser reports whether the credentials are those of a demo user.
+func authenticateUser(clientID, clientSecret string) bool {
+	for _, user := range Users() {
+		if clientID == user.Username && clientSecret == user.Password {
+			return true
+		}
+	}
+	return false
+}
+
+func errInvalidCredentials() error {
+	return connect.NewError(connect.CodePermissionDenied, errors.New("invalid credentials"))
+}
+
+// checkTokenClient checks that a token was issued to the client.
+func checkTokenClient(claims *JWTClaims, clientID string) error {
+	if claims.Username != clientID {
+		return connect.NewError(
+			connect.CodeInvalidArgument,
+			errors.New("token was issued to another client"),
+		)
+	}
+	return nil
+}
+
+// introspection returns the introspection response for a valid token.
+func introspection(claims *JWTClaims) *ileap.TokenIntrospection {
+	return &ileap.TokenIntrospection{
+		Active:       true,
+		Scope:        claims.Scope,
+		ClientID:     claims.Username,
+		Username:     claims.Username,
+		TokenType:    "Bearer",
+		ExpiresAt:    claims.Expiration,
+		IssuedAt:     claims.IssuedAt,
+		Subject:      claims.Username,
+		JWTID:        claims.JWTID,
+		Confirmation: claims.Confirmation,
 	}
 }
@@ -0,0 +1,305 @@
+package ileapdemo
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/way-platform/ileap-go"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ClientsFile is the format of a client registry file.
+type ClientsFile struct {
+	// Clients are the registered clients.
+	Clients []ClientConfig `json:"clients" yaml:"clients"`
+}
+
+// ClientConfig is the configuration of a registered client.
+type ClientConfig struct {
+	// ID is the client ID.
+	ID string `json:"id" yaml:"id"`
+	// SecretHash is the bcrypt or argon2id (PHC string format) hash of the
+	// client secret, for client_secret_basic authentication.
+	SecretHash string `json:"secretHash,omitempty" yaml:"secretHash,omitempty"`
+	// PublicKeys are the PEM-encoded public keys that verify the client's JWT
+	// assertions, for private_key_jwt authentication.
+	PublicKeys []string `json:"publicKeys,omitempty" yaml:"publicKeys,omitempty"`
+	// CertificateThumbprints are the SHA-256 thumbprints of the client's TLS
+	// certificates, see ileap.CertificateThumbprint, for
+	// self_signed_tls_client_auth authentication.
+	CertificateThumbprints []string `json:"certificateThumbprints,omitempty" yaml:"certificateThumbprints,omitempty"`
+	// CertificateSubject is the subject distinguished name of the client's
+	// CA-issued TLS certificate, in RFC 2253 format, for tls_client_auth
+	// authentication.
+	CertificateSubject string `json:"certificateSubject,omitempty" yaml:"certificateSubject,omitempty"`
+	// Scopes are the scopes granted to tokens issued to the client.
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// Organization is the organization of the client.
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
+	// TokenTTL is the lifetime of tokens issued to the client, as a Go
+	// duration string. Defaults to 1h.
+	TokenTTL string `json:"tokenTTL,omitempty" yaml:"tokenTTL,omitempty"`
+	// Enabled controls whether the client can authenticate. Defaults to true.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// registeredClient is a validated ClientConfig.
+type registeredClient struct {
+	id                     string
+	secretHash             string
+	publicKeys             []crypto.PublicKey
+	certificateThumbprints []string
+	certificateSubject     string
+	scopes                 []string
+	organization           string
+	tokenTTL               time.Duration
+	enabled                bool
+}
+
+// HashSecret hashes a client secret with bcrypt, for use as a SecretHash.
+func HashSecret(secret string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// loadClients reads and validates a client registry file. Files with a .json
+// extension are parsed as JSON, all other files as YAML.
+func loadClients(path string) (map[string]*registeredClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load clients: %w", err)
+	}
+	var file ClientsFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&file)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(&file); errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse clients %s: %w", path, err)
+	}
+	clients := make(map[string]*registeredClient, len(file.Clients))
+	for i, cfg := range file.Clients {
+		client, err := newRegisteredClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("parse clients %s: client %d: %w", path, i, err)
+		}
+		if _, ok := clients[client.id]; ok {
+			return nil, fmt.Errorf("parse clients %s: duplicate client %q", path, client.id)
+		}
+		clients[client.id] = client
+	}
+	return clients, nil
+}
+
+func newRegisteredClient(cfg ClientConfig) (*registeredClient, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("missing id")
+	}
+	// Clients without a secret must authenticate with one of the other methods.
+	if cfg.SecretHash != "" || (len(cfg.PublicKeys) == 0 &&
+		len(cfg.CertificateThumbprints) == 0 && cfg.CertificateSubject == "") {
+		if _, err := hashAlgorithm(cfg.SecretHash); err != nil {
+			return nil, fmt.Errorf("%s: %w", cfg.ID, err)
+		}
+	}
+	client := &registeredClient{
+		id:                     cfg.ID,
+		secretHash:             cfg.SecretHash,
+		certificateThumbprints: cfg.CertificateThumbprints,
+		certificateSubject:     cfg.CertificateSubject,
+		scopes:                 cfg.Scopes,
+		organization:           cfg.Organization,
+		tokenTTL:               accessTokenTTL,
+		enabled:                cfg.Enabled == nil || *cfg.Enabled,
+	}
+	for i, pemKey := range cfg.PublicKeys {
+		key, err := parsePublicKey(pemKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: publicKeys[%d]: %w", cfg.ID, i, err)
+		}
+		client.publicKeys = append(client.publicKeys, key)
+	}
+	if cfg.TokenTTL != "" {
+		ttl, err := time.ParseDuration(cfg.TokenTTL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid tokenTTL: %w", cfg.ID, err)
+		}
+		if ttl <= 0 {
+			return nil, fmt.Errorf("%s: tokenTTL must be positive", cfg.ID)
+		}
+		client.tokenTTL = ttl
+	}
+	return client, nil
+}
+
+// certificateMatches reports whether a TLS client certificate authenticates
+// the client. Registered thumbprints match any certificate, and the registered
+// subject only matches certificates verified against a trusted CA.
+func (c *registeredClient) certificateMatches(
+	cert *x509.Certificate,
+	method ileap.ClientAuthMethod,
+) bool {
+	if cert == nil {
+		return false
+	}
+	if slices.Contains(c.certificateThumbprints, ileap.CertificateThumbprint(cert)) {
+		return true
+	}
+	return method == ileap.ClientAuthMethodTLS &&
+		c.certificateSubject != "" &&
+		cert.Subject.String() == c.certificateSubject
+}
+
+// parsePublicKey parses a PEM-encoded PKIX RSA, ECDSA, or Ed25519 public key.
+func parsePublicKey(pemKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("not a PEM public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// hashAlgorithm returns the algorithm of a secret hash.
+func hashAlgorithm(hash string) (string, error) {
+	switch {
+	case strings.HasPrefix(hash, "$2a$"),
+		strings.HasPrefix(hash, "$2b$"),
+		strings.HasPrefix(hash, "$2y$"):
+		if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+			return "", fmt.Errorf("invalid bcrypt secretHash: %w", err)
+		}
+		return "bcrypt", nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		if _, err := parseArgon2id(hash); err != nil {
+			return "", err
+		}
+		return "argon2id", nil
+	case hash == "":
+		return "", errors.New("missing secretHash")
+	default:
+		return "", errors.New("unsupported secretHash, want bcrypt or argon2id")
+	}
+}
+
+// verifySecret reports whether a secret matches a secret hash.
+func verifySecret(hash, secret string) bool {
+	alg, err := hashAlgorithm(hash)
+	if err != nil {
+		return false
+	}
+	switch alg {
+	case "bcrypt":
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) == nil
+	case "argon2id":
+		params, err := parseArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		key := argon2.IDKey(
+			[]byte(secret),
+			params.salt,
+			params.time,
+			params.memory,
+			params.threads,
+			uint32(len(params.key)),
+		)
+		return subtle.ConstantTimeCompare(key, params.key) == 1
+	default:
+		return false
+	}
+}
+
+// dummySecretHash returns the hash of a random secret, which is verified
+// against for unknown and disabled clients to not reveal registered client
+// IDs through response times.
+var dummySecretHash = sync.OnceValue(func() string {
+	hash, err := HashSecret(rand.Text())
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// argon2idHash is a parsed argon2id hash.
+type argon2idHash struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// parseArgon2id parses an argon2id hash in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>.
+func parseArgon2id(hash string) (*argon2idHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errors.New("invalid argon2id secretHash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("invalid argon2id secretHash version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version: %d", version)
+	}
+	var result argon2idHash
+	if _, err := fmt.Sscanf(
+		parts[3], "m=%d,t=%d,p=%d", &result.memory, &result.time, &result.threads,
+	); err != nil {
+		return nil, fmt.Errorf("invalid argon2id secretHash parameters: %w", err)
+	}
+	if result.time == 0 || result.threads == 0 {
+		return nil, errors.New("invalid argon2id secretHash parameters")
+	}
+	var err error
+	if result.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id secretHash salt: %w", err)
+	}
+	if result.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id secretHash key: %w", err)
+	}
+	if len(result.key) == 0 {
+		return nil, errors.New("invalid argon2id secretHash key")
+	}
+	return &result, nil
+}
@@ -0,0 +1,434 @@
+package ileapdemo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/internal/jose"
+	"golang.org/x/oauth2"
+)
+
+// FileAuthProvider implements ileap.AuthHandler using clients loaded from a
+// YAML or JSON client registry file. The registry is reloaded when the file
+// changes.
+type FileAuthProvider struct {
+	*AuthProvider
+	path           string
+	signingKeyFile string
+	reloadInterval time.Duration
+	keyOpts        []KeyManagerOption
+
+	mu        sync.Mutex
+	clients   map[string]*registeredClient
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+
+	// assertions holds the expiration of the client assertions used, by client
+	// ID and JWT ID, to reject replayed assertions.
+	assertionsMu sync.Mutex
+	assertions   map[string]time.Time
+}
+
+var _ ileap.TokenRequestHandler = (*FileAuthProvider)(nil)
+
+// assertionClockSkew is the tolerance for the exp and nbf claims of client
+// assertions.
+const assertionClockSkew = time.Minute
+
+// FileAuthProviderOption configures the FileAuthProvider.
+type FileAuthProviderOption func(*FileAuthProvider)
+
+// WithSigningKeyFile sets the path of the PEM-encoded PKCS #8 RSA signing key.
+// If the file does not exist, a new key is generated and written to it. Without
+// a signing key file, a new key is generated on every start.
+func WithSigningKeyFile(path string) FileAuthProviderOption {
+	return func(p *FileAuthProvider) { p.signingKeyFile = path }
+}
+
+// WithReloadInterval sets how often the client registry file is checked for
+// changes. Defaults to 5 seconds.
+func WithReloadInterval(d time.Duration) FileAuthProviderOption {
+	return func(p *FileAuthProvider) { p.reloadInterval = d }
+}
+
+// WithKeyManagerOptions configures the KeyManager holding the signing keys.
+// The key loaded from the signing key file is the initial signing key; keys
+// rotated to are held in memory only.
+func WithKeyManagerOptions(opts ...KeyManagerOption) FileAuthProviderOption {
+	return func(p *FileAuthProvider) { p.keyOpts = append(p.keyOpts, opts...) }
+}
+
+// NewFileAuthProvider creates a new FileAuthProvider for the client registry
+// file at path.
+func NewFileAuthProvider(
+	path string,
+	opts ...FileAuthProviderOption,
+) (*FileAuthProvider, error) {
+	p := &FileAuthProvider{
+		path:           path,
+		reloadInterval: 5 * time.Second,
+		assertions:     make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	var kp *KeyPair
+	var err error
+	if p.signingKeyFile != "" {
+		kp, err = LoadOrGenerateKeyPair(p.signingKeyFile)
+	} else {
+		slog.Warn("no signing key file, issued tokens will not survive a restart")
+		kp, err = GenerateKeyPair()
+	}
+	if err != nil {
+		return nil, err
+	}
+	keyOpts := append([]KeyManagerOption{WithMaxTokenTTL(p.maxTokenTTL())}, p.keyOpts...)
+	p.AuthProvider = &AuthProvider{keys: NewKeyManager(kp, keyOpts...)}
+	return p, nil
+}
+
+// Reload reloads the client registry file. On error, the previously loaded
+// clients are kept.
+func (p *FileAuthProvider) Reload() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reloadLocked()
+}
+
+func (p *FileAuthProvider) reloadLocked() error {
+	p.lastCheck = time.Now()
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return fmt.Errorf("load clients: %w", err)
+	}
+	clients, err := loadClients(p.path)
+	if err != nil {
+		return err
+	}
+	p.clients = clients
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	slog.Debug("loaded clients", "path", p.path, "count", len(clients))
+	return nil
+}
+
+// maxTokenTTL returns the longest lifetime of the tokens issued to the
+// registered clients.
+func (p *FileAuthProvider) maxTokenTTL() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ttl := accessTokenTTL
+	for _, client := range p.clients {
+		ttl = max(ttl, client.tokenTTL)
+	}
+	return ttl
+}
+
+// client returns the registered client with the ID, reloading the client
+// registry file first if it has changed.
+func (p *FileAuthProvider) client(clientID string) (*registeredClient, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Since(p.lastCheck) >= p.reloadInterval {
+		p.lastCheck = time.Now()
+		info, err := os.Stat(p.path)
+		switch {
+		case err != nil:
+			slog.Warn("failed to check clients file, keeping loaded clients", "error", err)
+		case !info.ModTime().Equal(p.modTime) || info.Size() != p.size:
+			if err := p.reloadLocked(); err != nil {
+				slog.Warn("failed to reload clients, keeping loaded clients", "error", err)
+			}
+		}
+	}
+	client, ok := p.clients[clientID]
+	return client, ok
+}
+
+// IssueToken validates client credentials and returns a signed JWT.
+func (p *FileAuthProvider) IssueToken(
+	_ context.Context, clientID, clientSecret string,
+) (*oauth2.Token, error) {
+	client, ok := p.authenticate(clientID, clientSecret)
+	if !ok {
+		return nil, errInvalidCredentials()
+	}
+	return p.issueToken(client, nil, nil)
+}
+
+// HandleTokenRequest authenticates a registered client with a client secret,
+// a JWT assertion signed with one of its public keys, or its TLS certificate,
+// and returns a signed JWT. Tokens issued to clients that presented a TLS
+// certificate are bound to the certificate.
+//
+// Tokens are granted the requested scopes the client is registered with, or
+// all of its scopes if the request has none. Requests for none of the
+// client's scopes are rejected as connect.CodeInvalidArgument.
+func (p *FileAuthProvider) HandleTokenRequest(
+	_ context.Context,
+	req *ileap.TokenRequest,
+) (*oauth2.Token, error) {
+	client, ok := p.client(req.ClientID)
+	if !ok || !client.enabled {
+		verifySecret(dummySecretHash(), req.ClientSecret)
+		return nil, errInvalidCredentials()
+	}
+	switch req.AuthMethod {
+	case ileap.ClientAuthMethodSecretBasic:
+		if !verifySecret(client.secretHash, req.ClientSecret) {
+			return nil, errInvalidCredentials()
+		}
+	case ileap.ClientAuthMethodPrivateKeyJWT:
+		if err := p.verifyAssertion(client, req); err != nil {
+			return nil, connect.NewError(
+				connect.CodePermissionDenied,
+				fmt.Errorf("invalid client assertion: %w", err),
+			)
+		}
+	case ileap.ClientAuthMethodTLS, ileap.ClientAuthMethodSelfSignedTLS:
+		if !client.certificateMatches(req.ClientCertificate, req.AuthMethod) {
+			return nil, errInvalidCredentials()
+		}
+	default:
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("unsupported client authentication method: %s", req.AuthMethod),
+		)
+	}
+	return p.issueToken(client, req.ClientCertificate, req.Scope)
+}
+
+// issueToken returns a signed JWT for the client, bound to the certificate if
+// not nil, granting the requested scopes the client is registered with.
+func (p *FileAuthProvider) issueToken(
+	client *registeredClient,
+	cert *x509.Certificate,
+	scope []string,
+) (*oauth2.Token, error) {
+	scopes := client.scopes
+	if len(scope) > 0 {
+		scopes = slices.DeleteFunc(slices.Clone(scope), func(s string) bool {
+			return !slices.Contains(client.scopes, s)
+		})
+		if len(scopes) == 0 {
+			return nil, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("invalid scope: %s", strings.Join(scope, " ")),
+			)
+		}
+	}
+	issuedAt := time.Now()
+	expiresAt := issuedAt.Add(client.tokenTTL)
+	claims := JWTClaims{
+		Username:     client.id,
+		Scope:        strings.Join(scopes, " "),
+		Organization: client.organization,
+		JWTID:        rand.Text(),
+		IssuedAt:     issuedAt.Unix(),
+		Expiration:   expiresAt.Unix(),
+	}
+	if cert != nil {
+		claims.Confirmation = &ileap.TokenConfirmation{
+			CertificateThumbprint: ileap.CertificateThumbprint(cert),
+		}
+	}
+	accessToken, err := p.keys.CreateJWT(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{
+		AccessToken: accessToken,
+		TokenType:   "bearer",
+		Expiry:      expiresAt,
+		ExpiresIn:   int64(client.tokenTTL.Seconds()),
+	}, nil
+}
+
+// verifyAssertion verifies a private_key_jwt client assertion (RFC 7523,
+// section 3) and records its JWT ID to reject replays.
+func (p *FileAuthProvider) verifyAssertion(
+	client *registeredClient,
+	req *ileap.TokenRequest,
+) error {
+	t, err := jose.Parse(req.ClientAssertion)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(jose.SupportedAlgorithms, t.Header.Algorithm) {
+		return fmt.Errorf("unsupported alg: %s", t.Header.Algorithm)
+	}
+	verified := false
+	for _, key := range client.publicKeys {
+		err := jose.VerifySignature(t.Header.Algorithm, key, t.SigningInput, t.Signature)
+		if err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return errors.New("invalid signature")
+	}
+	if iss, _ := t.Claims["iss"].(string); iss != client.id {
+		return fmt.Errorf("invalid iss claim: %q", iss)
+	}
+	if sub, _ := t.Claims["sub"].(string); sub != client.id {
+		return fmt.Errorf("invalid sub claim: %q", sub)
+	}
+	aud := jose.Audience(t.Claims)
+	if !slices.Contains(aud, req.TokenEndpoint) && !slices.Contains(aud, req.Issuer) {
+		return fmt.Errorf("invalid aud claim: %v", aud)
+	}
+	now := time.Now()
+	exp, ok, err := jose.NumericDate(t.Claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("missing exp claim")
+	}
+	expiresAt := time.Unix(exp, 0)
+	if now.After(expiresAt.Add(assertionClockSkew)) {
+		return errors.New("assertion expired")
+	}
+	nbf, ok, err := jose.NumericDate(t.Claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if ok && now.Add(assertionClockSkew).Before(time.Unix(nbf, 0)) {
+		return errors.New("assertion not yet valid")
+	}
+	jti, _ := t.Claims["jti"].(string)
+	if jti == "" {
+		return errors.New("missing jti claim")
+	}
+	p.assertionsMu.Lock()
+	defer p.assertionsMu.Unlock()
+	for key, exp := range p.assertions {
+		if now.After(exp.Add(assertionClockSkew)) {
+			delete(p.assertions, key)
+		}
+	}
+	key := client.id + " " + jti
+	if _, ok := p.assertions[key]; ok {
+		return fmt.Errorf("replayed jti: %q", jti)
+	}
+	p.assertions[key] = expiresAt
+	return nil
+}
+
+// ValidateToken validates a JWT and returns token info. Tokens of clients that
+// have since been removed or disabled are rejected.
+func (p *FileAuthProvider) ValidateToken(
+	_ context.Context,
+	token string,
+) (*ileap.TokenInfo, error) {
+	claims, err := p.validateJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	info := &ileap.TokenInfo{
+		Subject:      claims.Username,
+		Scopes:       strings.Fields(claims.Scope),
+		Organization: claims.Organization,
+	}
+	if claims.Confirmation != nil {
+		info.CertificateThumbprint = claims.Confirmation.CertificateThumbprint
+	}
+	return info, nil
+}
+
+// OpenIDConfiguration returns the OIDC configuration for the given base URL,
+// advertising the supported client authentication methods.
+func (p *FileAuthProvider) OpenIDConfiguration(baseURL string) *ileap.OpenIDConfiguration {
+	cfg := p.AuthProvider.OpenIDConfiguration(baseURL)
+	cfg.TokenEndpointAuthMethods = []ileap.ClientAuthMethod{
+		ileap.ClientAuthMethodSecretBasic,
+		ileap.ClientAuthMethodPrivateKeyJWT,
+		ileap.ClientAuthMethodTLS,
+		ileap.ClientAuthMethodSelfSignedTLS,
+	}
+	cfg.TokenEndpointAuthAlgorithms = jose.SupportedAlgorithms
+	cfg.CertificateBoundAccessTokens = true
+	return cfg
+}
+
+// IntrospectToken authenticates a registered client and introspects the token.
+func (p *FileAuthProvider) IntrospectToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (*ileap.TokenIntrospection, error) {
+	if _, ok := p.authenticate(clientID, clientSecret); !ok {
+		return nil, errInvalidCredentials()
+	}
+	claims, err := p.validateJWT(token)
+	if err != nil {
+		return &ileap.TokenIntrospection{Active: false}, nil
+	}
+	return introspection(claims), nil
+}
+
+// RevokeToken authenticates a registered client and checks that the token was
+// issued to it. It returns the expiration time of the token.
+func (p *FileAuthProvider) RevokeToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (time.Time, error) {
+	if _, ok := p.authenticate(clientID, clientSecret); !ok {
+		return time.Time{}, errInvalidCredentials()
+	}
+	claims, err := p.keys.ValidateJWT(token)
+	if err != nil {
+		// Invalid tokens need no revocation (RFC 7009, section 2.2).
+		return time.Time{}, nil
+	}
+	if err := checkTokenClient(claims, clientID); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(claims.Expiration, 0), nil
+}
+
+// authenticate returns the enabled client with the credentials. Unknown and
+// disabled clients take as long to reject as a wrong secret.
+func (p *FileAuthProvider) authenticate(
+	clientID, clientSecret string,
+) (*registeredClient, bool) {
+	client, ok := p.client(clientID)
+	if !ok || !client.enabled {
+		verifySecret(dummySecretHash(), clientSecret)
+		return nil, false
+	}
+	if !verifySecret(client.secretHash, clientSecret) {
+		return nil, false
+	}
+	return client, true
+}
+
+// validateJWT validates a JWT issued to a client that is still enabled.
+func (p *FileAuthProvider) validateJWT(token string) (*JWTClaims, error) {
+	claims, err := p.keys.ValidateJWT(token)
+	if err != nil {
+		return nil, err
+	}
+	client, ok := p.client(claims.Username)
+	if !ok || !client.enabled {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("client %q is not enabled", claims.Username),
+		)
+	}
+	return claims, nil
+}
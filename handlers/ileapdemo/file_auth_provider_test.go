@@ -0,0 +1,501 @@
+package ileapdemo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func bcryptHash(t *testing.T, secret string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hash)
+}
+
+func hashArgon2id(secret string) string {
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte(secret), salt, 1, 64, 1, 32)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=64,t=1,p=1$%s$%s",
+		argon2.Version,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func writeClientsFile(t *testing.T, path, content string) {
+	t.Helper()
+	var mtime time.Time
+	if info, err := os.Stat(path); err == nil {
+		mtime = info.ModTime()
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Ensure the modification time changes on file systems with coarse timestamps.
+	if !mtime.IsZero() {
+		if err := os.Chtimes(path, time.Time{}, mtime.Add(time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestFileAuthProvider(t *testing.T, name, content string) (*FileAuthProvider, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, name)
+	writeClientsFile(t, path, content)
+	p, err := NewFileAuthProvider(
+		path,
+		WithSigningKeyFile(filepath.Join(dir, "signing-key.pem")),
+		WithReloadInterval(0),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, path
+}
+
+func TestFileAuthProvider(t *testing.T) {
+	p, _ := newTestFileAuthProvider(t, "clients.yaml", fmt.Sprintf(`
+clients:
+  - id: partner-a
+    secretHash: '%s'
+    scopes: [footprints:read, tad:read]
+    organization: org-a
+    tokenTTL: 10m
+  - id: partner-b
+    secretHash: '%s'
+  - id: partner-c
+    secretHash: '%s'
+    enabled: false
+`, bcryptHash(t, "secret-a"), hashArgon2id("secret-b"), bcryptHash(t, "secret-c")))
+
+	t.Run("bcrypt", func(t *testing.T) {
+		token, err := p.IssueToken(t.Context(), "partner-a", "secret-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.ExpiresIn != 600 {
+			t.Errorf("got expires_in %d, want 600", token.ExpiresIn)
+		}
+		info, err := p.ValidateToken(t.Context(), token.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Subject != "partner-a" {
+			t.Errorf("got subject %q, want %q", info.Subject, "partner-a")
+		}
+		if want := []string{"footprints:read", "tad:read"}; !slices.Equal(info.Scopes, want) {
+			t.Errorf("got scopes %v, want %v", info.Scopes, want)
+		}
+		if info.Organization != "org-a" {
+			t.Errorf("got organization %q, want %q", info.Organization, "org-a")
+		}
+	})
+
+	t.Run("argon2id", func(t *testing.T) {
+		token, err := p.IssueToken(t.Context(), "partner-b", "secret-b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.ExpiresIn != int64(accessTokenTTL.Seconds()) {
+			t.Errorf("got expires_in %d, want default TTL", token.ExpiresIn)
+		}
+		if _, err := p.ValidateToken(t.Context(), token.AccessToken); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("requested scopes", func(t *testing.T) {
+		for _, tc := range []struct {
+			scope []string
+			want  []string
+		}{
+			{scope: []string{"tad:read", "other"}, want: []string{"tad:read"}},
+			{scope: []string{"other"}},
+		} {
+			token, err := p.HandleTokenRequest(t.Context(), &ileap.TokenRequest{
+				ClientID:     "partner-a",
+				ClientSecret: "secret-a",
+				AuthMethod:   ileap.ClientAuthMethodSecretBasic,
+				Scope:        tc.scope,
+			})
+			if tc.want == nil {
+				if got := connect.CodeOf(err); got != connect.CodeInvalidArgument {
+					t.Errorf("%v: got code %v, want %v", tc.scope, got, connect.CodeInvalidArgument)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := p.ValidateToken(t.Context(), token.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(info.Scopes, tc.want) {
+				t.Errorf("%v: got scopes %v, want %v", tc.scope, info.Scopes, tc.want)
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		name     string
+		clientID string
+		secret   string
+	}{
+		{name: "wrong bcrypt secret", clientID: "partner-a", secret: "secret-b"},
+		{name: "wrong argon2id secret", clientID: "partner-b", secret: "secret-a"},
+		{name: "disabled client", clientID: "partner-c", secret: "secret-c"},
+		{name: "unknown client", clientID: "partner-d", secret: "secret-a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := p.IssueToken(t.Context(), tc.clientID, tc.secret)
+			if got := connect.CodeOf(err); got != connect.CodePermissionDenied {
+				t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+			}
+		})
+	}
+}
+
+func TestFileAuthProvider_JSON(t *testing.T) {
+	p, _ := newTestFileAuthProvider(t, "clients.json", fmt.Sprintf(
+		`{"clients": [{"id": "partner-a", "secretHash": %q, "scopes": ["footprints:read"]}]}`,
+		bcryptHash(t, "secret-a"),
+	))
+	token, err := p.IssueToken(t.Context(), "partner-a", "secret-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := p.ValidateToken(t.Context(), token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.HasScope("footprints:read") {
+		t.Errorf("got scopes %v, want footprints:read", info.Scopes)
+	}
+}
+
+func TestFileAuthProvider_Reload(t *testing.T) {
+	hashA, hashB := bcryptHash(t, "secret-a"), bcryptHash(t, "secret-b")
+	p, path := newTestFileAuthProvider(t, "clients.yaml", fmt.Sprintf(`
+clients:
+  - id: partner-a
+    secretHash: '%s'
+`, hashA))
+	token, err := p.IssueToken(t.Context(), "partner-a", "secret-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Disable partner-a and add partner-b.
+	writeClientsFile(t, path, fmt.Sprintf(`
+clients:
+  - id: partner-a
+    secretHash: '%s'
+    enabled: false
+  - id: partner-b
+    secretHash: '%s'
+`, hashA, hashB))
+	_, err = p.ValidateToken(t.Context(), token.AccessToken)
+	if got := connect.CodeOf(err); got != connect.CodePermissionDenied {
+		t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+	}
+	if _, err := p.IssueToken(t.Context(), "partner-b", "secret-b"); err != nil {
+		t.Errorf("got %v, want reloaded client to authenticate", err)
+	}
+
+	// Invalid files keep the loaded clients.
+	writeClientsFile(t, path, "clients: [{id: partner-b, secretHash: plaintext}]")
+	if _, err := p.IssueToken(t.Context(), "partner-b", "secret-b"); err != nil {
+		t.Errorf("got %v, want loaded clients to be kept", err)
+	}
+}
+
+func TestNewFileAuthProvider_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: "clients: [{id: a, secret: plaintext}]"},
+		{name: "missing ID", content: fmt.Sprintf("clients: [{secretHash: '%s'}]", hashArgon2id("a"))},
+		{name: "plaintext secret", content: "clients: [{id: a, secretHash: plaintext}]"},
+		{name: "missing credentials", content: "clients: [{id: a}]"},
+		{name: "invalid public key", content: "clients: [{id: a, publicKeys: [key]}]"},
+		{name: "invalid TTL", content: fmt.Sprintf(
+			"clients: [{id: a, secretHash: '%s', tokenTTL: forever}]", hashArgon2id("a"),
+		)},
+		{name: "duplicate ID", content: fmt.Sprintf(
+			"clients: [{id: a, secretHash: '%[1]s'}, {id: a, secretHash: '%[1]s'}]",
+			hashArgon2id("a"),
+		)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "clients.yaml")
+			writeClientsFile(t, path, tc.content)
+			if _, err := NewFileAuthProvider(path); err == nil {
+				t.Error("got nil error, want error")
+			}
+		})
+	}
+}
+
+func newTestAssertionKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func signAssertion(t *testing.T, key *ecdsa.PrivateKey, claims map[string]any) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","typ":"JWT"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestCertificate(t *testing.T, commonName string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestFileAuthProvider_PrivateKeyJWT(t *testing.T) {
+	key, publicKey := newTestAssertionKey(t)
+	otherKey, _ := newTestAssertionKey(t)
+	p, _ := newTestFileAuthProvider(t, "clients.json", fmt.Sprintf(
+		`{"clients": [{"id": "partner-a", "publicKeys": [%q], "scopes": ["footprints:read"]}]}`,
+		publicKey,
+	))
+	const tokenEndpoint = "https://example.com/auth/token"
+	claims := func() map[string]any {
+		return map[string]any{
+			"iss": "partner-a",
+			"sub": "partner-a",
+			"aud": tokenEndpoint,
+			"jti": rand.Text(),
+			"exp": time.Now().Add(time.Minute).Unix(),
+		}
+	}
+	handle := func(assertion string) error {
+		_, err := p.HandleTokenRequest(t.Context(), &ileap.TokenRequest{
+			AuthMethod:      ileap.ClientAuthMethodPrivateKeyJWT,
+			ClientID:        "partner-a",
+			ClientAssertion: assertion,
+			Issuer:          "https://example.com",
+			TokenEndpoint:   tokenEndpoint,
+		})
+		return err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		token, err := p.HandleTokenRequest(t.Context(), &ileap.TokenRequest{
+			AuthMethod:      ileap.ClientAuthMethodPrivateKeyJWT,
+			ClientID:        "partner-a",
+			ClientAssertion: signAssertion(t, key, claims()),
+			TokenEndpoint:   tokenEndpoint,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := p.ValidateToken(t.Context(), token.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Subject != "partner-a" || info.CertificateThumbprint != "" {
+			t.Errorf("got token info %+v", info)
+		}
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		assertion := signAssertion(t, key, claims())
+		if err := handle(assertion); err != nil {
+			t.Fatal(err)
+		}
+		if got := connect.CodeOf(handle(assertion)); got != connect.CodePermissionDenied {
+			t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		key    *ecdsa.PrivateKey
+		modify func(map[string]any)
+	}{
+		{name: "other key", key: otherKey},
+		{name: "wrong issuer", modify: func(c map[string]any) { c["iss"] = "partner-b" }},
+		{name: "wrong audience", modify: func(c map[string]any) { c["aud"] = "other" }},
+		{name: "missing jti", modify: func(c map[string]any) { delete(c, "jti") }},
+		{name: "missing exp", modify: func(c map[string]any) { delete(c, "exp") }},
+		{
+			name: "expired",
+			modify: func(c map[string]any) {
+				c["exp"] = time.Now().Add(-2 * assertionClockSkew).Unix()
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			signingKey, c := key, claims()
+			if tc.key != nil {
+				signingKey = tc.key
+			}
+			if tc.modify != nil {
+				tc.modify(c)
+			}
+			err := handle(signAssertion(t, signingKey, c))
+			if got := connect.CodeOf(err); got != connect.CodePermissionDenied {
+				t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+			}
+		})
+	}
+}
+
+func TestFileAuthProvider_MutualTLS(t *testing.T) {
+	selfSigned := newTestCertificate(t, "partner-a")
+	caIssued := newTestCertificate(t, "partner-b")
+	p, _ := newTestFileAuthProvider(t, "clients.yaml", fmt.Sprintf(`
+clients:
+  - id: partner-a
+    certificateThumbprints: ['%s']
+  - id: partner-b
+    certificateSubject: '%s'
+`, ileap.CertificateThumbprint(selfSigned), caIssued.Subject))
+
+	for _, tc := range []struct {
+		name     string
+		clientID string
+		method   ileap.ClientAuthMethod
+		cert     *x509.Certificate
+		wantErr  bool
+	}{
+		{
+			name:     "registered thumbprint",
+			clientID: "partner-a",
+			method:   ileap.ClientAuthMethodSelfSignedTLS,
+			cert:     selfSigned,
+		},
+		{
+			name:     "verified subject",
+			clientID: "partner-b",
+			method:   ileap.ClientAuthMethodTLS,
+			cert:     caIssued,
+		},
+		{
+			name:     "unverified subject",
+			clientID: "partner-b",
+			method:   ileap.ClientAuthMethodSelfSignedTLS,
+			cert:     caIssued,
+			wantErr:  true,
+		},
+		{
+			name:     "other certificate",
+			clientID: "partner-a",
+			method:   ileap.ClientAuthMethodSelfSignedTLS,
+			cert:     newTestCertificate(t, "partner-a"),
+			wantErr:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := p.HandleTokenRequest(t.Context(), &ileap.TokenRequest{
+				AuthMethod:        tc.method,
+				ClientID:          tc.clientID,
+				ClientCertificate: tc.cert,
+			})
+			if tc.wantErr {
+				if got := connect.CodeOf(err); got != connect.CodePermissionDenied {
+					t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := p.ValidateToken(t.Context(), token.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := ileap.CertificateThumbprint(tc.cert); info.CertificateThumbprint != want {
+				t.Errorf("got certificate thumbprint %q, want %q", info.CertificateThumbprint, want)
+			}
+		})
+	}
+}
+
+func TestLoadOrGenerateKeyPair(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "signing-key.pem")
+	generated, err := LoadOrGenerateKeyPair(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0o600 {
+		t.Errorf("got mode %v, want 0600", got)
+	}
+	loaded, err := LoadOrGenerateKeyPair(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !generated.PrivateKey.Equal(loaded.PrivateKey) {
+		t.Error("got different key, want persisted key")
+	}
+}
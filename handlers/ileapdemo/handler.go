@@ -56,7 +56,7 @@ func (h *Handler) ListFootprints(
 ) (*ileapv1.ListFootprintsResponse, error) {
 	filtered := make([]*ileapv1.ProductFootprint, 0, len(h.footprints))
 	for _, fp := range h.footprints {
-		if footprintMatchesFilters(fp, req.GetFilters()) {
+		if footprintMatchesRequest(fp, req) {
 			filtered = append(filtered, fp)
 		}
 	}
@@ -185,6 +185,75 @@ func matchesFeedstockFilter(
 	return true, found
 }
 
+func footprintMatchesRequest(
+	fp *ileapv1.ProductFootprint,
+	req *ileapv1.ListFootprintsRequest,
+) bool {
+	if req.HasFilter() {
+		return footprintMatchesExpression(fp, req.GetFilter())
+	}
+	return footprintMatchesFilters(fp, req.GetFilters())
+}
+
+// footprintMatchesExpression evaluates a filter expression. Unsupported
+// comparisons and lambdas are ignored by treating them as matching.
+func footprintMatchesExpression(
+	fp *ileapv1.ProductFootprint,
+	expr *ileapv1.FilterExpression,
+) bool {
+	switch expr.WhichExpression() {
+	case ileapv1.FilterExpression_Comparison_case:
+		ok, match := footprintMatchesSingleFilter(fp, expr.GetComparison())
+		return !ok || match
+	case ileapv1.FilterExpression_And_case:
+		for _, operand := range expr.GetAnd().GetOperands() {
+			if !footprintMatchesExpression(fp, operand) {
+				return false
+			}
+		}
+		return true
+	case ileapv1.FilterExpression_Or_case:
+		for _, operand := range expr.GetOr().GetOperands() {
+			if footprintMatchesExpression(fp, operand) {
+				return true
+			}
+		}
+		return false
+	case ileapv1.FilterExpression_Not_case:
+		return !footprintMatchesExpression(fp, expr.GetNot())
+	case ileapv1.FilterExpression_In_case:
+		in := expr.GetIn()
+		supported := false
+		for _, value := range in.GetValues() {
+			filter := new(ileapv1.Filter)
+			filter.SetFieldPath(in.GetFieldPath())
+			filter.SetOperator(ileapv1.Filter_EQ)
+			filter.SetValue(value)
+			ok, match := footprintMatchesSingleFilter(fp, filter)
+			supported = supported || ok
+			if match {
+				return true
+			}
+		}
+		return !supported
+	case ileapv1.FilterExpression_Any_case:
+		// Only lambdas over the collections of strings are supported,
+		// with a single comparison of the element.
+		predicate := expr.GetAny().GetPredicate().GetComparison()
+		if predicate == nil || predicate.GetFieldPath() != "" {
+			return true
+		}
+		filter := new(ileapv1.Filter)
+		filter.SetFieldPath(expr.GetAny().GetFieldPath())
+		filter.SetOperator(predicate.GetOperator())
+		filter.SetValue(predicate.GetValue())
+		ok, match := footprintMatchesSingleFilter(fp, filter)
+		return !ok || match
+	default:
+		return true
+	}
+}
+
 func footprintMatchesFilters(
 	fp *ileapv1.ProductFootprint,
 	filters []*ileapv1.Filter,
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/way-platform/ileap-go/internal/odata"
 	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -88,6 +89,55 @@ func TestFootprintMatchesFilters_ORSemantics(t *testing.T) {
 	}
 }
 
+func TestFootprintMatchesExpression(t *testing.T) {
+	fp := new(ileapv1.ProductFootprint)
+	fp.SetProductCategoryCpc("83117")
+	fp.SetCompanyIds([]string{"acme"})
+	fp.SetCreated(timestamppb.New(time.Date(2022, 3, 1, 9, 32, 20, 0, time.UTC)))
+
+	tests := []struct {
+		name   string
+		filter string
+		want   bool
+	}{
+		{
+			name:   "or match",
+			filter: "productCategoryCpc eq '99999' or companyIds/any(c:c eq 'acme')",
+			want:   true,
+		},
+		{
+			name:   "or no match",
+			filter: "productCategoryCpc eq '99999' or companyIds/any(c:c eq 'other')",
+			want:   false,
+		},
+		{
+			name:   "and",
+			filter: "productCategoryCpc eq '83117' and created lt 2022-01-01T00:00:00Z",
+			want:   false,
+		},
+		{name: "not", filter: "not (productCategoryCpc eq '83117')", want: false},
+		{name: "in", filter: "productCategoryCpc in ('1', '83117')", want: true},
+		{
+			name:   "unsupported comparison ignored",
+			filter: "unknownField eq 'x' and productCategoryCpc eq '83117'",
+			want:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := odata.ParseExpression(tc.filter)
+			if err != nil {
+				t.Fatalf("parse filter: %v", err)
+			}
+			got := footprintMatchesExpression(fp, expr)
+			if got != tc.want {
+				t.Fatalf("footprintMatchesExpression() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestTADMatchesFilters_ORSemantics(t *testing.T) {
 	tad := new(ileapv1.TAD)
 	tad.SetActivityId("a-1")
@@ -1,19 +1,31 @@
 package ileapdemo
 
+import "github.com/way-platform/ileap-go"
+
 // JWTHeader is the header of a JWT.
 type JWTHeader struct {
 	// Type is the type of the JWT.
 	Type string `json:"typ"`
 	// Algorithm is the algorithm used to sign the JWT.
 	Algorithm string `json:"alg"`
+	// KeyID is the ID of the key used to sign the JWT.
+	KeyID string `json:"kid,omitempty"`
 }
 
 // JWTClaims are the claims of a JWT.
 type JWTClaims struct {
 	// Username is the username of the user.
 	Username string `json:"username"`
+	// Scope is the space-separated list of scopes granted to the token.
+	Scope string `json:"scope,omitempty"`
+	// Organization is the organization of the user.
+	Organization string `json:"org_id,omitempty"`
+	// JWTID is the unique identifier of the JWT.
+	JWTID string `json:"jti,omitempty"`
 	// IssuedAt is the Unix timestamp of the JWT issuance.
 	IssuedAt int64 `json:"iat,omitempty"`
 	// Expiration is the Unix timestamp of the JWT expiration.
 	Expiration int64 `json:"exp,omitempty"`
+	// Confirmation binds the JWT to a client certificate.
+	Confirmation *ileap.TokenConfirmation `json:"cnf,omitempty"`
 }
@@ -0,0 +1,197 @@
+package ileapdemo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+)
+
+// KeyManager signs JWTs with an active key and verifies them with the active
+// key and the previous keys that may still have outstanding tokens.
+//
+// On rotation, the active key is retired and kept for verification until the
+// last token it signed expires, so rotating keys does not invalidate any
+// outstanding tokens. The initial key, which may have signed tokens before a
+// restart, is kept for at least the maximum token lifetime.
+type KeyManager struct {
+	rotationInterval time.Duration
+	maxTokenTTL      time.Duration
+	generateKey      func() (*KeyPair, error)
+
+	mu       sync.Mutex
+	active   *managedKey
+	previous []*managedKey
+}
+
+// managedKey is a key of a KeyManager.
+type managedKey struct {
+	keyPair   *KeyPair
+	keyID     string
+	createdAt time.Time
+	// notAfter is the latest expiration of the tokens signed with the key.
+	notAfter time.Time
+	// noExpiry is set when the key has signed a token without expiration.
+	noExpiry bool
+}
+
+// KeyManagerOption configures the KeyManager.
+type KeyManagerOption func(*KeyManager)
+
+// WithRotationInterval sets the interval after which the active key is
+// rotated. Defaults to 0, which disables scheduled rotation.
+func WithRotationInterval(d time.Duration) KeyManagerOption {
+	return func(m *KeyManager) { m.rotationInterval = d }
+}
+
+// WithMaxTokenTTL sets the maximum lifetime of the tokens signed with the
+// keys, for which the initial key is kept for verification. Defaults to 1 hour.
+func WithMaxTokenTTL(d time.Duration) KeyManagerOption {
+	return func(m *KeyManager) { m.maxTokenTTL = d }
+}
+
+// WithKeyGenerator sets the function that generates new keys on rotation.
+// Defaults to GenerateKeyPair.
+func WithKeyGenerator(generate func() (*KeyPair, error)) KeyManagerOption {
+	return func(m *KeyManager) { m.generateKey = generate }
+}
+
+// NewKeyManager creates a new KeyManager with the active key.
+func NewKeyManager(active *KeyPair, opts ...KeyManagerOption) *KeyManager {
+	m := &KeyManager{
+		maxTokenTTL: accessTokenTTL,
+		generateKey: GenerateKeyPair,
+		active:      newManagedKey(active),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	// Tokens signed with the initial key before a restart are not tracked.
+	m.active.notAfter = m.active.createdAt.Add(m.maxTokenTTL)
+	return m
+}
+
+func newManagedKey(kp *KeyPair) *managedKey {
+	return &managedKey{keyPair: kp, keyID: kp.KeyID(), createdAt: time.Now()}
+}
+
+// ActiveKey returns the key currently used for signing.
+func (m *KeyManager) ActiveKey() *KeyPair {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active.keyPair
+}
+
+// Rotate generates a new active key and retires the current one.
+func (m *KeyManager) Rotate() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rotateLocked()
+}
+
+func (m *KeyManager) rotateLocked() error {
+	kp, err := m.generateKey()
+	if err != nil {
+		return fmt.Errorf("rotate key: %w", err)
+	}
+	m.previous = append(m.previous, m.active)
+	m.active = newManagedKey(kp)
+	m.pruneLocked()
+	slog.Info("rotated signing key", "kid", m.active.keyID)
+	return nil
+}
+
+// pruneLocked removes the previous keys whose tokens have all expired.
+func (m *KeyManager) pruneLocked() {
+	now := time.Now()
+	n := 0
+	for _, key := range m.previous {
+		if key.noExpiry || key.notAfter.After(now) {
+			m.previous[n] = key
+			n++
+		}
+	}
+	clear(m.previous[n:])
+	m.previous = m.previous[:n]
+}
+
+// CreateJWT creates a JSON Web Token with the given claims, signed with the
+// active key. The active key is rotated first if it is due for rotation.
+func (m *KeyManager) CreateJWT(claims JWTClaims) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rotationInterval > 0 && time.Since(m.active.createdAt) >= m.rotationInterval {
+		if err := m.rotateLocked(); err != nil {
+			return "", err
+		}
+	}
+	token, err := m.active.keyPair.CreateJWT(claims)
+	if err != nil {
+		return "", err
+	}
+	if claims.Expiration == 0 {
+		m.active.noExpiry = true
+	} else if exp := time.Unix(claims.Expiration, 0); exp.After(m.active.notAfter) {
+		m.active.notAfter = exp
+	}
+	return token, nil
+}
+
+// ValidateJWT validates a JWT with the key identified by its kid header.
+// Tokens without a kid are validated with the active key.
+func (m *KeyManager) ValidateJWT(token string) (*JWTClaims, error) {
+	headerPart, _, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid JWT format")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(headerPart)
+	if err != nil {
+		return nil, fmt.Errorf("decode header: %w", err)
+	}
+	var header JWTHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("unmarshal header: %w", err)
+	}
+	kp, ok := m.verificationKey(header.KeyID)
+	if !ok {
+		return nil, connect.NewError(
+			connect.CodeUnauthenticated,
+			fmt.Errorf("unknown key ID: %q", header.KeyID),
+		)
+	}
+	return kp.ValidateJWT(token)
+}
+
+func (m *KeyManager) verificationKey(keyID string) (*KeyPair, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if keyID == "" || keyID == m.active.keyID {
+		return m.active.keyPair, true
+	}
+	m.pruneLocked()
+	for _, key := range m.previous {
+		if key.keyID == keyID {
+			return key.keyPair, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS returns the JSON Web Key Set containing the active key and the previous
+// keys that may still have outstanding tokens.
+func (m *KeyManager) JWKS() *ileap.JWKSet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	keys := []ileap.JWK{m.active.keyPair.JWK()}
+	for i := len(m.previous) - 1; i >= 0; i-- {
+		keys = append(keys, m.previous[i].keyPair.JWK())
+	}
+	return &ileap.JWKSet{Keys: keys}
+}
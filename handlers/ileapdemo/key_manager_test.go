@@ -0,0 +1,188 @@
+package ileapdemo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+func newTestKeyManager(t *testing.T, opts ...KeyManagerOption) *KeyManager {
+	t.Helper()
+	kp, err := LoadKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewKeyManager(kp, opts...)
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+	headerPart, _, _ := strings.Cut(token, ".")
+	headerBytes, err := base64.RawURLEncoding.DecodeString(headerPart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header JWTHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		t.Fatal(err)
+	}
+	return header.KeyID
+}
+
+func jwksKeyIDs(m *KeyManager) []string {
+	var keyIDs []string
+	for _, jwk := range m.JWKS().Keys {
+		keyIDs = append(keyIDs, jwk.KeyID)
+	}
+	return keyIDs
+}
+
+func TestKeyPair_KeyID(t *testing.T) {
+	kp, err := LoadKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// encoding/json sorts map keys and emits no whitespace, as RFC 7638 requires.
+	input, err := json.Marshal(map[string]string{
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(kp.PublicKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(kp.PublicKey.E)).Bytes()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumbprint := sha256.Sum256(input)
+	want := base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	if got := kp.KeyID(); got != want {
+		t.Errorf("got kid %q, want %q", got, want)
+	}
+	if got := kp.JWK().KeyID; got != want {
+		t.Errorf("got JWK kid %q, want %q", got, want)
+	}
+}
+
+func TestKeyManager_Rotate(t *testing.T) {
+	m := newTestKeyManager(t)
+	oldKeyID := m.ActiveKey().KeyID()
+	oldToken, err := m.CreateJWT(JWTClaims{
+		Username:   "hello",
+		Expiration: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenKeyID(t, oldToken); got != oldKeyID {
+		t.Errorf("got kid %q, want %q", got, oldKeyID)
+	}
+
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	newKeyID := m.ActiveKey().KeyID()
+	if newKeyID == oldKeyID {
+		t.Fatal("got same key after rotation")
+	}
+	newToken, err := m.CreateJWT(JWTClaims{
+		Username:   "hello",
+		Expiration: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenKeyID(t, newToken); got != newKeyID {
+		t.Errorf("got kid %q, want %q", got, newKeyID)
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := m.ValidateJWT(token); err != nil {
+			t.Errorf("got %v, want outstanding token to validate", err)
+		}
+	}
+	got := jwksKeyIDs(m)
+	if len(got) != 2 || got[0] != newKeyID || got[1] != oldKeyID {
+		t.Errorf("got JWKS keys %v, want [%s %s]", got, newKeyID, oldKeyID)
+	}
+}
+
+func TestKeyManager_Prune(t *testing.T) {
+	m := newTestKeyManager(t, WithMaxTokenTTL(0))
+	expiredToken, err := m.CreateJWT(JWTClaims{
+		Username:   "hello",
+		Expiration: time.Now().Add(-time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	// The previous key has no outstanding tokens and is no longer published.
+	if got := jwksKeyIDs(m); len(got) != 1 || got[0] != m.ActiveKey().KeyID() {
+		t.Errorf("got JWKS keys %v, want only the active key", got)
+	}
+	_, err = m.ValidateJWT(expiredToken)
+	if got := connect.CodeOf(err); got != connect.CodeUnauthenticated {
+		t.Errorf("got code %v, want %v", got, connect.CodeUnauthenticated)
+	}
+}
+
+func TestKeyManager_RotationInterval(t *testing.T) {
+	m := newTestKeyManager(t, WithRotationInterval(time.Nanosecond))
+	var keyIDs []string
+	for range 2 {
+		token, err := m.CreateJWT(JWTClaims{
+			Username:   "hello",
+			Expiration: time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyIDs = append(keyIDs, tokenKeyID(t, token))
+	}
+	if keyIDs[0] == keyIDs[1] {
+		t.Errorf("got same kid %q, want rotated key", keyIDs[0])
+	}
+	// The initial key is kept for tokens it may have signed before a restart.
+	if got := jwksKeyIDs(m); len(got) != 3 || got[1] != keyIDs[0] {
+		t.Errorf("got JWKS keys %v, want the active key, %s and the initial key", got, keyIDs[0])
+	}
+}
+
+func TestKeyManager_Restart(t *testing.T) {
+	token, err := newTestKeyManager(t).CreateJWT(JWTClaims{
+		Username:   "hello",
+		Expiration: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// After a restart, the loaded key is rotated before it signs any token.
+	m := newTestKeyManager(t)
+	if err := m.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ValidateJWT(token); err != nil {
+		t.Errorf("got %v, want token signed before the restart to validate", err)
+	}
+}
+
+func TestKeyManager_UnknownKeyID(t *testing.T) {
+	m := newTestKeyManager(t)
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := other.CreateJWT(JWTClaims{Username: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.ValidateJWT(token)
+	if got := connect.CodeOf(err); got != connect.CodeUnauthenticated {
+		t.Errorf("got code %v, want %v", got, connect.CodeUnauthenticated)
+	}
+}
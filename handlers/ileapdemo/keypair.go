@@ -10,9 +10,13 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -37,7 +41,7 @@ func (k *KeyPair) JWK() ileap.JWK {
 		KeyType:   "RSA",
 		Use:       "sig",
 		Algorithm: "RS256",
-		KeyID:     "Public key",
+		KeyID:     k.KeyID(),
 		N: base64.RawURLEncoding.EncodeToString(
 			k.PublicKey.N.Bytes(),
 		),
@@ -47,11 +51,26 @@ func (k *KeyPair) JWK() ileap.JWK {
 	}
 }
 
+// KeyID returns the JWK thumbprint of the public key (RFC 7638), which is used
+// as its key ID.
+func (k *KeyPair) KeyID() string {
+	// The required members of an RSA key, in lexicographic order and without
+	// whitespace (RFC 7638, section 3.2).
+	thumbprintInput := fmt.Sprintf(
+		`{"e":"%s","kty":"RSA","n":"%s"}`,
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.PublicKey.E)).Bytes()),
+		base64.RawURLEncoding.EncodeToString(k.PublicKey.N.Bytes()),
+	)
+	thumbprint := sha256.Sum256([]byte(thumbprintInput))
+	return base64.RawURLEncoding.EncodeToString(thumbprint[:])
+}
+
 // CreateJWT creates a JSON Web Token with the given claims.
 func (k *KeyPair) CreateJWT(claims JWTClaims) (string, error) {
 	header := JWTHeader{
 		Type:      "JWT",
 		Algorithm: "RS256",
+		KeyID:     k.KeyID(),
 	}
 	headerBytes, err := json.Marshal(header)
 	if err != nil {
@@ -80,7 +99,65 @@ func (k *KeyPair) CreateJWT(claims JWTClaims) (string, error) {
 // LoadKeyPair parses the embedded PEM data and returns a KeyPair.
 func LoadKeyPair() (*KeyPair, error) {
 	defer slog.Debug("loaded demo keypair")
-	block, _ := pem.Decode(keypairData)
+	return parseKeyPair(keypairData)
+}
+
+// GenerateKeyPair generates a new 2048-bit RSA KeyPair.
+func GenerateKeyPair() (*KeyPair, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("generate RSA key: %w", err)
+	}
+	return &KeyPair{
+		PrivateKey: privateKey,
+		PublicKey:  &privateKey.PublicKey,
+	}, nil
+}
+
+// LoadOrGenerateKeyPair loads a KeyPair from a PKCS #8 PEM file. If the file
+// does not exist, it generates a new KeyPair and persists it to the file, so
+// that issued tokens stay valid across restarts.
+func LoadOrGenerateKeyPair(path string) (*KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		kp, err := parseKeyPair(data)
+		if err != nil {
+			return nil, fmt.Errorf("load keypair %s: %w", path, err)
+		}
+		return kp, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load keypair: %w", err)
+	}
+	kp, err := GenerateKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(kp.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("persist keypair: %w", err)
+	}
+	// O_EXCL prevents overwriting a key persisted concurrently by another process.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("persist keypair: %w", err)
+	}
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("persist keypair: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("persist keypair: %w", err)
+	}
+	slog.Info("generated signing keypair", "path", path)
+	return kp, nil
+}
+
+func parseKeyPair(data []byte) (*KeyPair, error) {
+	block, _ := pem.Decode(data)
 	if block == nil {
 		return nil, fmt.Errorf("failed to decode PEM block")
 	}
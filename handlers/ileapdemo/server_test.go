@@ -395,3 +395,70 @@ func getAccessToken(t *testing.T, server *ileap.Server) string {
 	}
 	return credentials.AccessToken
 }
+
+func TestDemoServer_Revocation(t *testing.T) {
+	server := newDemoServer(t)
+	issueToken := func(username, password string) string {
+		t.Helper()
+		req := httptest.NewRequest(
+			"POST",
+			"/auth/token",
+			strings.NewReader("grant_type=client_credentials"),
+		)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		var credentials oauth2.Token
+		if err := json.NewDecoder(w.Body).Decode(&credentials); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return credentials.AccessToken
+	}
+	post := func(path, token, username, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader("token="+token))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(username, password)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+	listTADs := func(token string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/2/ileap/tad", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+	token := issueToken("hello", "pathfinder")
+
+	w := post("/auth/introspect", token, "transport_service_user", "ileap")
+	var introspection ileap.TokenIntrospection
+	if err := json.NewDecoder(w.Body).Decode(&introspection); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !introspection.Active || introspection.ClientID != "hello" {
+		t.Errorf("expected active token of hello, got %+v", introspection)
+	}
+
+	w = post("/auth/revoke", token, "transport_service_user", "ileap")
+	checkOAuthErrorResponse(
+		t,
+		w,
+		http.StatusBadRequest,
+		ileap.OAuthErrorCodeUnauthorizedClient,
+	)
+	if w := listTADs(token); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := post("/auth/revoke", token, "hello", "pathfinder"); w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	checkErrorResponse(t, listTADs(token), http.StatusForbidden, ileap.ErrorCodeAccessDenied)
+	if w := listTADs(issueToken("hello", "pathfinder")); w.Code != http.StatusOK {
+		t.Fatalf("expected new token to be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,460 @@
+// Package ileapoidc provides an iLEAP AuthHandler backed by any OpenID Connect
+// identity provider, such as Keycloak, Auth0, or Microsoft Entra ID.
+//
+// The [AuthHandler] discovers the provider from its issuer URL, proxies
+// client-credentials token requests to the provider's token endpoint, and
+// validates access tokens against the provider's JSON Web Key Set.
+package ileapoidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/internal/jose"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultHTTPTimeout            = 5 * time.Second
+	defaultClockSkew              = time.Minute
+	defaultJWKSCacheTTL           = 15 * time.Minute
+	defaultJWKSMinRefreshInterval = 10 * time.Second
+)
+
+var _ ileap.AuthHandler = (*AuthHandler)(nil)
+
+// AuthHandler implements ileap.AuthHandler on top of an OpenID Connect
+// identity provider.
+type AuthHandler struct {
+	issuer                 string
+	httpClient             *http.Client
+	audiences              []string
+	algorithms             []string
+	clockSkew              time.Duration
+	scopes                 []string
+	organizationClaim      string
+	tokenParams            url.Values
+	jwksCacheTTL           time.Duration
+	jwksMinRefreshInterval time.Duration
+
+	group singleflight.Group
+
+	mu               sync.Mutex
+	metadata         *providerMetadata
+	metadataErr      error
+	metadataFailedAt time.Time
+	jwks             *ileap.JWKSet
+	jwksFetchedAt    time.Time
+	// jwksForcedAt is the time of the last fetch triggered by an unknown key ID.
+	jwksForcedAt time.Time
+	jwksErr      error
+	jwksFailedAt time.Time
+}
+
+// AuthHandlerOption configures the AuthHandler.
+type AuthHandlerOption func(*AuthHandler)
+
+// WithHTTPClient sets the HTTP client used to call the identity provider.
+func WithHTTPClient(hc *http.Client) AuthHandlerOption {
+	return func(a *AuthHandler) { a.httpClient = hc }
+}
+
+// WithAudience requires the aud claim of validated tokens to contain at least
+// one of the given audiences. By default, the audience is not checked.
+func WithAudience(audiences ...string) AuthHandlerOption {
+	return func(a *AuthHandler) { a.audiences = audiences }
+}
+
+// WithAlgorithms restricts the JWS algorithms accepted for validated tokens.
+// Default is RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512,
+// and EdDSA.
+func WithAlgorithms(algorithms ...string) AuthHandlerOption {
+	return func(a *AuthHandler) { a.algorithms = algorithms }
+}
+
+// WithClockSkew sets the tolerance for the exp, nbf, and iat claims.
+// Default is 1 minute.
+func WithClockSkew(d time.Duration) AuthHandlerOption {
+	return func(a *AuthHandler) { a.clockSkew = d }
+}
+
+// WithScopes sets the scopes requested when issuing tokens.
+func WithScopes(scopes ...string) AuthHandlerOption {
+	return func(a *AuthHandler) { a.scopes = scopes }
+}
+
+// WithOrganizationClaim sets the claim that identifies the organization of
+// the caller in validated tokens. Default is "org_id".
+func WithOrganizationClaim(name string) AuthHandlerOption {
+	return func(a *AuthHandler) { a.organizationClaim = name }
+}
+
+// WithTokenParam adds a parameter to token requests, e.g. the "audience"
+// parameter required by Auth0.
+func WithTokenParam(key, value string) AuthHandlerOption {
+	return func(a *AuthHandler) {
+		if a.tokenParams == nil {
+			a.tokenParams = url.Values{}
+		}
+		a.tokenParams.Add(key, value)
+	}
+}
+
+// WithJWKSCacheTTL sets the JWKS cache TTL. Default is 15 minutes.
+func WithJWKSCacheTTL(d time.Duration) AuthHandlerOption {
+	return func(a *AuthHandler) { a.jwksCacheTTL = d }
+}
+
+// WithJWKSMinRefreshInterval sets the minimum interval between JWKS fetches
+// triggered by tokens with an unknown key ID, and between retries of failed
+// discovery and JWKS fetches. Default is 10 seconds.
+func WithJWKSMinRefreshInterval(d time.Duration) AuthHandlerOption {
+	return func(a *AuthHandler) { a.jwksMinRefreshInterval = d }
+}
+
+// NewAuthHandler creates an AuthHandler for the identity provider with the
+// given issuer URL. The provider is discovered on first use.
+func NewAuthHandler(issuer string, opts ...AuthHandlerOption) *AuthHandler {
+	a := &AuthHandler{
+		issuer:                 issuer,
+		httpClient:             &http.Client{Timeout: defaultHTTPTimeout},
+		algorithms:             jose.SupportedAlgorithms,
+		clockSkew:              defaultClockSkew,
+		organizationClaim:      "org_id",
+		jwksCacheTTL:           defaultJWKSCacheTTL,
+		jwksMinRefreshInterval: defaultJWKSMinRefreshInterval,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// IssueToken requests a token from the provider's token endpoint with the
+// client credentials grant.
+func (a *AuthHandler) IssueToken(
+	ctx context.Context, clientID, clientSecret string,
+) (*oauth2.Token, error) {
+	md, err := a.discover(ctx)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+	config := clientcredentials.Config{
+		ClientID:       clientID,
+		ClientSecret:   clientSecret,
+		TokenURL:       md.TokenEndpoint,
+		Scopes:         a.scopes,
+		EndpointParams: a.tokenParams,
+	}
+	if len(md.TokenEndpointAuthMethodsSupported) > 0 &&
+		!slices.Contains(md.TokenEndpointAuthMethodsSupported, "client_secret_basic") &&
+		slices.Contains(md.TokenEndpointAuthMethodsSupported, "client_secret_post") {
+		config.AuthStyle = oauth2.AuthStyleInParams
+	}
+	token, err := config.Token(context.WithValue(ctx, oauth2.HTTPClient, a.httpClient))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+			switch status := retrieveErr.Response.StatusCode; {
+			case status == http.StatusTooManyRequests:
+				return nil, connect.NewError(connect.CodeResourceExhausted, err)
+			case status >= http.StatusInternalServerError:
+				return nil, connect.NewError(connect.CodeUnavailable, err)
+			}
+			return nil, connect.NewError(
+				connect.CodePermissionDenied,
+				fmt.Errorf("invalid credentials: %w", err),
+			)
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return nil, connect.NewError(connect.CodeUnavailable, err)
+		}
+		return nil, fmt.Errorf("request token: %w", err)
+	}
+	creds := &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      token.Expiry,
+	}
+	if !token.Expiry.IsZero() {
+		if seconds := int64(time.Until(token.Expiry).Seconds()); seconds > 0 {
+			creds.ExpiresIn = seconds
+		}
+	}
+	return creds, nil
+}
+
+// ValidateToken validates a JWT access token issued by the provider.
+func (a *AuthHandler) ValidateToken(
+	ctx context.Context, token string,
+) (*ileap.TokenInfo, error) {
+	t, err := jose.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(a.algorithms, t.Header.Algorithm) ||
+		!slices.Contains(jose.SupportedAlgorithms, t.Header.Algorithm) {
+		return nil, fmt.Errorf("unsupported JWT alg: %s", t.Header.Algorithm)
+	}
+	md, err := a.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := a.findKeys(ctx, md, t.Header.KeyID, t.Header.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("find signing key: %w", err)
+	}
+	verified := false
+	for _, jwk := range keys {
+		pub, err := jose.PublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		if jose.VerifySignature(t.Header.Algorithm, pub, t.SigningInput, t.Signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("invalid JWT signature")
+	}
+	if err := a.validateClaims(t.Claims, md.Issuer); err != nil {
+		return nil, err
+	}
+	sub, ok := t.Claims["sub"].(string)
+	if !ok || sub == "" {
+		return nil, fmt.Errorf("missing or invalid sub claim")
+	}
+	organization, _ := t.Claims[a.organizationClaim].(string)
+	// Certificate-bound tokens carry the certificate thumbprint in the
+	// confirmation claim (RFC 8705, section 3.1).
+	var thumbprint string
+	if cnf, ok := t.Claims["cnf"].(map[string]any); ok {
+		thumbprint, _ = cnf["x5t#S256"].(string)
+	}
+	return &ileap.TokenInfo{
+		Subject:               sub,
+		Scopes:                jose.Scopes(t.Claims),
+		Audience:              jose.Audience(t.Claims),
+		Organization:          organization,
+		Claims:                t.Claims,
+		CertificateThumbprint: thumbprint,
+	}, nil
+}
+
+func (a *AuthHandler) validateClaims(claims map[string]any, issuer string) error {
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return fmt.Errorf("invalid iss claim: %q", iss)
+	}
+	if len(a.audiences) > 0 && !slices.ContainsFunc(jose.Audience(claims), func(aud string) bool {
+		return slices.Contains(a.audiences, aud)
+	}) {
+		return fmt.Errorf("invalid aud claim: %v", claims["aud"])
+	}
+	now := time.Now()
+	exp, ok, err := jose.NumericDate(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.Add(-a.clockSkew).After(time.Unix(exp, 0)) {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("token expired"))
+	}
+	if nbf, ok, err := jose.NumericDate(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(a.clockSkew).Before(time.Unix(nbf, 0)) {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("token not yet valid"))
+	}
+	if iat, ok, err := jose.NumericDate(claims, "iat"); err != nil {
+		return err
+	} else if ok && now.Add(a.clockSkew).Before(time.Unix(iat, 0)) {
+		return connect.NewError(
+			connect.CodeUnauthenticated,
+			errors.New("token issued in the future"),
+		)
+	}
+	return nil
+}
+
+// OpenIDConfiguration returns the OIDC configuration for the given base URL.
+// The issuer is the provider's issuer, since it issues the tokens; the token
+// and JWKS endpoints are served by the iLEAP server.
+func (a *AuthHandler) OpenIDConfiguration(baseURL string) *ileap.OpenIDConfiguration {
+	issuer := baseURL
+	if md, err := a.discover(context.Background()); err == nil {
+		issuer = md.Issuer
+	}
+	return &ileap.OpenIDConfiguration{
+		IssuerURL:              issuer,
+		AuthURL:                baseURL + "/auth/token",
+		TokenURL:               baseURL + "/auth/token",
+		JWKSURL:                baseURL + "/jwks",
+		Algorithms:             a.algorithms,
+		ResponseTypesSupported: []string{"token"},
+		SubjectTypesSupported:  []string{"public"},
+	}
+}
+
+// JWKS returns the provider's JSON Web Key Set.
+func (a *AuthHandler) JWKS() *ileap.JWKSet {
+	ctx := context.Background()
+	md, err := a.discover(ctx)
+	if err != nil {
+		return &ileap.JWKSet{}
+	}
+	jwks, err := a.refreshJWKS(ctx, md, false)
+	if err != nil && jwks == nil {
+		return &ileap.JWKSet{}
+	}
+	return jwks
+}
+
+// discover returns the provider metadata, fetching it on first use. A failed
+// fetch is retried at most once per minimum refresh interval.
+func (a *AuthHandler) discover(ctx context.Context) (*providerMetadata, error) {
+	a.mu.Lock()
+	md, err := a.metadata, a.metadataErr
+	failedRecently := time.Since(a.metadataFailedAt) < a.jwksMinRefreshInterval
+	a.mu.Unlock()
+	if md != nil {
+		return md, nil
+	}
+	if err != nil && failedRecently {
+		return nil, err
+	}
+	v, err := a.fetchOnce(ctx, "metadata", func(ctx context.Context) (any, error) {
+		md, err := fetchProviderMetadata(ctx, a.httpClient, a.issuer)
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if err != nil {
+			a.metadataErr, a.metadataFailedAt = err, time.Now()
+			return nil, err
+		}
+		a.metadata, a.metadataErr = md, nil
+		return md, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*providerMetadata), nil
+}
+
+// findKeys returns the keys that can verify a token with the key ID and
+// algorithm. A token with an unknown key ID triggers a JWKS fetch, to pick up
+// rotated keys, at most once per minimum refresh interval.
+func (a *AuthHandler) findKeys(
+	ctx context.Context,
+	md *providerMetadata,
+	kid, alg string,
+) ([]ileap.JWK, error) {
+	jwks, err := a.refreshJWKS(ctx, md, false)
+	if err != nil && jwks == nil {
+		return nil, err
+	}
+	keys := matchingKeys(jwks, kid, alg)
+	if len(keys) > 0 || kid == "" {
+		return keys, nil
+	}
+	jwks, err = a.refreshJWKS(ctx, md, true)
+	if err != nil {
+		return nil, err
+	}
+	keys = matchingKeys(jwks, kid, alg)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key %q not found in JWKS", kid)
+	}
+	return keys, nil
+}
+
+// refreshJWKS returns the cached JWKS, fetching it if the cache is stale or,
+// when forced, if the minimum refresh interval since the last forced fetch
+// has passed. A failed fetch is retried at most once per minimum refresh
+// interval, and the stale JWKS is returned along with the error.
+func (a *AuthHandler) refreshJWKS(
+	ctx context.Context,
+	md *providerMetadata,
+	force bool,
+) (*ileap.JWKSet, error) {
+	a.mu.Lock()
+	jwks := a.jwks
+	switch {
+	case !force && jwks != nil && time.Since(a.jwksFetchedAt) < a.jwksCacheTTL:
+		a.mu.Unlock()
+		return jwks, nil
+	case a.jwksErr != nil && time.Since(a.jwksFailedAt) < a.jwksMinRefreshInterval:
+		err := a.jwksErr
+		a.mu.Unlock()
+		return jwks, err
+	case force && time.Since(a.jwksForcedAt) < a.jwksMinRefreshInterval:
+		a.mu.Unlock()
+		return jwks, nil
+	}
+	if force {
+		a.jwksForcedAt = time.Now()
+	}
+	a.mu.Unlock()
+	v, err := a.fetchOnce(ctx, "jwks", func(ctx context.Context) (any, error) {
+		jwks, err := fetchJWKS(ctx, a.httpClient, md.JWKSURI)
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if err != nil {
+			a.jwksErr, a.jwksFailedAt = err, time.Now()
+			return a.jwks, err
+		}
+		a.jwks, a.jwksFetchedAt, a.jwksErr = jwks, time.Now(), nil
+		return jwks, nil
+	})
+	if v, ok := v.(*ileap.JWKSet); ok {
+		jwks = v
+	}
+	return jwks, err
+}
+
+// fetchOnce calls fetch without holding the mutex, sharing the call between
+// concurrent callers with the same key. The fetch is not canceled when the
+// context of a caller is done, since other callers may wait for it.
+func (a *AuthHandler) fetchOnce(
+	ctx context.Context,
+	key string,
+	fetch func(context.Context) (any, error),
+) (any, error) {
+	results := a.group.DoChan(key, func() (any, error) {
+		return fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		return result.Val, result.Err
+	}
+}
+
+func matchingKeys(jwks *ileap.JWKSet, kid, alg string) []ileap.JWK {
+	if jwks == nil {
+		return nil
+	}
+	var keys []ileap.JWK
+	for _, jwk := range jwks.Keys {
+		if kid != "" && jwk.KeyID != kid {
+			continue
+		}
+		if jose.KeyMatches(jwk, alg) {
+			keys = append(keys, jwk)
+		}
+	}
+	return keys
+}
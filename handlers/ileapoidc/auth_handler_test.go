@@ -0,0 +1,621 @@
+package ileapoidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go"
+)
+
+// testIssuer is a stand-in OpenID Connect provider.
+type testIssuer struct {
+	t              *testing.T
+	server         *httptest.Server
+	discoveryHits  atomic.Int32
+	discoveryError atomic.Int32
+	jwksHits       atomic.Int32
+	jwksError      atomic.Int32
+	tokenHits      atomic.Int32
+	tokenError     atomic.Int32
+
+	mu   sync.Mutex
+	keys []*testKey
+}
+
+type testKey struct {
+	kid    string
+	alg    string
+	signer crypto.Signer
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	iss := &testIssuer{t: t}
+	mux := http.NewServeMux()
+	mux.HandleFunc(
+		"GET /.well-known/openid-configuration",
+		func(w http.ResponseWriter, _ *http.Request) {
+			iss.discoveryHits.Add(1)
+			if status := iss.discoveryError.Load(); status != 0 {
+				w.WriteHeader(int(status))
+				return
+			}
+			writeTestJSON(w, map[string]any{
+				"issuer":         iss.server.URL,
+				"token_endpoint": iss.server.URL + "/token",
+				"jwks_uri":       iss.server.URL + "/keys",
+			})
+		},
+	)
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, _ *http.Request) {
+		iss.jwksHits.Add(1)
+		if status := iss.jwksError.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		iss.mu.Lock()
+		defer iss.mu.Unlock()
+		var jwks ileap.JWKSet
+		for _, key := range iss.keys {
+			jwks.Keys = append(jwks.Keys, testJWK(t, key))
+		}
+		writeTestJSON(w, jwks)
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		iss.tokenHits.Add(1)
+		if status := iss.tokenError.Load(); status != 0 {
+			w.WriteHeader(int(status))
+			return
+		}
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || r.FormValue("grant_type") != "client_credentials" ||
+			clientID != "client" || clientSecret != "secret" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		writeTestJSON(w, map[string]any{
+			"access_token": iss.sign(iss.claims(time.Hour)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"scope":        r.FormValue("scope"),
+		})
+	})
+	iss.server = httptest.NewServer(mux)
+	t.Cleanup(iss.server.Close)
+	iss.rotate("RS256")
+	return iss
+}
+
+// rotate replaces the signing key with a new key for the algorithm.
+func (iss *testIssuer) rotate(alg string) *testKey {
+	iss.t.Helper()
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case "RS256", "RS384", "PS256":
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "ES384":
+		signer, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case "EdDSA":
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		iss.t.Fatalf("unsupported algorithm %s", alg)
+	}
+	if err != nil {
+		iss.t.Fatalf("generate key: %v", err)
+	}
+	iss.mu.Lock()
+	defer iss.mu.Unlock()
+	key := &testKey{kid: alg + "-" + time.Now().Format(time.RFC3339Nano), alg: alg, signer: signer}
+	iss.keys = []*testKey{key}
+	return key
+}
+
+func (iss *testIssuer) claims(lifetime time.Duration) map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss": iss.server.URL,
+		"sub": "client",
+		"aud": []string{"ileap", "other"},
+		"iat": now.Unix(),
+		"exp": now.Add(lifetime).Unix(),
+	}
+}
+
+// sign signs the claims with the current key of the issuer.
+func (iss *testIssuer) sign(claims map[string]any) string {
+	iss.mu.Lock()
+	key := iss.keys[0]
+	iss.mu.Unlock()
+	return signTestJWT(
+		iss.t,
+		key,
+		map[string]any{"alg": key.alg, "kid": key.kid, "typ": "JWT"},
+		claims,
+	)
+}
+
+func signTestJWT(t *testing.T, key *testKey, header, claims map[string]any) string {
+	t.Helper()
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := base64.RawURLEncoding.EncodeToString(headerBytes) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsBytes)
+	var signature []byte
+	switch key.alg {
+	case "RS256":
+		digest := hash(crypto.SHA256, input)
+		signature, err = rsa.SignPKCS1v15(
+			rand.Reader,
+			key.signer.(*rsa.PrivateKey),
+			crypto.SHA256,
+			digest,
+		)
+	case "RS384":
+		digest := hash(crypto.SHA384, input)
+		signature, err = rsa.SignPKCS1v15(
+			rand.Reader,
+			key.signer.(*rsa.PrivateKey),
+			crypto.SHA384,
+			digest,
+		)
+	case "PS256":
+		digest := hash(crypto.SHA256, input)
+		signature, err = rsa.SignPSS(
+			rand.Reader,
+			key.signer.(*rsa.PrivateKey),
+			crypto.SHA256,
+			digest,
+			&rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash},
+		)
+	case "ES256", "ES384":
+		h := crypto.SHA256
+		if key.alg == "ES384" {
+			h = crypto.SHA384
+		}
+		priv := key.signer.(*ecdsa.PrivateKey)
+		r, s, signErr := ecdsa.Sign(rand.Reader, priv, hash(h, input))
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		err = signErr
+	case "EdDSA":
+		signature = ed25519.Sign(key.signer.(ed25519.PrivateKey), []byte(input))
+	}
+	if err != nil {
+		t.Fatalf("sign JWT: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func hash(h crypto.Hash, input string) []byte {
+	w := h.New()
+	w.Write([]byte(input))
+	return w.Sum(nil)
+}
+
+func testJWK(t *testing.T, key *testKey) ileap.JWK {
+	t.Helper()
+	jwk := ileap.JWK{Use: "sig", Algorithm: key.alg, KeyID: key.kid}
+	switch pub := key.signer.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		t.Fatalf("unsupported key type %T", pub)
+	}
+	return jwk
+}
+
+func writeTestJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestAuthHandler_IssueToken(t *testing.T) {
+	iss := newTestIssuer(t)
+	a := NewAuthHandler(iss.server.URL)
+
+	t.Run("valid credentials", func(t *testing.T) {
+		creds, err := a.IssueToken(t.Context(), "client", "secret")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if creds.AccessToken == "" {
+			t.Fatal("expected access token")
+		}
+		if creds.ExpiresIn <= 0 || creds.ExpiresIn > 3600 {
+			t.Errorf("got expires_in %d, want (0, 3600]", creds.ExpiresIn)
+		}
+		info, err := a.ValidateToken(t.Context(), creds.AccessToken)
+		if err != nil {
+			t.Fatalf("validate issued token: %v", err)
+		}
+		if info.Subject != "client" {
+			t.Errorf("got subject %q, want %q", info.Subject, "client")
+		}
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		_, err := a.IssueToken(t.Context(), "client", "wrong")
+		if got := connect.CodeOf(err); got != connect.CodePermissionDenied {
+			t.Errorf("got code %v, want %v", got, connect.CodePermissionDenied)
+		}
+	})
+
+	t.Run("provider unavailable", func(t *testing.T) {
+		iss.tokenError.Store(http.StatusServiceUnavailable)
+		defer iss.tokenError.Store(0)
+		_, err := a.IssueToken(t.Context(), "client", "secret")
+		if got := connect.CodeOf(err); got != connect.CodeUnavailable {
+			t.Errorf("got code %v, want %v", got, connect.CodeUnavailable)
+		}
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		iss.tokenError.Store(http.StatusTooManyRequests)
+		defer iss.tokenError.Store(0)
+		_, err := a.IssueToken(t.Context(), "client", "secret")
+		if got := connect.CodeOf(err); got != connect.CodeResourceExhausted {
+			t.Errorf("got code %v, want %v", got, connect.CodeResourceExhausted)
+		}
+	})
+}
+
+func TestAuthHandler_ValidateToken_Algorithms(t *testing.T) {
+	for _, alg := range []string{"RS256", "RS384", "PS256", "ES256", "EdDSA"} {
+		t.Run(alg, func(t *testing.T) {
+			iss := newTestIssuer(t)
+			iss.rotate(alg)
+			a := NewAuthHandler(iss.server.URL)
+			if _, err := a.ValidateToken(t.Context(), iss.sign(iss.claims(time.Hour))); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ValidateToken(t *testing.T) {
+	iss := newTestIssuer(t)
+	key := iss.rotate("ES256")
+	claims := func(modify func(map[string]any)) map[string]any {
+		c := iss.claims(time.Hour)
+		modify(c)
+		return c
+	}
+	header := map[string]any{"alg": "ES256", "kid": key.kid}
+	tests := []struct {
+		name     string
+		token    string
+		opts     []AuthHandlerOption
+		wantErr  bool
+		wantCode connect.Code
+	}{
+		{
+			name:  "valid",
+			token: iss.sign(iss.claims(time.Hour)),
+			opts:  []AuthHandlerOption{WithAudience("ileap")},
+		},
+		{
+			name:  "token without kid",
+			token: signTestJWT(t, key, map[string]any{"alg": "ES256"}, iss.claims(time.Hour)),
+		},
+		{
+			name:     "expired",
+			token:    iss.sign(iss.claims(-2 * time.Minute)),
+			wantErr:  true,
+			wantCode: connect.CodeUnauthenticated,
+		},
+		{
+			name:  "expired within clock skew",
+			token: iss.sign(iss.claims(-30 * time.Second)),
+		},
+		{
+			name:     "expired with zero clock skew",
+			token:    iss.sign(iss.claims(-30 * time.Second)),
+			opts:     []AuthHandlerOption{WithClockSkew(0)},
+			wantErr:  true,
+			wantCode: connect.CodeUnauthenticated,
+		},
+		{
+			name: "not yet valid",
+			token: iss.sign(claims(func(c map[string]any) {
+				c["nbf"] = time.Now().Add(time.Hour).Unix()
+			})),
+			wantErr:  true,
+			wantCode: connect.CodeUnauthenticated,
+		},
+		{
+			name:    "missing exp",
+			token:   iss.sign(claims(func(c map[string]any) { delete(c, "exp") })),
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: iss.sign(
+				claims(func(c map[string]any) { c["iss"] = "https://evil.example.com" }),
+			),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   iss.sign(iss.claims(time.Hour)),
+			opts:    []AuthHandlerOption{WithAudience("someone-else")},
+			wantErr: true,
+		},
+		{
+			name:    "missing sub",
+			token:   iss.sign(claims(func(c map[string]any) { delete(c, "sub") })),
+			wantErr: true,
+		},
+		{
+			name:    "algorithm not allowed",
+			token:   iss.sign(iss.claims(time.Hour)),
+			opts:    []AuthHandlerOption{WithAlgorithms("RS256")},
+			wantErr: true,
+		},
+		{
+			name:    "alg none",
+			token:   signTestJWT(t, key, map[string]any{"alg": "none"}, iss.claims(time.Hour)),
+			wantErr: true,
+		},
+		{
+			name: "tampered claims",
+			token: func() string {
+				valid := iss.sign(iss.claims(time.Hour))
+				other := signTestJWT(
+					t,
+					key,
+					header,
+					claims(func(c map[string]any) { c["sub"] = "admin" }),
+				)
+				return other[:len(other)-len(signaturePart(other))] + signaturePart(valid)
+			}(),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAuthHandler(iss.server.URL, tt.opts...)
+			info, err := a.ValidateToken(t.Context(), tt.token)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if info.Subject != "client" {
+					t.Errorf("got subject %q, want %q", info.Subject, "client")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantCode != 0 && connect.CodeOf(err) != tt.wantCode {
+				t.Errorf("got code %v, want %v", connect.CodeOf(err), tt.wantCode)
+			}
+			if tt.wantCode == 0 && connect.CodeOf(err) == connect.CodeUnauthenticated {
+				t.Errorf("got code %v for invalid token", connect.CodeOf(err))
+			}
+		})
+	}
+}
+
+func signaturePart(token string) string {
+	for i := len(token) - 1; i >= 0; i-- {
+		if token[i] == '.' {
+			return token[i+1:]
+		}
+	}
+	return ""
+}
+
+func TestAuthHandler_ValidateToken_KeyRotation(t *testing.T) {
+	iss := newTestIssuer(t)
+	a := NewAuthHandler(iss.server.URL, WithJWKSMinRefreshInterval(time.Hour))
+	if _, err := a.ValidateToken(t.Context(), iss.sign(iss.claims(time.Hour))); err != nil {
+		t.Fatalf("validate token: %v", err)
+	}
+	if got := iss.jwksHits.Load(); got != 1 {
+		t.Fatalf("got %d JWKS fetches, want 1", got)
+	}
+	iss.rotate("ES256")
+	if _, err := a.ValidateToken(t.Context(), iss.sign(iss.claims(time.Hour))); err != nil {
+		t.Fatalf("validate token signed with rotated key: %v", err)
+	}
+	if got := iss.jwksHits.Load(); got != 2 {
+		t.Errorf("got %d JWKS fetches, want 2", got)
+	}
+	// Unknown key IDs do not trigger another fetch within the refresh interval.
+	unknown := &testKey{kid: "unknown", alg: "EdDSA"}
+	_, unknown.signer, _ = ed25519.GenerateKey(rand.Reader)
+	token := signTestJWT(
+		t,
+		unknown,
+		map[string]any{"alg": "EdDSA", "kid": "unknown"},
+		iss.claims(time.Hour),
+	)
+	if _, err := a.ValidateToken(t.Context(), token); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+	if got := iss.jwksHits.Load(); got != 2 {
+		t.Errorf("got %d JWKS fetches, want 2", got)
+	}
+}
+
+func TestAuthHandler_ValidateToken_ConcurrentFetch(t *testing.T) {
+	iss := newTestIssuer(t)
+	a := NewAuthHandler(iss.server.URL)
+	token := iss.sign(iss.claims(time.Hour))
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := a.ValidateToken(t.Context(), token); err != nil {
+				t.Errorf("validate token: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+	if got := iss.discoveryHits.Load(); got != 1 {
+		t.Errorf("got %d discovery fetches, want 1", got)
+	}
+	if got := iss.jwksHits.Load(); got != 1 {
+		t.Errorf("got %d JWKS fetches, want 1", got)
+	}
+}
+
+func TestAuthHandler_ValidateToken_FetchFailure(t *testing.T) {
+	t.Run("discovery", func(t *testing.T) {
+		iss := newTestIssuer(t)
+		iss.discoveryError.Store(http.StatusInternalServerError)
+		a := NewAuthHandler(iss.server.URL, WithJWKSMinRefreshInterval(time.Hour))
+		token := iss.sign(iss.claims(time.Hour))
+		for range 2 {
+			if _, err := a.ValidateToken(t.Context(), token); err == nil {
+				t.Fatal("expected error for failed discovery")
+			}
+		}
+		if got := iss.discoveryHits.Load(); got != 1 {
+			t.Errorf("got %d discovery fetches, want 1", got)
+		}
+	})
+
+	t.Run("jwks", func(t *testing.T) {
+		iss := newTestIssuer(t)
+		iss.jwksError.Store(http.StatusInternalServerError)
+		a := NewAuthHandler(iss.server.URL, WithJWKSMinRefreshInterval(time.Hour))
+		token := iss.sign(iss.claims(time.Hour))
+		for range 2 {
+			if _, err := a.ValidateToken(t.Context(), token); err == nil {
+				t.Fatal("expected error for failed JWKS fetch")
+			}
+		}
+		if got := iss.jwksHits.Load(); got != 1 {
+			t.Errorf("got %d JWKS fetches, want 1", got)
+		}
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		iss := newTestIssuer(t)
+		iss.jwksError.Store(http.StatusInternalServerError)
+		a := NewAuthHandler(iss.server.URL, WithJWKSMinRefreshInterval(0))
+		token := iss.sign(iss.claims(time.Hour))
+		if _, err := a.ValidateToken(t.Context(), token); err == nil {
+			t.Fatal("expected error for failed JWKS fetch")
+		}
+		iss.jwksError.Store(0)
+		if _, err := a.ValidateToken(t.Context(), token); err != nil {
+			t.Errorf("validate token after recovery: %v", err)
+		}
+	})
+}
+
+func TestAuthHandler_DiscoveryIssuerMismatch(t *testing.T) {
+	iss := newTestIssuer(t)
+	a := NewAuthHandler(iss.server.URL + "/")
+	_, err := a.IssueToken(t.Context(), "client", "secret")
+	if got := connect.CodeOf(err); got != connect.CodeUnavailable {
+		t.Errorf("got code %v, want %v", got, connect.CodeUnavailable)
+	}
+	if got := iss.tokenHits.Load(); got != 0 {
+		t.Errorf("got %d token requests, want 0", got)
+	}
+}
+
+func TestAuthHandler_OpenIDConfiguration(t *testing.T) {
+	iss := newTestIssuer(t)
+	a := NewAuthHandler(iss.server.URL, WithAlgorithms("ES256"))
+	cfg := a.OpenIDConfiguration("https://ileap.example.com")
+	if cfg.IssuerURL != iss.server.URL {
+		t.Errorf("got issuer %q, want %q", cfg.IssuerURL, iss.server.URL)
+	}
+	if cfg.TokenURL != "https://ileap.example.com/auth/token" {
+		t.Errorf("got token URL %q", cfg.TokenURL)
+	}
+	if cfg.JWKSURL != "https://ileap.example.com/jwks" {
+		t.Errorf("got JWKS URL %q", cfg.JWKSURL)
+	}
+	if len(cfg.Algorithms) != 1 || cfg.Algorithms[0] != "ES256" {
+		t.Errorf("got algorithms %v, want [ES256]", cfg.Algorithms)
+	}
+}
+
+func TestAuthHandler_JWKS(t *testing.T) {
+	iss := newTestIssuer(t)
+	key := iss.rotate("EdDSA")
+	a := NewAuthHandler(iss.server.URL)
+	jwks := a.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != key.kid || jwks.Keys[0].Curve != "Ed25519" {
+		t.Errorf("got JWKS %+v, want the issuer's key", jwks)
+	}
+	a.JWKS()
+	if got := iss.jwksHits.Load(); got != 1 {
+		t.Errorf("got %d JWKS fetches, want 1", got)
+	}
+}
+
+func TestAuthHandler_ValidateToken_TokenInfo(t *testing.T) {
+	iss := newTestIssuer(t)
+	claims := iss.claims(time.Hour)
+	claims["scope"] = "footprints:read tad:read"
+	claims["tenant"] = "acme"
+	claims["cnf"] = map[string]any{"x5t#S256": "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"}
+	a := NewAuthHandler(iss.server.URL, WithOrganizationClaim("tenant"))
+	info, err := a.ValidateToken(t.Context(), iss.sign(claims))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !info.HasScope("footprints:read") || !info.HasScope("tad:read") || len(info.Scopes) != 2 {
+		t.Errorf("got scopes %v", info.Scopes)
+	}
+	if len(info.Audience) != 2 || info.Audience[0] != "ileap" {
+		t.Errorf("got audience %v", info.Audience)
+	}
+	if info.Organization != "acme" {
+		t.Errorf("got organization %q, want %q", info.Organization, "acme")
+	}
+	if info.Claims["tenant"] != "acme" {
+		t.Errorf("got claims %v", info.Claims)
+	}
+	if want := "bwcK0esc3ACC3DB2Y5_lESsXE8o9ltc05O89jdN-dg2"; info.CertificateThumbprint != want {
+		t.Errorf("got certificate thumbprint %q, want %q", info.CertificateThumbprint, want)
+	}
+}
@@ -0,0 +1,81 @@
+package ileapoidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/way-platform/ileap-go"
+)
+
+// providerMetadata is the subset of the OpenID Provider Metadata used by the
+// handler.
+type providerMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// fetchProviderMetadata fetches the OpenID Provider Metadata of an issuer.
+func fetchProviderMetadata(
+	ctx context.Context,
+	httpClient *http.Client,
+	issuer string,
+) (*providerMetadata, error) {
+	endpoint := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	var md providerMetadata
+	if err := getJSON(ctx, httpClient, endpoint, &md); err != nil {
+		return nil, fmt.Errorf("fetch OpenID configuration: %w", err)
+	}
+	// The issuer must match exactly (OpenID Connect Discovery 1.0, section 4.3).
+	if md.Issuer != issuer {
+		return nil, fmt.Errorf(
+			"OpenID configuration issuer %q does not match %q",
+			md.Issuer,
+			issuer,
+		)
+	}
+	if md.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OpenID configuration has no token_endpoint")
+	}
+	if md.JWKSURI == "" {
+		return nil, fmt.Errorf("OpenID configuration has no jwks_uri")
+	}
+	return &md, nil
+}
+
+// fetchJWKS fetches a JSON Web Key Set.
+func fetchJWKS(
+	ctx context.Context,
+	httpClient *http.Client,
+	jwksURI string,
+) (*ileap.JWKSet, error) {
+	var jwks ileap.JWKSet
+	if err := getJSON(ctx, httpClient, jwksURI, &jwks); err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	return &jwks, nil
+}
+
+func getJSON(ctx context.Context, httpClient *http.Client, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
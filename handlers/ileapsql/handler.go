@@ -0,0 +1,374 @@
+// Package ileapsql provides an ILeapServiceHandler backed by a SQL database.
+//
+// The [Handler] stores footprints and TADs as protojson, with frequently
+// filtered fields projected into indexed columns. Filters on projected fields
+// are translated into parameterised WHERE clauses; all other filters are
+// evaluated on the query results with [ileapfilter]. Footprints are stored
+// per version, and only the latest version of each footprint is returned.
+//
+// The handler works with any [database/sql] driver for SQLite or PostgreSQL.
+package ileapsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go/ileapfilter"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var _ ileapv1connect.ILeapServiceHandler = (*Handler)(nil)
+
+// Option configures a [Handler].
+type Option func(*Handler)
+
+// WithDialect sets the SQL dialect of the database. Defaults to [SQLite].
+func WithDialect(dialect Dialect) Option {
+	return func(h *Handler) { h.dialect = dialect }
+}
+
+// Handler implements ILeapServiceHandler on top of a SQL database.
+type Handler struct {
+	ileapv1connect.UnimplementedILeapServiceHandler
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewHandler creates a new [Handler] for the database.
+// Call [Handler.CreateSchema] to create the tables before first use.
+func NewHandler(db *sql.DB, opts ...Option) *Handler {
+	h := &Handler{db: db}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// CreateSchema creates the tables and indexes of the handler, if they do
+// not exist yet.
+func (h *Handler) CreateSchema(ctx context.Context) error {
+	for _, statement := range schema {
+		if _, err := h.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// PutFootprint stores a version of a footprint, replacing a stored footprint
+// with the same ID and version.
+func (h *Handler) PutFootprint(ctx context.Context, pf *ileapv1.ProductFootprint) error {
+	if pf.GetId() == "" {
+		return fmt.Errorf("put footprint: empty ID")
+	}
+	err := h.put(ctx, footprintsTable, pf, footprintRow(pf), footprintValues(pf), []string{
+		"id", "version", "status", "created", "updated",
+		"product_category_cpc", "geography_country",
+	}, 2)
+	if err != nil {
+		return fmt.Errorf("put footprint %s: %w", pf.GetId(), err)
+	}
+	return nil
+}
+
+// PutTAD stores a TAD, replacing a stored TAD with the same activity ID.
+func (h *Handler) PutTAD(ctx context.Context, tad *ileapv1.TAD) error {
+	if tad.GetActivityId() == "" {
+		return fmt.Errorf("put TAD: empty activity ID")
+	}
+	err := h.put(ctx, tadsTable, tad, tadRow(tad), tadValues(tad), []string{
+		"id", "mode", "packaging_or_tr_eq_type", "departure_at", "arrival_at",
+	}, 1)
+	if err != nil {
+		return fmt.Errorf("put TAD %s: %w", tad.GetActivityId(), err)
+	}
+	return nil
+}
+
+// put upserts a message row and replaces its values. The first keys columns
+// identify the message.
+func (h *Handler) put(
+	ctx context.Context,
+	t *table,
+	msg proto.Message,
+	row []any,
+	values map[string][]string,
+	columns []string,
+	keys int,
+) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	q := &query{dialect: h.dialect}
+	placeholders := make([]string, 0, len(row)+1)
+	for _, v := range row {
+		placeholders = append(placeholders, q.arg(v))
+	}
+	placeholders = append(placeholders, q.arg(string(data)))
+	updates := make([]string, 0, len(columns)-keys+1)
+	for _, name := range append(columns[keys:], "data") {
+		updates = append(updates, name+" = excluded."+name)
+	}
+	statement := "INSERT INTO " + t.name +
+		" (" + strings.Join(columns, ", ") + ", data)" +
+		" VALUES (" + strings.Join(placeholders, ", ") + ")" +
+		" ON CONFLICT (" + strings.Join(columns[:keys], ", ") + ")" +
+		" DO UPDATE SET " + strings.Join(updates, ", ")
+	if _, err := tx.ExecContext(ctx, statement, q.args...); err != nil {
+		return err
+	}
+	q = &query{dialect: h.dialect}
+	conditions := make([]string, 0, keys)
+	for i, name := range columns[:keys] {
+		conditions = append(conditions, name+" = "+q.arg(row[i]))
+	}
+	statement = "DELETE FROM " + t.valuesTable + " WHERE " + strings.Join(conditions, " AND ")
+	if _, err := tx.ExecContext(ctx, statement, q.args...); err != nil {
+		return err
+	}
+	for field, elements := range values {
+		for _, element := range elements {
+			q = &query{dialect: h.dialect}
+			placeholders := make([]string, 0, keys+2)
+			for _, v := range row[:keys] {
+				placeholders = append(placeholders, q.arg(v))
+			}
+			placeholders = append(placeholders, q.arg(field), q.arg(element))
+			statement := "INSERT INTO " + t.valuesTable +
+				" (" + strings.Join(columns[:keys], ", ") + ", field, value) VALUES (" +
+				strings.Join(placeholders, ", ") + ")"
+			if _, err := tx.ExecContext(ctx, statement, q.args...); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFootprint returns the latest version of a footprint by ID.
+func (h *Handler) GetFootprint(
+	ctx context.Context, req *ileapv1.GetFootprintRequest,
+) (*ileapv1.GetFootprintResponse, error) {
+	q := &query{dialect: h.dialect}
+	statement := "SELECT data FROM ileap_footprints WHERE id = " + q.arg(req.GetId()) +
+		" ORDER BY version DESC LIMIT 1"
+	var data string
+	err := h.db.QueryRowContext(ctx, statement, q.args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get footprint: %w", err)
+	}
+	pf := new(ileapv1.ProductFootprint)
+	if err := unmarshal(data, pf); err != nil {
+		return nil, fmt.Errorf("get footprint: %w", err)
+	}
+	resp := new(ileapv1.GetFootprintResponse)
+	resp.SetData(pf)
+	return resp, nil
+}
+
+// ListFootprints returns a filtered, paginated list of the latest version of
+// each footprint.
+func (h *Handler) ListFootprints(
+	ctx context.Context, req *ileapv1.ListFootprintsRequest,
+) (*ileapv1.ListFootprintsResponse, error) {
+	data, total, err := list(
+		ctx,
+		h,
+		footprintsTable,
+		filterExpression(req.GetFilter(), req.GetFilters()),
+		int(req.GetLimit()),
+		int(req.GetOffset()),
+		func() *ileapv1.ProductFootprint { return new(ileapv1.ProductFootprint) },
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list footprints: %w", filterError(err, connect.CodeUnimplemented))
+	}
+	resp := new(ileapv1.ListFootprintsResponse)
+	resp.SetData(data)
+	resp.SetTotal(int32(total))
+	return resp, nil
+}
+
+// ListTransportActivityData returns a filtered, paginated list of TADs.
+func (h *Handler) ListTransportActivityData(
+	ctx context.Context, req *ileapv1.ListTransportActivityDataRequest,
+) (*ileapv1.ListTransportActivityDataResponse, error) {
+	data, total, err := list(
+		ctx,
+		h,
+		tadsTable,
+		filterExpression(nil, req.GetFilters()),
+		int(req.GetLimit()),
+		int(req.GetOffset()),
+		func() *ileapv1.TAD { return new(ileapv1.TAD) },
+	)
+	if err != nil {
+		// TAD filters are arbitrary query parameters, so filters on unknown
+		// fields are invalid arguments rather than unsupported filters.
+		return nil, fmt.Errorf("list TADs: %w", filterError(err, connect.CodeInvalidArgument))
+	}
+	resp := new(ileapv1.ListTransportActivityDataResponse)
+	resp.SetData(data)
+	resp.SetTotal(int32(total))
+	return resp, nil
+}
+
+// list returns a page of the messages that match the expression, and the
+// total number of matching messages.
+//
+// If the expression translates exactly into SQL, the database paginates and
+// counts the results within one read-only transaction, so that the total is
+// consistent with the page. Otherwise, the candidates selected by the
+// database are filtered and paginated in memory.
+//
+// Errors of evaluating the expression are returned unmapped, see
+// [filterError].
+func list[T proto.Message](
+	ctx context.Context,
+	h *Handler,
+	t *table,
+	expr *ileapv1.FilterExpression,
+	limit, offset int,
+	newMessage func() T,
+) ([]T, int, error) {
+	q := &query{dialect: h.dialect}
+	var conditions []string
+	if t.latest != "" {
+		conditions = append(conditions, t.latest)
+	}
+	exact := true
+	if expr != nil {
+		var condition string
+		condition, exact = q.where(t, expr)
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	from := " FROM " + t.name + " m"
+	if len(conditions) > 0 {
+		from += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if !exact {
+		msgs, err := queryMessages(
+			ctx,
+			h.db,
+			"SELECT m.data"+from+" ORDER BY "+t.orderBy,
+			q.args,
+			newMessage,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		msgs, err = ileapfilter.FilterExpression(msgs, expr)
+		if err != nil {
+			return nil, 0, err
+		}
+		total := len(msgs)
+		msgs = msgs[min(offset, total):]
+		if limit > 0 && len(msgs) > limit {
+			msgs = msgs[:limit]
+		}
+		return msgs, total, nil
+	}
+	opts := &sql.TxOptions{ReadOnly: true}
+	if h.dialect == Postgres {
+		// Statements of a read committed transaction see different snapshots.
+		opts.Isolation = sql.LevelRepeatableRead
+	}
+	tx, err := h.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	var total int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*)"+from, q.args...).
+		Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	statement := "SELECT m.data" + from + " ORDER BY " + t.orderBy
+	switch {
+	case limit > 0:
+		statement += " LIMIT " + q.arg(limit) + " OFFSET " + q.arg(offset)
+	case offset > 0 && h.dialect == SQLite:
+		statement += " LIMIT -1 OFFSET " + q.arg(offset)
+	case offset > 0:
+		statement += " OFFSET " + q.arg(offset)
+	}
+	msgs, err := queryMessages(ctx, tx, statement, q.args, newMessage)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return msgs, total, nil
+}
+
+// queryer runs queries on a [*sql.DB] or within a [*sql.Tx].
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// queryMessages runs a statement selecting the data column and decodes the
+// messages.
+func queryMessages[T proto.Message](
+	ctx context.Context,
+	db queryer,
+	statement string,
+	args []any,
+	newMessage func() T,
+) ([]T, error) {
+	rows, err := db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var msgs []T
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		msg := newMessage()
+		if err := unmarshal(data, msg); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, rows.Err()
+}
+
+func unmarshal(data string, msg proto.Message) error {
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal([]byte(data), msg)
+}
+
+// filterError maps an error of evaluating filters to a Connect error, with
+// the given code for filters on unknown fields.
+func filterError(err error, unknownField connect.Code) error {
+	switch {
+	case errors.Is(err, ileapfilter.ErrInvalidValue):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, ileapfilter.ErrUnknownField):
+		return connect.NewError(unknownField, err)
+	case errors.Is(err, ileapfilter.ErrUnsupported):
+		return connect.NewError(connect.CodeUnimplemented, err)
+	default:
+		return err
+	}
+}
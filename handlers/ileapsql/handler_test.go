@@ -0,0 +1,357 @@
+package ileapsql
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/way-platform/ileap-go/handlers/ileapdemo"
+	"github.com/way-platform/ileap-go/ileapfilter"
+	"github.com/way-platform/ileap-go/internal/odata"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to an in-memory database opens a new database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	h := NewHandler(db)
+	if err := h.CreateSchema(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func loadTestData(t *testing.T, h *Handler) ([]*ileapv1.ProductFootprint, []*ileapv1.TAD) {
+	t.Helper()
+	footprints, err := ileapdemo.LoadFootprints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pf := range footprints {
+		if err := h.PutFootprint(t.Context(), pf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tads, err := ileapdemo.LoadTADs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tad := range tads {
+		if err := h.PutTAD(t.Context(), tad); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return footprints, tads
+}
+
+func footprintIDs(footprints []*ileapv1.ProductFootprint) []string {
+	ids := make([]string, 0, len(footprints))
+	for _, pf := range footprints {
+		ids = append(ids, pf.GetId())
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestListFootprints(t *testing.T) {
+	h := newTestHandler(t)
+	footprints, _ := loadTestData(t, h)
+	for _, filter := range []string{
+		"",
+		"productCategoryCpc eq '83117'",
+		"productCategoryCpc ne '83117'",
+		"status eq 'active'",
+		"pcf/geographyCountry eq 'DE'",
+		"pcf/geographyCountry ne 'DE'",
+		"created ge 2022-05-01T00:00:00Z",
+		"created lt 2022-05-01",
+		"companyIds eq 'urn:epc:id:sgln:4063973.00000.8'",
+		"companyIds ne 'urn:epc:id:sgln:4063973.00000.8'",
+		"companyIds/any(c:c eq 'URN:EPC:ID:SGLN:6957976.00000.1')",
+		"productIds/any()",
+		"productCategoryCpc eq '4365' or pcf/geographyCountry eq 'DE'",
+		"productCategoryCpc in ('4365', '3342')",
+		"not (status eq 'Active')",
+		"created ge 2022-05-01T00:00:00Z and pcf/declaredUnit eq 'kilogram'",
+		"pcf/declaredUnit eq 'kilogram' or productCategoryCpc eq '4365'",
+		"pcf/pCfExcludingBiogenic gt 1",
+		"productCategoryCpc gt '5'",
+	} {
+		t.Run(filter, func(t *testing.T) {
+			req := new(ileapv1.ListFootprintsRequest)
+			var want []*ileapv1.ProductFootprint
+			if filter == "" {
+				want = footprints
+			} else {
+				expr, err := odata.ParseExpression(filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.SetFilter(expr)
+				want, err = ileapfilter.FilterExpression(footprints, expr)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			resp, err := h.ListFootprints(t.Context(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, wantIDs := footprintIDs(resp.GetData()), footprintIDs(want)
+			if !slices.Equal(got, wantIDs) {
+				t.Errorf("got %v, want %v", got, wantIDs)
+			}
+			if got, want := int(resp.GetTotal()), len(want); got != want {
+				t.Errorf("got total %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestListFootprints_Filters(t *testing.T) {
+	h := newTestHandler(t)
+	loadTestData(t, h)
+	filter := func(fieldPath, value string) *ileapv1.Filter {
+		f := new(ileapv1.Filter)
+		f.SetFieldPath(fieldPath)
+		f.SetOperator(ileapv1.Filter_EQ)
+		f.SetValue(value)
+		return f
+	}
+	req := new(ileapv1.ListFootprintsRequest)
+	req.SetFilters([]*ileapv1.Filter{
+		filter("productCategoryCpc", "4365"),
+		filter("status", "Active"),
+	})
+	resp, err := h.ListFootprints(t.Context(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := footprintIDs(resp.GetData())
+	if want := []string{"f369091a-aa5d-4248-9bd5-2812329e1ef1"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestListFootprints_Pagination(t *testing.T) {
+	h := newTestHandler(t)
+	footprints, _ := loadTestData(t, h)
+	all := footprintIDs(footprints)
+	for _, filter := range []string{
+		// Translated exactly, paginated by the database.
+		"status eq 'Active'",
+		// Filtered and paginated in memory.
+		"pcf/declaredUnit ne 'liter'",
+	} {
+		t.Run(filter, func(t *testing.T) {
+			expr, err := odata.ParseExpression(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pages []string
+			var total int32
+			for offset := int32(0); offset < int32(len(all))+5; offset += 5 {
+				req := new(ileapv1.ListFootprintsRequest)
+				req.SetFilter(expr)
+				req.SetLimit(5)
+				req.SetOffset(offset)
+				resp, err := h.ListFootprints(t.Context(), req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.GetData()) > 5 {
+					t.Fatalf("got page of %d, want at most 5", len(resp.GetData()))
+				}
+				for _, pf := range resp.GetData() {
+					pages = append(pages, pf.GetId())
+				}
+				total = resp.GetTotal()
+			}
+			want, err := ileapfilter.FilterExpression(footprints, expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A limit of 0 returns all matching footprints.
+			req := new(ileapv1.ListFootprintsRequest)
+			req.SetFilter(expr)
+			resp, err := h.ListFootprints(t.Context(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(resp.GetData()); got != len(want) {
+				t.Errorf("got %d footprints without limit, want %d", got, len(want))
+			}
+			if !slices.IsSorted(pages) {
+				t.Errorf("pages are not ordered: %v", pages)
+			}
+			if got, want := pages, footprintIDs(want); !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if int(total) != len(want) {
+				t.Errorf("got total %d, want %d", total, len(want))
+			}
+		})
+	}
+}
+
+func TestListFootprints_Errors(t *testing.T) {
+	h := newTestHandler(t)
+	loadTestData(t, h)
+	for _, tc := range []struct {
+		filter string
+		want   connect.Code
+	}{
+		{filter: "pcf/unknownField eq 'x'", want: connect.CodeUnimplemented},
+		{filter: "created gt 'yesterday'", want: connect.CodeInvalidArgument},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			expr, err := odata.ParseExpression(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := new(ileapv1.ListFootprintsRequest)
+			req.SetFilter(expr)
+			_, err = h.ListFootprints(t.Context(), req)
+			if got := connect.CodeOf(err); got != tc.want {
+				t.Errorf("got code %v, want %v (%v)", got, tc.want, err)
+			}
+		})
+	}
+}
+
+func TestFootprintVersions(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := t.Context()
+	put := func(version int32, status string) {
+		t.Helper()
+		pf := new(ileapv1.ProductFootprint)
+		pf.SetId("3a8f6bc0-7c9f-4b5e-9f2a-0c1d2e3f4a5b")
+		pf.SetVersion(version)
+		pf.SetStatus(status)
+		pf.SetCompanyIds([]string{"company-" + status})
+		if err := h.PutFootprint(ctx, pf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put(1, "Active")
+	put(2, "Deprecated")
+	// Replaces the stored version 2.
+	put(2, "Active")
+
+	get := new(ileapv1.GetFootprintRequest)
+	get.SetId("3a8f6bc0-7c9f-4b5e-9f2a-0c1d2e3f4a5b")
+	resp, err := h.GetFootprint(ctx, get)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetData().GetVersion(); got != 2 {
+		t.Errorf("got version %d, want 2", got)
+	}
+
+	expr, err := odata.ParseExpression("companyIds eq 'company-Deprecated'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := new(ileapv1.ListFootprintsRequest)
+	list.SetFilter(expr)
+	listResp, err := h.ListFootprints(ctx, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listResp.GetTotal() != 0 {
+		t.Errorf("got %d footprints with replaced values, want 0", listResp.GetTotal())
+	}
+	listResp, err = h.ListFootprints(ctx, new(ileapv1.ListFootprintsRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := listResp.GetData(); len(got) != 1 || !proto.Equal(got[0], resp.GetData()) {
+		t.Errorf("got %v, want only the latest version", got)
+	}
+}
+
+func TestGetFootprint_NotFound(t *testing.T) {
+	h := newTestHandler(t)
+	req := new(ileapv1.GetFootprintRequest)
+	req.SetId("does-not-exist")
+	_, err := h.GetFootprint(t.Context(), req)
+	if got := connect.CodeOf(err); got != connect.CodeNotFound {
+		t.Errorf("got code %v, want %v", got, connect.CodeNotFound)
+	}
+}
+
+func TestListTransportActivityData(t *testing.T) {
+	h := newTestHandler(t)
+	_, tads := loadTestData(t, h)
+	filter := func(fieldPath string, operator ileapv1.Filter_Operator, value string) *ileapv1.Filter {
+		f := new(ileapv1.Filter)
+		f.SetFieldPath(fieldPath)
+		f.SetOperator(operator)
+		f.SetValue(value)
+		return f
+	}
+	for _, tc := range []struct {
+		name    string
+		filters []*ileapv1.Filter
+	}{
+		{name: "all"},
+		{name: "mode", filters: []*ileapv1.Filter{filter("mode", ileapv1.Filter_EQ, "road")}},
+		{name: "activity ID", filters: []*ileapv1.Filter{filter("activityId", ileapv1.Filter_EQ, "3")}},
+		{
+			name:    "consignment IDs",
+			filters: []*ileapv1.Filter{filter("consignmentIds", ileapv1.Filter_NE, "4")},
+		},
+		{
+			name: "departure",
+			filters: []*ileapv1.Filter{
+				filter("departureAt", ileapv1.Filter_GT, "2025-06-24T15:06:29.687767398Z"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want, err := ileapfilter.Filter(tads, tc.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := new(ileapv1.ListTransportActivityDataRequest)
+			req.SetFilters(tc.filters)
+			resp, err := h.ListTransportActivityData(t.Context(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, wantIDs []string
+			for _, tad := range resp.GetData() {
+				got = append(got, tad.GetActivityId())
+			}
+			for _, tad := range want {
+				wantIDs = append(wantIDs, tad.GetActivityId())
+			}
+			slices.Sort(got)
+			slices.Sort(wantIDs)
+			if !slices.Equal(got, wantIDs) {
+				t.Errorf("got %v, want %v", got, wantIDs)
+			}
+			if int(resp.GetTotal()) != len(want) {
+				t.Errorf("got total %d, want %d", resp.GetTotal(), len(want))
+			}
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		req := new(ileapv1.ListTransportActivityDataRequest)
+		req.SetFilters([]*ileapv1.Filter{filter("unknownParam", ileapv1.Filter_EQ, "x")})
+		_, err := h.ListTransportActivityData(t.Context(), req)
+		if got := connect.CodeOf(err); got != connect.CodeInvalidArgument {
+			t.Errorf("got code %v, want %v (%v)", got, connect.CodeInvalidArgument, err)
+		}
+	})
+}
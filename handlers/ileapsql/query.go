@@ -0,0 +1,214 @@
+package ileapsql
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// query builds a parameterised SQL statement.
+type query struct {
+	dialect Dialect
+	args    []any
+}
+
+// arg adds an argument and returns its placeholder.
+func (q *query) arg(v any) string {
+	q.args = append(q.args, v)
+	if q.dialect == Postgres {
+		return "$" + strconv.Itoa(len(q.args))
+	}
+	return "?"
+}
+
+// filterExpression returns the expression of a list request, combining
+// filter pairs conjunctively if the request carries no expression.
+func filterExpression(
+	expr *ileapv1.FilterExpression,
+	filters []*ileapv1.Filter,
+) *ileapv1.FilterExpression {
+	if expr != nil || len(filters) == 0 {
+		return expr
+	}
+	operands := make([]*ileapv1.FilterExpression, 0, len(filters))
+	for _, filter := range filters {
+		operand := new(ileapv1.FilterExpression)
+		operand.SetComparison(filter)
+		operands = append(operands, operand)
+	}
+	and := new(ileapv1.FilterExpression_Operands)
+	and.SetOperands(operands)
+	result := new(ileapv1.FilterExpression)
+	result.SetAnd(and)
+	return result
+}
+
+// where translates a filter expression into a WHERE condition on the message
+// table "m". It reports whether the condition is exact. If it is not, the
+// condition only narrows the candidates and the expression must be
+// evaluated on the results. An empty condition matches all rows.
+//
+// The condition follows the semantics of [ileapfilter.MatchExpression]: strings
+// are compared case-insensitively, repeated fields match if any element
+// matches, and NE matches if no element is equal to the value.
+func (q *query) where(t *table, expr *ileapv1.FilterExpression) (string, bool) {
+	switch expr.WhichExpression() {
+	case ileapv1.FilterExpression_Comparison_case:
+		filter := expr.GetComparison()
+		return q.compare(t, filter.GetFieldPath(), filter.GetOperator(), filter.GetValue())
+	case ileapv1.FilterExpression_And_case:
+		var conditions []string
+		exact := true
+		for _, operand := range expr.GetAnd().GetOperands() {
+			condition, ok := q.where(t, operand)
+			if condition != "" {
+				conditions = append(conditions, condition)
+			}
+			exact = exact && ok
+		}
+		if len(conditions) == 0 {
+			return "", exact
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")", exact
+	case ileapv1.FilterExpression_Or_case:
+		operands := expr.GetOr().GetOperands()
+		conditions := make([]string, 0, len(operands))
+		mark := len(q.args)
+		for _, operand := range operands {
+			condition, ok := q.where(t, operand)
+			if !ok {
+				q.args = q.args[:mark]
+				return "", false
+			}
+			conditions = append(conditions, condition)
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")", true
+	case ileapv1.FilterExpression_Not_case:
+		mark := len(q.args)
+		condition, ok := q.where(t, expr.GetNot())
+		if !ok {
+			q.args = q.args[:mark]
+			return "", false
+		}
+		return "NOT " + condition, true
+	case ileapv1.FilterExpression_In_case:
+		in := expr.GetIn()
+		conditions := make([]string, 0, len(in.GetValues()))
+		mark := len(q.args)
+		for _, value := range in.GetValues() {
+			condition, ok := q.compare(t, in.GetFieldPath(), ileapv1.Filter_EQ, value)
+			if !ok {
+				q.args = q.args[:mark]
+				return "", false
+			}
+			conditions = append(conditions, condition)
+		}
+		return "(" + strings.Join(conditions, " OR ") + ")", true
+	case ileapv1.FilterExpression_Any_case:
+		lambda := expr.GetAny()
+		if !t.values[lambda.GetFieldPath()] {
+			return "", false
+		}
+		if !lambda.HasPredicate() {
+			return q.exists(t, lambda.GetFieldPath(), "", ""), true
+		}
+		predicate := lambda.GetPredicate().GetComparison()
+		if predicate == nil || predicate.GetFieldPath() != "" {
+			return "", false
+		}
+		switch predicate.GetOperator() {
+		case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+			return q.exists(t, lambda.GetFieldPath(), "=", predicate.GetValue()), true
+		case ileapv1.Filter_NE:
+			return q.exists(t, lambda.GetFieldPath(), "<>", predicate.GetValue()), true
+		default:
+			return "", false
+		}
+	default:
+		return "", false
+	}
+}
+
+// compare translates a comparison of a field path with a value.
+func (q *query) compare(
+	t *table,
+	fieldPath string,
+	operator ileapv1.Filter_Operator,
+	value string,
+) (string, bool) {
+	if t.values[fieldPath] {
+		switch operator {
+		case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+			return q.exists(t, fieldPath, "=", value), true
+		case ileapv1.Filter_NE:
+			return "NOT " + q.exists(t, fieldPath, "=", value), true
+		default:
+			return "", false
+		}
+	}
+	col, ok := t.columns[fieldPath]
+	if !ok {
+		return "", false
+	}
+	ref := "m." + col.name
+	var arg any
+	switch col.kind {
+	case textColumn:
+		// Ordering of strings depends on the collation of the database and
+		// is left to the evaluation of the results.
+		if operator != ileapv1.Filter_OPERATOR_UNSPECIFIED &&
+			operator != ileapv1.Filter_EQ &&
+			operator != ileapv1.Filter_NE {
+			return "", false
+		}
+		ref = "LOWER(" + ref + ")"
+		arg = strings.ToLower(value)
+	case timeColumn:
+		parsed, err := parseTime(value)
+		if err != nil {
+			return "", false
+		}
+		arg = parsed.UnixNano()
+	}
+	sqlOperator, ok := sqlOperators[operator]
+	if !ok {
+		return "", false
+	}
+	if operator == ileapv1.Filter_NE {
+		return "(m." + col.name + " IS NULL OR " + ref + " <> " + q.arg(arg) + ")", true
+	}
+	return "(m." + col.name + " IS NOT NULL AND " + ref + " " + sqlOperator + " " + q.arg(
+		arg,
+	) + ")", true
+}
+
+// exists returns a condition that matches if an element of a repeated field
+// compares to the value with the SQL operator. Without an operator, it
+// matches if the field has any elements.
+func (q *query) exists(t *table, fieldPath, sqlOperator, value string) string {
+	clause := "EXISTS (SELECT 1 FROM " + t.valuesTable + " v WHERE " + t.valuesJoin +
+		" AND v.field = " + q.arg(fieldPath)
+	if sqlOperator != "" {
+		clause += " AND LOWER(v.value) " + sqlOperator + " " + q.arg(strings.ToLower(value))
+	}
+	return clause + ")"
+}
+
+var sqlOperators = map[ileapv1.Filter_Operator]string{
+	ileapv1.Filter_OPERATOR_UNSPECIFIED: "=",
+	ileapv1.Filter_EQ:                   "=",
+	ileapv1.Filter_NE:                   "<>",
+	ileapv1.Filter_LT:                   "<",
+	ileapv1.Filter_LE:                   "<=",
+	ileapv1.Filter_GT:                   ">",
+	ileapv1.Filter_GE:                   ">=",
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
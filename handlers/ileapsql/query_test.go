@@ -0,0 +1,80 @@
+package ileapsql
+
+import (
+	"testing"
+
+	"github.com/way-platform/ileap-go/internal/odata"
+)
+
+func TestWhere(t *testing.T) {
+	for _, tc := range []struct {
+		filter string
+		want   string
+		args   []any
+		exact  bool
+	}{
+		{
+			filter: "productCategoryCpc eq '83117'",
+			want:   "(m.product_category_cpc IS NOT NULL AND LOWER(m.product_category_cpc) = $1)",
+			args:   []any{"83117"},
+			exact:  true,
+		},
+		{
+			filter: "status ne 'Active'",
+			want:   "(m.status IS NULL OR LOWER(m.status) <> $1)",
+			args:   []any{"active"},
+			exact:  true,
+		},
+		{
+			filter: "created ge 2022-03-01",
+			want:   "(m.created IS NOT NULL AND m.created >= $1)",
+			args:   []any{int64(1646092800000000000)},
+			exact:  true,
+		},
+		{
+			filter: "companyIds eq 'A' or status in ('Active')",
+			want: "(EXISTS (SELECT 1 FROM ileap_footprint_values v " +
+				"WHERE v.id = m.id AND v.version = m.version AND v.field = $1 AND LOWER(v.value) = $2) OR " +
+				"((m.status IS NOT NULL AND LOWER(m.status) = $3)))",
+			args:  []any{"companyIds", "a", "active"},
+			exact: true,
+		},
+		{
+			filter: "status eq 'Active' and pcf/declaredUnit eq 'liter'",
+			want:   "((m.status IS NOT NULL AND LOWER(m.status) = $1))",
+			args:   []any{"active"},
+			exact:  false,
+		},
+		{
+			filter: "status eq 'Active' or pcf/declaredUnit eq 'liter'",
+			exact:  false,
+		},
+		{
+			filter: "productCategoryCpc lt '5'",
+			exact:  false,
+		},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			expr, err := odata.ParseExpression(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q := &query{dialect: Postgres}
+			got, exact := q.where(footprintsTable, expr)
+			if got != tc.want {
+				t.Errorf("got condition\n%s\nwant\n%s", got, tc.want)
+			}
+			if exact != tc.exact {
+				t.Errorf("got exact %v, want %v", exact, tc.exact)
+			}
+			if len(q.args) != len(tc.args) {
+				t.Fatalf("got args %v, want %v", q.args, tc.args)
+			}
+			for i := range q.args {
+				if q.args[i] != tc.args[i] {
+					t.Errorf("got args %v, want %v", q.args, tc.args)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package ileapsql
+
+import (
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// Dialect is the SQL dialect of the database.
+type Dialect int
+
+const (
+	// SQLite uses "?" placeholders.
+	SQLite Dialect = iota
+	// Postgres uses "$1", "$2", … placeholders.
+	Postgres
+)
+
+// schema creates the tables and indexes used by the [Handler].
+//
+// Messages are stored as protojson in the data column. Frequently filtered
+// fields are projected into indexed columns, and the elements of repeated
+// string fields into a separate values table. Timestamps are stored as Unix
+// nanoseconds.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS ileap_footprints (
+		id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		status TEXT,
+		created BIGINT,
+		updated BIGINT,
+		product_category_cpc TEXT,
+		geography_country TEXT,
+		data TEXT NOT NULL,
+		PRIMARY KEY (id, version)
+	)`,
+	`CREATE INDEX IF NOT EXISTS ileap_footprints_created ON ileap_footprints (created)`,
+	`CREATE INDEX IF NOT EXISTS ileap_footprints_cpc ON ileap_footprints (product_category_cpc)`,
+	`CREATE TABLE IF NOT EXISTS ileap_footprint_values (
+		id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		field TEXT NOT NULL,
+		value TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS ileap_footprint_values_id ON ileap_footprint_values (id, version)`,
+	`CREATE INDEX IF NOT EXISTS ileap_footprint_values_value ON ileap_footprint_values (field, value)`,
+	`CREATE TABLE IF NOT EXISTS ileap_tads (
+		id TEXT NOT NULL PRIMARY KEY,
+		mode TEXT,
+		packaging_or_tr_eq_type TEXT,
+		departure_at BIGINT,
+		arrival_at BIGINT,
+		data TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS ileap_tads_departure_at ON ileap_tads (departure_at)`,
+	`CREATE TABLE IF NOT EXISTS ileap_tad_values (
+		id TEXT NOT NULL,
+		field TEXT NOT NULL,
+		value TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS ileap_tad_values_id ON ileap_tad_values (id)`,
+	`CREATE INDEX IF NOT EXISTS ileap_tad_values_value ON ileap_tad_values (field, value)`,
+}
+
+type columnKind int
+
+const (
+	textColumn columnKind = iota
+	timeColumn
+)
+
+// column is a projection of a message field into a column.
+type column struct {
+	name string
+	kind columnKind
+}
+
+// table describes how messages are stored and which field paths can be
+// filtered in SQL.
+type table struct {
+	name        string
+	valuesTable string
+	// valuesJoin joins the values table "v" with the message table "m".
+	valuesJoin string
+	// latest restricts the message table "m" to the latest version per ID.
+	latest  string
+	orderBy string
+	// columns are the projected columns, by field path.
+	columns map[string]column
+	// values are the field paths of repeated strings in the values table.
+	values map[string]bool
+}
+
+var footprintsTable = &table{
+	name:        "ileap_footprints",
+	valuesTable: "ileap_footprint_values",
+	valuesJoin:  "v.id = m.id AND v.version = m.version",
+	latest:      "m.version = (SELECT MAX(l.version) FROM ileap_footprints l WHERE l.id = m.id)",
+	orderBy:     "m.id",
+	columns: map[string]column{
+		"id":                   {name: "id", kind: textColumn},
+		"status":               {name: "status", kind: textColumn},
+		"created":              {name: "created", kind: timeColumn},
+		"updated":              {name: "updated", kind: timeColumn},
+		"productCategoryCpc":   {name: "product_category_cpc", kind: textColumn},
+		"pcf.geographyCountry": {name: "geography_country", kind: textColumn},
+	},
+	values: map[string]bool{
+		"productIds": true,
+		"companyIds": true,
+	},
+}
+
+var tadsTable = &table{
+	name:        "ileap_tads",
+	valuesTable: "ileap_tad_values",
+	valuesJoin:  "v.id = m.id",
+	orderBy:     "m.id",
+	columns: map[string]column{
+		"activityId":          {name: "id", kind: textColumn},
+		"mode":                {name: "mode", kind: textColumn},
+		"packagingOrTrEqType": {name: "packaging_or_tr_eq_type", kind: textColumn},
+		"departureAt":         {name: "departure_at", kind: timeColumn},
+		"arrivalAt":           {name: "arrival_at", kind: timeColumn},
+	},
+	values: map[string]bool{
+		"consignmentIds":                      true,
+		"energyCarriers.feedstocks.feedstock": true,
+	},
+}
+
+// footprintRow returns the projected column values of a footprint, in the
+// order of the footprint columns in the INSERT statement.
+func footprintRow(pf *ileapv1.ProductFootprint) []any {
+	return []any{
+		pf.GetId(),
+		pf.GetVersion(),
+		nullString(pf.HasStatus(), pf.GetStatus()),
+		nullTime(pf.HasCreated(), pf.GetCreated().AsTime()),
+		nullTime(pf.HasUpdated(), pf.GetUpdated().AsTime()),
+		nullString(pf.HasProductCategoryCpc(), pf.GetProductCategoryCpc()),
+		nullString(pf.GetPcf().HasGeographyCountry(), pf.GetPcf().GetGeographyCountry()),
+	}
+}
+
+func footprintValues(pf *ileapv1.ProductFootprint) map[string][]string {
+	return map[string][]string{
+		"productIds": pf.GetProductIds(),
+		"companyIds": pf.GetCompanyIds(),
+	}
+}
+
+// tadRow returns the projected column values of a TAD, in the order of the
+// TAD columns in the INSERT statement.
+func tadRow(tad *ileapv1.TAD) []any {
+	return []any{
+		tad.GetActivityId(),
+		nullString(tad.HasMode(), tad.GetMode()),
+		nullString(tad.HasPackagingOrTrEqType(), tad.GetPackagingOrTrEqType()),
+		nullTime(tad.HasDepartureAt(), tad.GetDepartureAt().AsTime()),
+		nullTime(tad.HasArrivalAt(), tad.GetArrivalAt().AsTime()),
+	}
+}
+
+func tadValues(tad *ileapv1.TAD) map[string][]string {
+	var feedstocks []string
+	for _, carrier := range tad.GetEnergyCarriers() {
+		for _, feedstock := range carrier.GetFeedstocks() {
+			if feedstock.HasFeedstock() {
+				feedstocks = append(feedstocks, feedstock.GetFeedstock())
+			}
+		}
+	}
+	return map[string][]string{
+		"consignmentIds":                      tad.GetConsignmentIds(),
+		"energyCarriers.feedstocks.feedstock": feedstocks,
+	}
+}
+
+func nullString(ok bool, s string) any {
+	if !ok {
+		return nil
+	}
+	return s
+}
+
+func nullTime(ok bool, t time.Time) any {
+	if !ok {
+		return nil
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,336 @@
+// Package ileapcalc calculates the emissions of transport chain elements
+// (TCEs) from iLEAP activity data, following the GLEC Framework and ISO 14083.
+//
+// A Transport Service Organizer receiving TADs (Data Transaction DT#3) turns
+// them into the TCEs of its footprints (DT#1) in one of two ways:
+//
+//   - [FromTOC] multiplies the transport activity of a TAD with the emission
+//     intensities of a TOC describing the transport operation.
+//   - [FromTAD] calculates the emission intensities from the energy carriers
+//     of the TAD, and returns the TOC of the operation along with the TCE.
+//
+// The transport activity is the freight mass times the distance in ton
+// kilometers (tkm), or the number of twenty-foot equivalent units (TEU) times
+// the distance in TEU kilometers (TEUkm) for container transport by sea or
+// inland waterway. The distance is the actual distance of the TAD if set, and
+// otherwise its shortest feasible distance or great circle distance, in that
+// order.
+//
+// All arithmetic is exact. Results are formatted with as many decimal places
+// as they need, without rounding.
+package ileapcalc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Transport activity units of a TOC.
+const (
+	// UnitTKM is the transport activity unit ton kilometers.
+	UnitTKM = "tkm"
+	// UnitTEUKM is the transport activity unit TEU kilometers, used for sea
+	// and inland waterway transport.
+	UnitTEUKM = "TEUkm"
+)
+
+var (
+	// ErrMissingValue is returned when a value required for the calculation
+	// is missing.
+	ErrMissingValue = errors.New("missing value")
+	// ErrInvalidValue is returned when a value of the input is invalid, e.g.
+	// a malformed decimal.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// Option configures a calculation.
+type Option func(*config)
+
+type config struct {
+	tceID      string
+	shipmentID string
+	tocID      string
+	unit       string
+}
+
+// WithTCEID sets the ID of the calculated TCE. Defaults to the activity ID of
+// the TAD.
+func WithTCEID(id string) Option {
+	return func(c *config) { c.tceID = id }
+}
+
+// WithShipmentID sets the shipment ID of the calculated TCE. Defaults to the
+// first consignment ID of the TAD.
+func WithShipmentID(id string) Option {
+	return func(c *config) { c.shipmentID = id }
+}
+
+// WithTOCID sets the ID of the TOC returned by [FromTAD]. Defaults to the
+// activity ID of the TAD.
+func WithTOCID(id string) Option {
+	return func(c *config) { c.tocID = id }
+}
+
+// WithTransportActivityUnit sets the transport activity unit of the energy
+// consumption of the TAD's energy carriers in [FromTAD]: [UnitTKM] or
+// [UnitTEUKM]. Defaults to [UnitTKM].
+func WithTransportActivityUnit(unit string) Option {
+	return func(c *config) { c.unit = unit }
+}
+
+func newConfig(tad *ileapv1.TAD, opts []Option) *config {
+	c := &config{
+		tceID: tad.GetActivityId(),
+		tocID: tad.GetActivityId(),
+		unit:  UnitTKM,
+	}
+	if ids := tad.GetConsignmentIds(); len(ids) > 0 {
+		c.shipmentID = ids[0]
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// FromTOC calculates the TCE of the transport activity of a TAD, performed
+// in the transport operation category of the TOC. The emissions are the
+// transport activity, in the TOC's transportActivityUnit, times the TOC's
+// co2eIntensityWTW and co2eIntensityTTW.
+func FromTOC(toc *ileapv1.TOC, tad *ileapv1.TAD, opts ...Option) (*ileapv1.TCE, error) {
+	cfg := newConfig(tad, opts)
+	tce, err := newTCE(tad, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if toc.GetTocId() == "" {
+		return nil, fmt.Errorf("%w: tocId", ErrMissingValue)
+	}
+	activity, err := transportActivity(tad, toc.GetTransportActivityUnit())
+	if err != nil {
+		return nil, err
+	}
+	intensityWTW, err := parseDecimal("co2eIntensityWTW", toc.GetCo2EIntensityWtw())
+	if err != nil {
+		return nil, err
+	}
+	intensityTTW, err := parseDecimal("co2eIntensityTTW", toc.GetCo2EIntensityTtw())
+	if err != nil {
+		return nil, err
+	}
+	tce.SetTocId(toc.GetTocId())
+	tce.SetCo2EWtwDecimal(activity.Mul(intensityWTW))
+	tce.SetCo2ETtwDecimal(activity.Mul(intensityTTW))
+	return tce, nil
+}
+
+// FromTAD calculates the TCE of a TAD from the energy carriers of the TAD,
+// and returns it with the TOC it references.
+//
+// The energy carriers must have an energyConsumption per transport activity
+// unit (see [WithTransportActivityUnit]), and WTW and TTW emission factors per
+// energyConsumptionUnit. The emission intensities of the TOC are the sums of
+// the relativeShare times the energyConsumption times the emission factor of
+// the energy carriers.
+func FromTAD(tad *ileapv1.TAD, opts ...Option) (*ileapv1.TCE, *ileapv1.TOC, error) {
+	cfg := newConfig(tad, opts)
+	toc, err := newTOC(tad, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	tce, err := FromTOC(toc, tad, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tce, toc, nil
+}
+
+// newTOC returns the TOC of the transport operation of a TAD.
+func newTOC(tad *ileapv1.TAD, cfg *config) (*ileapv1.TOC, error) {
+	carriers := tad.GetEnergyCarriers()
+	if len(carriers) == 0 {
+		return nil, fmt.Errorf("%w: energyCarriers", ErrMissingValue)
+	}
+	var intensityWTW, intensityTTW ileapdecimal.Decimal
+	for i, carrier := range carriers {
+		path := fmt.Sprintf("energyCarriers[%d].", i)
+		share, err := parseDecimal(path+"relativeShare", carrier.GetRelativeShare())
+		if err != nil {
+			return nil, err
+		}
+		consumption, err := parseDecimal(
+			path+"energyConsumption",
+			carrier.GetEnergyConsumption(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		factorWTW, err := parseDecimal(path+"emissionFactorWTW", carrier.GetEmissionFactorWtw())
+		if err != nil {
+			return nil, err
+		}
+		factorTTW, err := parseDecimal(path+"emissionFactorTTW", carrier.GetEmissionFactorTtw())
+		if err != nil {
+			return nil, err
+		}
+		consumed := share.Mul(consumption)
+		intensityWTW = intensityWTW.Add(consumed.Mul(factorWTW))
+		intensityTTW = intensityTTW.Add(consumed.Mul(factorTTW))
+	}
+	toc := new(ileapv1.TOC)
+	toc.SetTocId(cfg.tocID)
+	toc.SetMode(tad.GetMode())
+	if tad.HasLoadFactor() {
+		toc.SetLoadFactor(tad.GetLoadFactor())
+	}
+	if tad.HasEmptyDistanceFactor() {
+		toc.SetEmptyDistanceFactor(tad.GetEmptyDistanceFactor())
+	}
+	if tad.HasTemperatureControl() {
+		toc.SetTemperatureControl(tad.GetTemperatureControl())
+	}
+	tocCarriers := make([]*ileapv1.EnergyCarrier, 0, len(carriers))
+	for _, carrier := range carriers {
+		tocCarriers = append(tocCarriers, proto.CloneOf(carrier))
+	}
+	toc.SetEnergyCarriers(tocCarriers)
+	toc.SetCo2EIntensityWtwDecimal(intensityWTW)
+	toc.SetCo2EIntensityTtwDecimal(intensityTTW)
+	toc.SetTransportActivityUnit(cfg.unit)
+	return toc, nil
+}
+
+// newTCE returns a TCE with the activity data of a TAD, without emissions.
+func newTCE(tad *ileapv1.TAD, cfg *config) (*ileapv1.TCE, error) {
+	if cfg.tceID == "" {
+		return nil, fmt.Errorf("%w: tceId", ErrMissingValue)
+	}
+	if cfg.shipmentID == "" {
+		return nil, fmt.Errorf("%w: shipmentId", ErrMissingValue)
+	}
+	tkm, err := transportActivity(tad, UnitTKM)
+	if err != nil {
+		return nil, err
+	}
+	tce := new(ileapv1.TCE)
+	tce.SetTceId(cfg.tceID)
+	tce.SetShipmentId(cfg.shipmentID)
+	if ids := tad.GetConsignmentIds(); len(ids) == 1 {
+		tce.SetConsignmentId(ids[0])
+	}
+	tce.SetMass(tad.GetMass())
+	if tad.HasPackagingOrTrEqType() {
+		tce.SetPackagingOrTrEqType(tad.GetPackagingOrTrEqType())
+	}
+	if tad.HasPackagingOrTrEqAmount() {
+		tce.SetPackagingOrTrEqAmountDecimal(
+			ileapdecimal.NewFromInt(int64(tad.GetPackagingOrTrEqAmount())),
+		)
+	}
+	tce.SetDistance(proto.CloneOf(tad.GetDistance()))
+	if tad.HasOrigin() {
+		tce.SetOrigin(proto.CloneOf(tad.GetOrigin()))
+	}
+	if tad.HasDestination() {
+		tce.SetDestination(proto.CloneOf(tad.GetDestination()))
+	}
+	if tad.HasDepartureAt() {
+		tce.SetDepartureAt(proto.CloneOf(tad.GetDepartureAt()))
+	}
+	if tad.HasArrivalAt() {
+		tce.SetArrivalAt(proto.CloneOf(tad.GetArrivalAt()))
+	}
+	tce.SetTransportActivityDecimal(tkm)
+	return tce, nil
+}
+
+// transportActivity returns the transport activity of a TAD in the unit.
+func transportActivity(tad *ileapv1.TAD, unit string) (ileapdecimal.Decimal, error) {
+	distance, err := glecDistance(tad.GetDistance())
+	if err != nil {
+		return ileapdecimal.Decimal{}, err
+	}
+	switch unit {
+	case UnitTKM:
+		mass, err := parseDecimal("mass", tad.GetMass())
+		if err != nil {
+			return ileapdecimal.Decimal{}, err
+		}
+		// The mass is in kilograms.
+		return mass.Mul(distance).Mul(ileapdecimal.New(1, 3)), nil
+	case UnitTEUKM:
+		teu, err := teus(tad)
+		if err != nil {
+			return ileapdecimal.Decimal{}, err
+		}
+		return teu.Mul(distance), nil
+	case "":
+		return ileapdecimal.Decimal{}, fmt.Errorf("%w: transportActivityUnit", ErrMissingValue)
+	default:
+		return ileapdecimal.Decimal{}, fmt.Errorf(
+			"%w: transportActivityUnit %q",
+			ErrInvalidValue,
+			unit,
+		)
+	}
+}
+
+// glecDistance returns the distance in kilometers used for the transport
+// activity: the actual distance, the shortest feasible distance, or the great
+// circle distance, whichever is set first.
+func glecDistance(d *ileapv1.GLECDistance) (ileapdecimal.Decimal, error) {
+	switch {
+	case d.HasActual():
+		return parseDecimal("distance.actual", d.GetActual())
+	case d.HasSfd():
+		return parseDecimal("distance.sfd", d.GetSfd())
+	case d.HasGcd():
+		return parseDecimal("distance.gcd", d.GetGcd())
+	default:
+		return ileapdecimal.Decimal{}, fmt.Errorf("%w: distance", ErrMissingValue)
+	}
+}
+
+// teus returns the number of twenty-foot equivalent units of a TAD, from its
+// packaging or transport equipment.
+func teus(tad *ileapv1.TAD) (ileapdecimal.Decimal, error) {
+	amount := tad.GetPackagingOrTrEqAmount()
+	if amount <= 0 {
+		return ileapdecimal.Decimal{}, fmt.Errorf("%w: packagingOrTrEqAmount", ErrMissingValue)
+	}
+	switch t := tad.GetPackagingOrTrEqType(); t {
+	case "Container-TEU":
+		return ileapdecimal.NewFromInt(int64(amount)), nil
+	case "Container-FEU":
+		return ileapdecimal.NewFromInt(2 * int64(amount)), nil
+	case "":
+		return ileapdecimal.Decimal{}, fmt.Errorf("%w: packagingOrTrEqType", ErrMissingValue)
+	default:
+		return ileapdecimal.Decimal{}, fmt.Errorf(
+			"%w: packagingOrTrEqType %q is not measured in TEU",
+			ErrInvalidValue,
+			t,
+		)
+	}
+}
+
+// parseDecimal parses the decimal value of the field at path.
+func parseDecimal(path, value string) (ileapdecimal.Decimal, error) {
+	if value == "" {
+		return ileapdecimal.Decimal{}, fmt.Errorf("%w: %s", ErrMissingValue, path)
+	}
+	d, err := ileapdecimal.Parse(value)
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf(
+			"%w: %s %q is not a decimal",
+			ErrInvalidValue,
+			path,
+			value,
+		)
+	}
+	return d, nil
+}
@@ -0,0 +1,176 @@
+package ileapcalc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/internal/testfixture"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// newTestTAD returns the TAD of the shared test fixture.
+func newTestTAD(t *testing.T) *ileapv1.TAD {
+	t.Helper()
+	tad := &ileapv1.TAD{}
+	testfixture.Load(t, "tad", tad)
+	return tad
+}
+
+// newTestTOC returns the TOC of the shared test fixture, with the transport
+// activity unit and emission intensities of the test.
+func newTestTOC(t *testing.T, unit, intensityWTW, intensityTTW string) *ileapv1.TOC {
+	t.Helper()
+	toc := &ileapv1.TOC{}
+	testfixture.Load(t, "toc", toc)
+	toc.SetCo2EIntensityWtw(intensityWTW)
+	toc.SetCo2EIntensityTtw(intensityTTW)
+	toc.SetTransportActivityUnit(unit)
+	return toc
+}
+
+func TestFromTOC(t *testing.T) {
+	t.Run("tkm", func(t *testing.T) {
+		tce, err := FromTOC(newTestTOC(t, UnitTKM, "0.1", "0.08"), newTestTAD(t))
+		if err != nil {
+			t.Fatalf("FromTOC: %v", err)
+		}
+		if err := ileap.Validate(tce); err != nil {
+			t.Errorf("invalid TCE: %v", err)
+		}
+		for _, check := range []struct{ field, got, want string }{
+			{"tceId", tce.GetTceId(), "tad-1"},
+			{"tocId", tce.GetTocId(), "toc-road-1"},
+			{"shipmentId", tce.GetShipmentId(), "consignment-1"},
+			{"consignmentId", tce.GetConsignmentId(), "consignment-1"},
+			{"mass", tce.GetMass(), "230"},
+			{"transportActivity", tce.GetTransportActivity(), "161"},
+			{"co2eWTW", tce.GetCo2EWtw(), "16.1"},
+			{"co2eTTW", tce.GetCo2ETtw(), "12.88"},
+			{"origin", tce.GetOrigin().GetCity(), "Berlin"},
+		} {
+			if check.got != check.want {
+				t.Errorf("%s = %q, want %q", check.field, check.got, check.want)
+			}
+		}
+	})
+
+	t.Run("TEUkm", func(t *testing.T) {
+		tad := newTestTAD(t)
+		tad.SetMode("Sea")
+		tad.SetMass("12000")
+		distance := &ileapv1.GLECDistance{}
+		distance.SetSfd("1000.5")
+		distance.SetGcd("900")
+		tad.SetDistance(distance)
+		tad.SetPackagingOrTrEqType("Container-FEU")
+		tad.SetPackagingOrTrEqAmount(1)
+		tce, err := FromTOC(
+			newTestTOC(t, UnitTEUKM, "0.05", "0.04"),
+			tad,
+			WithTCEID("tce-1"),
+			WithShipmentID("shipment-1"),
+		)
+		if err != nil {
+			t.Fatalf("FromTOC: %v", err)
+		}
+		for _, check := range []struct{ field, got, want string }{
+			{"tceId", tce.GetTceId(), "tce-1"},
+			{"shipmentId", tce.GetShipmentId(), "shipment-1"},
+			{"packagingOrTrEqAmount", tce.GetPackagingOrTrEqAmount(), "1"},
+			{"transportActivity", tce.GetTransportActivity(), "12006"},
+			{"co2eWTW", tce.GetCo2EWtw(), "100.05"},
+			{"co2eTTW", tce.GetCo2ETtw(), "80.04"},
+		} {
+			if check.got != check.want {
+				t.Errorf("%s = %q, want %q", check.field, check.got, check.want)
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			name   string
+			modify func(*ileapv1.TOC, *ileapv1.TAD)
+			want   error
+		}{
+			{
+				name:   "missing mass",
+				modify: func(_ *ileapv1.TOC, tad *ileapv1.TAD) { tad.ClearMass() },
+				want:   ErrMissingValue,
+			},
+			{
+				name:   "exponent notation",
+				modify: func(toc *ileapv1.TOC, _ *ileapv1.TAD) { toc.SetCo2EIntensityWtw("1e-05") },
+				want:   ErrInvalidValue,
+			},
+			{
+				name:   "missing distance",
+				modify: func(_ *ileapv1.TOC, tad *ileapv1.TAD) { tad.ClearDistance() },
+				want:   ErrMissingValue,
+			},
+			{
+				name: "TEUkm without containers",
+				modify: func(toc *ileapv1.TOC, tad *ileapv1.TAD) {
+					toc.SetTransportActivityUnit(UnitTEUKM)
+					tad.SetPackagingOrTrEqType("Pallet")
+					tad.SetPackagingOrTrEqAmount(10)
+				},
+				want: ErrInvalidValue,
+			},
+			{
+				name:   "unknown unit",
+				modify: func(toc *ileapv1.TOC, _ *ileapv1.TAD) { toc.SetTransportActivityUnit("km") },
+				want:   ErrInvalidValue,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				toc, tad := newTestTOC(t, UnitTKM, "0.1", "0.08"), newTestTAD(t)
+				tc.modify(toc, tad)
+				if _, err := FromTOC(toc, tad); !errors.Is(err, tc.want) {
+					t.Errorf("FromTOC error = %v, want %v", err, tc.want)
+				}
+			})
+		}
+	})
+}
+
+func TestFromTAD(t *testing.T) {
+	tce, toc, err := FromTAD(newTestTAD(t), WithTOCID("toc-road"))
+	if err != nil {
+		t.Fatalf("FromTAD: %v", err)
+	}
+	if err := ileap.Validate(tce); err != nil {
+		t.Errorf("invalid TCE: %v", err)
+	}
+	if err := ileap.Validate(toc); err != nil {
+		t.Errorf("invalid TOC: %v", err)
+	}
+	for _, check := range []struct{ field, got, want string }{
+		{"toc.tocId", toc.GetTocId(), "toc-road"},
+		{"toc.mode", toc.GetMode(), "Road"},
+		{"toc.loadFactor", toc.GetLoadFactor(), "0.6"},
+		{"toc.transportActivityUnit", toc.GetTransportActivityUnit(), UnitTKM},
+		// 0.8 * 0.03 * 3.17 + 0.2 * 0.03 * 0.5
+		{"toc.co2eIntensityWTW", toc.GetCo2EIntensityWtw(), "0.07908"},
+		// 0.8 * 0.03 * 2.65 + 0.2 * 0.03 * 0.03
+		{"toc.co2eIntensityTTW", toc.GetCo2EIntensityTtw(), "0.06378"},
+		{"tce.tocId", tce.GetTocId(), "toc-road"},
+		{"tce.transportActivity", tce.GetTransportActivity(), "161"},
+		{"tce.co2eWTW", tce.GetCo2EWtw(), "12.73188"},
+		{"tce.co2eTTW", tce.GetCo2ETtw(), "10.26858"},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s = %q, want %q", check.field, check.got, check.want)
+		}
+	}
+
+	t.Run("missing emission factor", func(t *testing.T) {
+		tad := newTestTAD(t)
+		tad.GetEnergyCarriers()[1].ClearEmissionFactorTtw()
+		_, _, err := FromTAD(tad)
+		if !errors.Is(err, ErrMissingValue) {
+			t.Errorf("FromTAD error = %v, want ErrMissingValue", err)
+		}
+	})
+}
@@ -0,0 +1,342 @@
+// Package ileapdecimal provides an exact decimal type for the "Decimal" string
+// fields of the iLEAP and PACT data models, such as "mass", "co2eWTW" or
+// "pCfExcludingBiogenic".
+//
+// PACT encodes decimals as JSON strings matching [Pattern]: an optional minus
+// sign, integer digits, and optional fractional digits, without exponent
+// notation. Parsing a decimal with [strconv.ParseFloat] loses precision, and
+// formatting a float64 may produce strings such as "1e-05" that partners
+// reject. A [Decimal] is exact, and its arithmetic never rounds except where
+// explicitly requested with [Decimal.Round] and [Decimal.Quo].
+//
+// The generated messages have typed accessors for their decimal fields:
+//
+//	co2e, err := tce.GetCo2EWtwDecimal()
+//	if err != nil {
+//		return err
+//	}
+//	tce.SetCo2EWtwDecimal(co2e.Add(ileapdecimal.MustParse("0.5")))
+package ileapdecimal
+
+//go:generate go run ../internal/cmd/decimalgen -o ../proto/gen/wayplatform/connect/ileap/v1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Pattern is the validation pattern of decimal string fields in the iLEAP
+// data model.
+const Pattern = `^-?\d+(\.\d+)?$`
+
+var (
+	// ErrSyntax is returned when a string is not a decimal matching [Pattern].
+	ErrSyntax = errors.New("invalid decimal syntax")
+	// ErrDivisionByZero is returned by [Decimal.Quo] for a zero divisor.
+	ErrDivisionByZero = errors.New("decimal division by zero")
+)
+
+// Decimal is an exact decimal number. The zero value is 0.
+//
+// Decimals are immutable values: arithmetic methods return a new Decimal and
+// leave their receiver and arguments unchanged.
+type Decimal struct {
+	// coef is the unscaled value, without trailing zeros in its fractional
+	// digits. A nil coef is 0.
+	coef *big.Int
+	// scale is the number of fractional digits, at least 0.
+	scale int32
+}
+
+// Zero is the decimal 0.
+var Zero = Decimal{}
+
+// New returns the decimal unscaled × 10^-scale, e.g. New(125, 2) is 1.25.
+func New(unscaled int64, scale int32) Decimal {
+	return newDecimal(big.NewInt(unscaled), scale)
+}
+
+// NewFromInt returns the decimal value of an integer.
+func NewFromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// NewFromFloat returns the decimal with the shortest representation that
+// parses to the float, e.g. 0.1 for 0.1 rather than the exact binary value.
+// It returns an error for NaN and infinities.
+func NewFromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}, fmt.Errorf("%w: %v", ErrSyntax, f)
+	}
+	return Parse(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// Parse parses a decimal string matching [Pattern], e.g. "-12.50". Leading
+// plus signs, exponent notation, and missing integer or fractional digits
+// such as ".5" or "5." are rejected.
+func Parse(s string) (Decimal, error) {
+	digits := s
+	if len(digits) > 0 && digits[0] == '-' {
+		digits = digits[1:]
+	}
+	integer, fraction := digits, ""
+	for i := 0; i < len(digits); i++ {
+		if digits[i] == '.' {
+			integer, fraction = digits[:i], digits[i+1:]
+			if fraction == "" {
+				return Decimal{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+			}
+			break
+		}
+	}
+	if integer == "" || !isDigits(integer) || !isDigits(fraction) ||
+		len(fraction) > math.MaxInt32 {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	// Trailing fractional zeros are dropped here, where it is cheap, rather
+	// than divided out of the coefficient.
+	fraction = strings.TrimRight(fraction, "0")
+	coef, ok := new(big.Int).SetString(integer+fraction, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	if s[0] == '-' {
+		coef.Neg(coef)
+	}
+	return newDecimal(coef, int32(len(fraction))), nil
+}
+
+// MustParse is like [Parse] but panics if the string is not a decimal. It is
+// intended for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns the canonical PACT representation of the decimal: a minus
+// sign for negative values, the integer digits without leading zeros, and the
+// fractional digits without trailing zeros, e.g. "-0.5", "0" or "1200".
+func (d Decimal) String() string {
+	if d.coef == nil {
+		return "0"
+	}
+	digits := new(big.Int).Abs(d.coef).String()
+	if d.scale > 0 {
+		if n := int(d.scale) + 1 - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.coef.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Float64 returns the float64 nearest to the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// Rat returns the decimal as a new rational number.
+func (d Decimal) Rat() *big.Rat {
+	if d.coef == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(d.coef, pow10(d.scale))
+}
+
+// Sign returns -1, 0 or +1 for negative, zero and positive decimals.
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+	return d.coef.Sign()
+}
+
+// IsZero reports whether the decimal is 0.
+func (d Decimal) IsZero() bool {
+	return d.coef == nil
+}
+
+// Cmp compares the decimals and returns -1, 0 or +1 if d is less than, equal
+// to or greater than e.
+func (d Decimal) Cmp(e Decimal) int {
+	x, y := align(d, e)
+	return x.Cmp(y)
+}
+
+// Equal reports whether the decimals have the same value.
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.coef == nil {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Neg(d.coef), scale: d.scale}
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	x, y := align(d, e)
+	return newDecimal(x.Add(x, y), max(d.scale, e.scale))
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	x, y := align(d, e)
+	return newDecimal(x.Sub(x, y), max(d.scale, e.scale))
+}
+
+// Mul returns d × e. It panics if the product has more than math.MaxInt32
+// fractional digits.
+func (d Decimal) Mul(e Decimal) Decimal {
+	if d.coef == nil || e.coef == nil {
+		return Decimal{}
+	}
+	scale := int64(d.scale) + int64(e.scale)
+	if scale > math.MaxInt32 {
+		panic("ileapdecimal: product has too many fractional digits")
+	}
+	return newDecimal(new(big.Int).Mul(d.coef, e.coef), int32(scale))
+}
+
+// Quo returns d / e rounded to places fractional digits, as by
+// [Decimal.Round]. The quotient of decimals is generally not a decimal, e.g.
+// 1 / 3, so a precision is required. It returns [ErrDivisionByZero] if e is 0.
+func (d Decimal) Quo(e Decimal, places int32) (Decimal, error) {
+	if e.coef == nil {
+		return Decimal{}, ErrDivisionByZero
+	}
+	return round(new(big.Rat).Quo(d.Rat(), e.Rat()), places), nil
+}
+
+// Round returns d rounded to places fractional digits, rounding halves away
+// from zero, e.g. 2.345 rounded to 2 places is 2.35 and -2.345 is -2.35. A
+// negative places rounds to a power of ten, e.g. 1250 rounded to -2 places
+// is 1300.
+func (d Decimal) Round(places int32) Decimal {
+	if d.scale <= places {
+		return d
+	}
+	return round(d.Rat(), places)
+}
+
+// Sum returns the sum of the decimals.
+func Sum(ds ...Decimal) Decimal {
+	var sum Decimal
+	for _, d := range ds {
+		sum = sum.Add(d)
+	}
+	return sum
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Decimals are encoded in
+// JSON as strings, as required by PACT.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (d *Decimal) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// newDecimal returns the decimal coef × 10^-scale in its normal form, without
+// trailing fractional zeros. It takes ownership of coef.
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if coef.Sign() == 0 {
+		return Decimal{}
+	}
+	if scale < 0 {
+		return Decimal{coef: coef.Mul(coef, pow10(-scale))}
+	}
+	// A multiple of 10^k has at least k trailing zero bits, which spares
+	// formatting the digits of most coefficients.
+	n := min(int(scale), int(coef.TrailingZeroBits()))
+	if n == 0 {
+		return Decimal{coef: coef, scale: scale}
+	}
+	digits := coef.Text(10)
+	zeros := 0
+	for zeros < n && digits[len(digits)-1-zeros] == '0' {
+		zeros++
+	}
+	if zeros > 0 {
+		coef.Quo(coef, pow10(int32(zeros)))
+	}
+	return Decimal{coef: coef, scale: scale - int32(zeros)}
+}
+
+// round returns r rounded to places fractional digits, rounding halves away
+// from zero.
+func round(r *big.Rat, places int32) Decimal {
+	num := new(big.Int).Set(r.Num())
+	den := new(big.Int).Set(r.Denom())
+	if places >= 0 {
+		num.Mul(num, pow10(places))
+	} else {
+		den.Mul(den, pow10(-places))
+	}
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Abs(m).Lsh(m, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+	return newDecimal(q, places)
+}
+
+// align returns the unscaled values of the decimals at their common scale.
+func align(d, e Decimal) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+	if d.coef != nil {
+		x.Set(d.coef)
+	}
+	if e.coef != nil {
+		y.Set(e.coef)
+	}
+	switch {
+	case d.scale < e.scale:
+		x.Mul(x, pow10(e.scale-d.scale))
+	case e.scale < d.scale:
+		y.Mul(y, pow10(d.scale-e.scale))
+	}
+	return x, y
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
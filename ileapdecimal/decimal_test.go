@@ -0,0 +1,210 @@
+package ileapdecimal_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"-0.000", "0"},
+		{"007", "7"},
+		{"1.50", "1.5"},
+		{"-12.340", "-12.34"},
+		{"0.00001", "0.00001"},
+		{"1200", "1200"},
+		{
+			"123456789012345678901234567890.000000000000000000001",
+			"123456789012345678901234567890.000000000000000000001",
+		},
+	} {
+		d, err := ileapdecimal.Parse(tc.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tc.input, err)
+			continue
+		}
+		if got := d.String(); got != tc.want {
+			t.Errorf("Parse(%q) = %s, want %s", tc.input, got, tc.want)
+		}
+	}
+	for _, input := range []string{
+		"", "-", "+1", "1e-05", ".5", "5.", "1.2.3", " 1", "1,5", "NaN",
+	} {
+		if _, err := ileapdecimal.Parse(input); !errors.Is(err, ileapdecimal.ErrSyntax) {
+			t.Errorf("Parse(%q) error = %v, want ErrSyntax", input, err)
+		}
+	}
+}
+
+func TestParse_LongFraction(t *testing.T) {
+	zeros := strings.Repeat("0", 100_000)
+	for input, want := range map[string]string{
+		"1." + zeros:       "1",
+		"-0." + zeros:      "0",
+		"0." + zeros + "1": "0." + zeros + "1",
+		"1" + zeros + ".5": "1" + zeros + ".5",
+	} {
+		d, err := ileapdecimal.Parse(input)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if got := d.String(); got != want {
+			t.Errorf("Parse(%.10q...) = %.10q..., want %.10q...", input, got, want)
+		}
+	}
+	// Products are normalized without dividing out one zero at a time.
+	d := ileapdecimal.MustParse("0." + zeros + "5").Mul(ileapdecimal.NewFromInt(2))
+	if want := "0." + zeros[1:] + "1"; d.String() != want {
+		t.Errorf("Mul = %.10q..., want %.10q...", d.String(), want)
+	}
+}
+
+func BenchmarkParse_LongFraction(b *testing.B) {
+	s := "1." + strings.Repeat("0", 100_000)
+	for b.Loop() {
+		if _, err := ileapdecimal.Parse(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestNewFromFloat(t *testing.T) {
+	for f, want := range map[float64]string{
+		0.1:   "0.1",
+		1e-05: "0.00001",
+		1e21:  "1000000000000000000000",
+	} {
+		d, err := ileapdecimal.NewFromFloat(f)
+		if err != nil || d.String() != want {
+			t.Errorf("NewFromFloat(%v) = %s, %v, want %s", f, d, err, want)
+		}
+	}
+	if got := ileapdecimal.New(-125, 2).String(); got != "-1.25" {
+		t.Errorf("New(-125, 2) = %s, want -1.25", got)
+	}
+	if got := ileapdecimal.New(5, -2).String(); got != "500" {
+		t.Errorf("New(5, -2) = %s, want 500", got)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	d := ileapdecimal.MustParse
+	for _, tc := range []struct {
+		name string
+		got  ileapdecimal.Decimal
+		want string
+	}{
+		{"add", d("0.1").Add(d("0.2")), "0.3"},
+		{"add to zero", d("0.5").Add(d("-0.5")), "0"},
+		{"sub", d("1").Sub(d("0.001")), "0.999"},
+		{"mul", d("0.8").Mul(d("0.03")).Mul(d("3.17")), "0.07608"},
+		{"mul by zero", d("12.5").Mul(ileapdecimal.Zero), "0"},
+		{"neg", d("1.5").Neg(), "-1.5"},
+		{"abs", d("-1.5").Abs(), "1.5"},
+		{"sum", ileapdecimal.Sum(d("1962.72"), d("1320")), "3282.72"},
+		{"round half up", d("2.345").Round(2), "2.35"},
+		{"round half away from zero", d("-2.345").Round(2), "-2.35"},
+		{"round down", d("2.344").Round(2), "2.34"},
+		{"round to integer", d("0.5").Round(0), "1"},
+		{"round to tens", d("1250").Round(-2), "1300"},
+		{"round exact", d("2.5").Round(3), "2.5"},
+	} {
+		if got := tc.got.String(); got != tc.want {
+			t.Errorf("%s = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	t.Run("quo", func(t *testing.T) {
+		q, err := d("1").Quo(d("3"), 4)
+		if err != nil || q.String() != "0.3333" {
+			t.Errorf("1 / 3 = %s, %v, want 0.3333", q, err)
+		}
+		q, err = d("-2").Quo(d("3"), 2)
+		if err != nil || q.String() != "-0.67" {
+			t.Errorf("-2 / 3 = %s, %v, want -0.67", q, err)
+		}
+		_, err = d("1").Quo(ileapdecimal.Zero, 2)
+		if !errors.Is(err, ileapdecimal.ErrDivisionByZero) {
+			t.Errorf("1 / 0 error = %v, want ErrDivisionByZero", err)
+		}
+	})
+
+	t.Run("mul scale overflow", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic for product with too many fractional digits")
+			}
+		}()
+		tiny := ileapdecimal.New(1, math.MaxInt32)
+		tiny.Mul(tiny)
+	})
+
+	t.Run("cmp", func(t *testing.T) {
+		for _, tc := range []struct {
+			a, b string
+			want int
+		}{
+			{"1.50", "1.5", 0},
+			{"-1", "0.001", -1},
+			{"10", "9.999", 1},
+			{"0", "-0", 0},
+		} {
+			if got := d(tc.a).Cmp(d(tc.b)); got != tc.want {
+				t.Errorf("Cmp(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+			}
+		}
+		if !d("1.50").Equal(d("1.5")) || d("1").Equal(d("-1")) {
+			t.Error("unexpected Equal result")
+		}
+	})
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Mass ileapdecimal.Decimal `json:"mass"`
+	}
+	if err := json.Unmarshal([]byte(`{"mass": "40000.50"}`), &v); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got, want := string(data), `{"mass":"40000.5"}`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+	err = json.Unmarshal([]byte(`{"mass": "4e4"}`), &v)
+	if !errors.Is(err, ileapdecimal.ErrSyntax) {
+		t.Errorf("Unmarshal error = %v, want ErrSyntax", err)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	tce := &ileapv1.TCE{}
+	if _, err := tce.GetCo2EWtwDecimal(); err == nil {
+		t.Error("expected error for unset field")
+	}
+	tce.SetCo2EWtw("1e-05")
+	if _, err := tce.GetCo2EWtwDecimal(); !errors.Is(err, ileapdecimal.ErrSyntax) {
+		t.Errorf("expected ErrSyntax for exponent notation, got %v", err)
+	}
+	tce.SetCo2EWtwDecimal(ileapdecimal.New(1, 5))
+	if got := tce.GetCo2EWtw(); got != "0.00001" {
+		t.Errorf("co2eWTW = %q, want %q", got, "0.00001")
+	}
+	co2e, err := tce.GetCo2EWtwDecimal()
+	if err != nil || !co2e.Equal(ileapdecimal.New(1, 5)) {
+		t.Errorf("GetCo2EWtwDecimal = %s, %v", co2e, err)
+	}
+}
@@ -0,0 +1,490 @@
+// Package ileapfilter evaluates iLEAP filters against proto messages.
+//
+// Filters can be evaluated against any message, such as a
+// [ileapv1.ProductFootprint] or a [ileapv1.TAD], which allows an
+// ILeapServiceHandler to filter in-memory data or post-filter the results of
+// a backend with a single call:
+//
+//	footprints, err := ileapfilter.Filter(footprints, req.GetFilters())
+//
+// Field paths use dot notation and resolve fields by their JSON name, e.g.
+// "pcf.geographyCountry". Paths that traverse a repeated field match if any
+// element matches. Values are compared according to the type of the field:
+//
+//   - Decimal strings, such as "co2eWTW", and numeric fields numerically.
+//     Values are decimals matching [ileapdecimal.Pattern], without exponent.
+//   - Timestamps chronologically. Values are RFC 3339 date-times or dates.
+//   - Strings and enums case-insensitively.
+//   - Booleans by equality.
+//
+// The data of a [structpb.Struct], such as the data of an extension, is
+// traversed by key. Its strings are compared numerically if both sides are
+// decimals, and case-insensitively otherwise.
+package ileapfilter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	// ErrUnknownField is returned when a field path does not exist in the message.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrInvalidValue is returned when a filter value cannot be compared with
+	// the type of the field, e.g. a non-numeric value for a decimal field.
+	ErrInvalidValue = errors.New("invalid filter value")
+	// ErrUnsupported is returned for operators that are not supported by the
+	// type of the field, e.g. "lt" for a boolean field, and for filter
+	// expressions that are not supported.
+	ErrUnsupported = errors.New("unsupported filter")
+)
+
+// Match reports whether the message matches all filters.
+//
+// A filter on a path that traverses a repeated field matches if any element
+// matches, except for the NE operator, which matches if no element is equal
+// to the value. Missing fields only match the NE operator.
+func Match(msg proto.Message, filters []*ileapv1.Filter) (bool, error) {
+	root := messageNode(msg)
+	for _, filter := range filters {
+		ok, err := matchFilter(root, filter)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// MatchExpression reports whether the message matches the filter expression.
+// A nil expression matches all messages.
+func MatchExpression(msg proto.Message, expr *ileapv1.FilterExpression) (bool, error) {
+	if expr == nil {
+		return true, nil
+	}
+	return matchExpression(messageNode(msg), expr)
+}
+
+// Filter returns the messages that match all filters, see [Match].
+func Filter[T proto.Message](msgs []T, filters []*ileapv1.Filter) ([]T, error) {
+	return filterMessages(msgs, func(msg T) (bool, error) { return Match(msg, filters) })
+}
+
+// FilterExpression returns the messages that match the filter expression, see
+// [MatchExpression].
+func FilterExpression[T proto.Message](msgs []T, expr *ileapv1.FilterExpression) ([]T, error) {
+	return filterMessages(msgs, func(msg T) (bool, error) { return MatchExpression(msg, expr) })
+}
+
+func filterMessages[T proto.Message](msgs []T, match func(T) (bool, error)) ([]T, error) {
+	result := make([]T, 0, len(msgs))
+	for _, msg := range msgs {
+		ok, err := match(msg)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, msg)
+		}
+	}
+	return result, nil
+}
+
+// node is a value reached by a field path.
+type node struct {
+	value protoreflect.Value
+	// field is the field holding the value, or nil for the root message and
+	// for values within a Struct.
+	field protoreflect.FieldDescriptor
+	// message is the descriptor of the value if it is a message.
+	message protoreflect.MessageDescriptor
+}
+
+func messageNode(msg proto.Message) node {
+	m := msg.ProtoReflect()
+	return node{value: protoreflect.ValueOfMessage(m), message: m.Descriptor()}
+}
+
+func matchExpression(ctx node, expr *ileapv1.FilterExpression) (bool, error) {
+	switch expr.WhichExpression() {
+	case ileapv1.FilterExpression_Comparison_case:
+		return matchFilter(ctx, expr.GetComparison())
+	case ileapv1.FilterExpression_And_case:
+		for _, operand := range expr.GetAnd().GetOperands() {
+			ok, err := matchExpression(ctx, operand)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case ileapv1.FilterExpression_Or_case:
+		for _, operand := range expr.GetOr().GetOperands() {
+			ok, err := matchExpression(ctx, operand)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case ileapv1.FilterExpression_Not_case:
+		ok, err := matchExpression(ctx, expr.GetNot())
+		return !ok && err == nil, err
+	case ileapv1.FilterExpression_In_case:
+		in := expr.GetIn()
+		nodes, err := resolve(ctx, in.GetFieldPath())
+		if err != nil {
+			return false, err
+		}
+		for _, value := range in.GetValues() {
+			ok, err := matchNodes(nodes, in.GetFieldPath(), ileapv1.Filter_EQ, value)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case ileapv1.FilterExpression_Any_case:
+		lambda := expr.GetAny()
+		nodes, err := resolve(ctx, lambda.GetFieldPath())
+		if err != nil {
+			return false, err
+		}
+		if !lambda.HasPredicate() {
+			return len(nodes) > 0, nil
+		}
+		for _, element := range nodes {
+			ok, err := matchExpression(element, lambda.GetPredicate())
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: empty filter expression", ErrUnsupported)
+	}
+}
+
+func matchFilter(ctx node, filter *ileapv1.Filter) (bool, error) {
+	nodes, err := resolve(ctx, filter.GetFieldPath())
+	if err != nil {
+		return false, err
+	}
+	return matchNodes(nodes, filter.GetFieldPath(), filter.GetOperator(), filter.GetValue())
+}
+
+func matchNodes(
+	nodes []node,
+	fieldPath string,
+	operator ileapv1.Filter_Operator,
+	value string,
+) (bool, error) {
+	if operator == ileapv1.Filter_NE {
+		ok, err := matchNodes(nodes, fieldPath, ileapv1.Filter_EQ, value)
+		return !ok && err == nil, err
+	}
+	want := &filterValue{raw: value}
+	for _, n := range nodes {
+		ok, err := compare(n, operator, want)
+		if err != nil {
+			return false, fmt.Errorf("filter %q: %w", fieldPath, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resolve returns the values reached by a dot notation field path, expanding
+// repeated fields. An empty path refers to the context value itself.
+func resolve(ctx node, fieldPath string) ([]node, error) {
+	nodes := []node{ctx}
+	if fieldPath == "" {
+		return expand(nodes), nil
+	}
+	for segment := range strings.SplitSeq(fieldPath, ".") {
+		next := make([]node, 0, len(nodes))
+		for _, n := range expand(nodes) {
+			child, err := lookup(n, segment)
+			if err != nil {
+				return nil, fmt.Errorf("filter %q: %w", fieldPath, err)
+			}
+			next = append(next, child...)
+		}
+		nodes = next
+	}
+	return expand(nodes), nil
+}
+
+// lookup returns the value of a field of a message, or of a key of a Struct.
+func lookup(n node, name string) ([]node, error) {
+	if n.message == nil {
+		return nil, fmt.Errorf("%w: %q is not an object", ErrUnknownField, name)
+	}
+	m := n.value.Message()
+	if n.message.FullName() == "google.protobuf.Struct" {
+		fields := m.Interface().(*structpb.Struct).GetFields()
+		value, ok := fields[name]
+		if !ok {
+			return nil, nil
+		}
+		return structValueNodes(value), nil
+	}
+	fd := n.message.Fields().ByJSONName(name)
+	if fd == nil {
+		fd = n.message.Fields().ByName(protoreflect.Name(name))
+	}
+	if fd == nil || fd.IsMap() {
+		return nil, fmt.Errorf("%w: %q in %s", ErrUnknownField, name, n.message.Name())
+	}
+	if !m.Has(fd) {
+		return nil, nil
+	}
+	return []node{{value: m.Get(fd), field: fd, message: fd.Message()}}, nil
+}
+
+// expand replaces repeated field values with their elements.
+func expand(nodes []node) []node {
+	expanded := make([]node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.field == nil || !n.field.IsList() {
+			expanded = append(expanded, n)
+			continue
+		}
+		list := n.value.List()
+		for i := range list.Len() {
+			element := node{value: list.Get(i), field: n.field, message: n.field.Message()}
+			expanded = append(expanded, expandStructValue(element)...)
+		}
+	}
+	return expanded
+}
+
+// expandStructValue unwraps a google.protobuf.Value element of a repeated field.
+func expandStructValue(n node) []node {
+	if n.message == nil || n.message.FullName() != "google.protobuf.Value" {
+		return []node{{value: n.value, field: elementField{n.field}, message: n.message}}
+	}
+	return structValueNodes(n.value.Message().Interface().(*structpb.Value))
+}
+
+// structValueNodes returns the nodes of a dynamic Struct value, expanding lists.
+func structValueNodes(value *structpb.Value) []node {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_StructValue:
+		m := kind.StructValue.ProtoReflect()
+		return []node{{value: protoreflect.ValueOfMessage(m), message: m.Descriptor()}}
+	case *structpb.Value_ListValue:
+		var nodes []node
+		for _, element := range kind.ListValue.GetValues() {
+			nodes = append(nodes, structValueNodes(element)...)
+		}
+		return nodes
+	case *structpb.Value_StringValue:
+		return []node{{value: protoreflect.ValueOfString(kind.StringValue)}}
+	case *structpb.Value_NumberValue:
+		return []node{{value: protoreflect.ValueOfFloat64(kind.NumberValue)}}
+	case *structpb.Value_BoolValue:
+		return []node{{value: protoreflect.ValueOfBool(kind.BoolValue)}}
+	default:
+		return nil
+	}
+}
+
+// elementField wraps the descriptor of a repeated field for its elements, so
+// that elements are not expanded again.
+type elementField struct {
+	protoreflect.FieldDescriptor
+}
+
+// IsList implements [protoreflect.FieldDescriptor].
+func (elementField) IsList() bool { return false }
+
+// filterValue is a filter value, parsed as a decimal once on first use.
+type filterValue struct {
+	raw        string
+	parsed     bool
+	decimal    ileapdecimal.Decimal
+	decimalErr error
+}
+
+// decimalValue returns the value as a decimal.
+func (v *filterValue) decimalValue() (ileapdecimal.Decimal, error) {
+	if !v.parsed {
+		v.decimal, v.decimalErr = ileapdecimal.Parse(v.raw)
+		v.parsed = true
+	}
+	return v.decimal, v.decimalErr
+}
+
+// compare compares a single value with a filter value.
+func compare(n node, operator ileapv1.Filter_Operator, want *filterValue) (bool, error) {
+	value := want.raw
+	if n.message != nil {
+		if n.message.FullName() != "google.protobuf.Timestamp" {
+			return false, fmt.Errorf("%w: cannot compare %s", ErrUnsupported, n.message.Name())
+		}
+		want, err := parseTime(value)
+		if err != nil {
+			return false, fmt.Errorf("%w: %q is not a date-time", ErrInvalidValue, value)
+		}
+		got := n.value.Message().Interface().(*timestamppb.Timestamp).AsTime()
+		return compareResult(operator, got.Compare(want))
+	}
+	if n.field == nil {
+		return compareDynamic(n.value, operator, want)
+	}
+	switch n.field.Kind() {
+	case protoreflect.StringKind:
+		if isDecimalField(n.field) {
+			wantDecimal, err := want.decimalValue()
+			if err != nil {
+				return false, fmt.Errorf("%w: %q is not a decimal", ErrInvalidValue, value)
+			}
+			got, err := ileapdecimal.Parse(n.value.String())
+			if err != nil {
+				return false, nil
+			}
+			return compareResult(operator, got.Cmp(wantDecimal))
+		}
+		return compareStrings(operator, n.value.String(), value)
+	case protoreflect.BoolKind:
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("%w: %q is not a boolean", ErrInvalidValue, value)
+		}
+		return compareEquality(operator, n.value.Bool() == want)
+	case protoreflect.EnumKind:
+		number := n.value.Enum()
+		enumValue := n.field.Enum().Values().ByNumber(number)
+		if enumValue == nil {
+			// An unknown value of an open enum has no name, only its number.
+			return compareEquality(operator, strconv.Itoa(int(number)) == value)
+		}
+		return compareEquality(operator, strings.EqualFold(string(enumValue.Name()), value))
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		got, err := ileapdecimal.NewFromFloat(n.value.Float())
+		if err != nil {
+			return false, nil
+		}
+		return compareNumber(operator, got, want)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return compareNumber(operator, ileapdecimal.NewFromInt(n.value.Int()), want)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		got := ileapdecimal.MustParse(strconv.FormatUint(n.value.Uint(), 10))
+		return compareNumber(operator, got, want)
+	default:
+		return false, fmt.Errorf("%w: cannot compare %s", ErrUnsupported, n.field.Kind())
+	}
+}
+
+// compareDynamic compares a value within a Struct. Strings are compared
+// numerically if both sides are decimals matching [ileapdecimal.Pattern].
+func compareDynamic(
+	v protoreflect.Value,
+	operator ileapv1.Filter_Operator,
+	want *filterValue,
+) (bool, error) {
+	switch got := v.Interface().(type) {
+	case float64:
+		gotDecimal, err := ileapdecimal.NewFromFloat(got)
+		if err != nil {
+			return false, nil
+		}
+		return compareNumber(operator, gotDecimal, want)
+	case bool:
+		wantBool, err := strconv.ParseBool(want.raw)
+		if err != nil {
+			return false, fmt.Errorf("%w: %q is not a boolean", ErrInvalidValue, want.raw)
+		}
+		return compareEquality(operator, got == wantBool)
+	case string:
+		if wantDecimal, err := want.decimalValue(); err == nil {
+			if gotDecimal, err := ileapdecimal.Parse(got); err == nil {
+				return compareResult(operator, gotDecimal.Cmp(wantDecimal))
+			}
+		}
+		return compareStrings(operator, got, want.raw)
+	default:
+		return false, fmt.Errorf("%w: cannot compare %T", ErrUnsupported, got)
+	}
+}
+
+func compareNumber(
+	operator ileapv1.Filter_Operator,
+	got ileapdecimal.Decimal,
+	want *filterValue,
+) (bool, error) {
+	wantDecimal, err := want.decimalValue()
+	if err != nil {
+		return false, fmt.Errorf("%w: %q is not a number", ErrInvalidValue, want.raw)
+	}
+	return compareResult(operator, got.Cmp(wantDecimal))
+}
+
+func compareStrings(operator ileapv1.Filter_Operator, got, want string) (bool, error) {
+	if operator == ileapv1.Filter_EQ || operator == ileapv1.Filter_OPERATOR_UNSPECIFIED {
+		return strings.EqualFold(got, want), nil
+	}
+	return compareResult(operator, strings.Compare(strings.ToLower(got), strings.ToLower(want)))
+}
+
+func compareEquality(operator ileapv1.Filter_Operator, equal bool) (bool, error) {
+	switch operator {
+	case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+		return equal, nil
+	default:
+		return false, fmt.Errorf("%w: operator %v", ErrUnsupported, operator)
+	}
+}
+
+// compareResult applies an operator to the result of a three-way comparison.
+func compareResult(operator ileapv1.Filter_Operator, cmp int) (bool, error) {
+	switch operator {
+	case ileapv1.Filter_OPERATOR_UNSPECIFIED, ileapv1.Filter_EQ:
+		return cmp == 0, nil
+	case ileapv1.Filter_LT:
+		return cmp < 0, nil
+	case ileapv1.Filter_LE:
+		return cmp <= 0, nil
+	case ileapv1.Filter_GT:
+		return cmp > 0, nil
+	case ileapv1.Filter_GE:
+		return cmp >= 0, nil
+	default:
+		return false, fmt.Errorf("%w: operator %v", ErrUnsupported, operator)
+	}
+}
+
+// isDecimalField reports whether a string field holds decimals, as declared
+// by its validation pattern.
+func isDecimalField(field protoreflect.FieldDescriptor) bool {
+	if element, ok := field.(elementField); ok {
+		field = element.FieldDescriptor
+	}
+	rules, ok := proto.GetExtension(field.Options(), validate.E_Field).(*validate.FieldRules)
+	if !ok {
+		return false
+	}
+	if field.IsList() {
+		rules = rules.GetRepeated().GetItems()
+	}
+	return rules.GetString().GetPattern() == ileapdecimal.Pattern
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
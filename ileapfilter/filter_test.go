@@ -0,0 +1,309 @@
+package ileapfilter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/way-platform/ileap-go"
+	"github.com/way-platform/ileap-go/internal/odata"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const testFootprintJSON = `{
+	"id": "91715e5e-fd0b-4d1c-8fab-76290c46e6ed",
+	"created": "2022-03-01T09:32:20Z",
+	"companyIds": ["urn:uuid:69585GB6", "urn:epc:id:sgln:562958.00000.4"],
+	"productCategoryCpc": "83117",
+	"pcf": {
+		"declaredUnit": "liter",
+		"pCfExcludingBiogenic": "1.63",
+		"geographyCountry": "DE",
+		"packagingEmissionsIncluded": false,
+		"dqi": {"coveragePercent": 78},
+		"secondaryEmissionFactorSources": [
+			{"name": "Ecoinvent", "version": "3.1"},
+			{"name": "GLEC", "version": "3.0"}
+		]
+	}
+}`
+
+func newTestFootprint(t *testing.T) *ileapv1.ProductFootprint {
+	t.Helper()
+	pf := &ileapv1.ProductFootprint{}
+	if err := protojson.Unmarshal([]byte(testFootprintJSON), pf); err != nil {
+		t.Fatalf("unmarshal footprint: %v", err)
+	}
+	tce := &ileapv1.TCE{}
+	tce.SetTceId("tce-1")
+	tce.SetCo2EWtw("10.50")
+	tce.SetIncoterms("FCA")
+	sf := &ileapv1.ShipmentFootprint{}
+	sf.SetShipmentId("shipment-1")
+	sf.SetTces([]*ileapv1.TCE{tce})
+	ext, err := ileap.NewShipmentFootprintExtension(sf)
+	if err != nil {
+		t.Fatalf("create extension: %v", err)
+	}
+	pf.SetExtensions([]*ileapv1.DataModelExtension{ext})
+	return pf
+}
+
+func newFilter(fieldPath string, operator ileapv1.Filter_Operator, value string) *ileapv1.Filter {
+	filter := new(ileapv1.Filter)
+	filter.SetFieldPath(fieldPath)
+	filter.SetOperator(operator)
+	filter.SetValue(value)
+	return filter
+}
+
+func TestMatch(t *testing.T) {
+	pf := newTestFootprint(t)
+	testCases := []struct {
+		name   string
+		filter *ileapv1.Filter
+		want   bool
+	}{
+		{
+			name:   "string is case-insensitive",
+			filter: newFilter("pcf.declaredUnit", ileapv1.Filter_EQ, "LITER"),
+			want:   true,
+		},
+		{
+			name:   "string ne",
+			filter: newFilter("pcf.declaredUnit", ileapv1.Filter_NE, "kilogram"),
+			want:   true,
+		},
+		{
+			name:   "decimal compares numerically",
+			filter: newFilter("pcf.pCfExcludingBiogenic", ileapv1.Filter_GT, "1.6"),
+			want:   true,
+		},
+		{
+			name:   "decimal equality ignores formatting",
+			filter: newFilter("pcf.pCfExcludingBiogenic", ileapv1.Filter_EQ, "1.630"),
+			want:   true,
+		},
+		{
+			name:   "decimal is not compared as string",
+			filter: newFilter("pcf.pCfExcludingBiogenic", ileapv1.Filter_LT, "10"),
+			want:   true,
+		},
+		{
+			name:   "timestamp compares chronologically",
+			filter: newFilter("created", ileapv1.Filter_GE, "2022-03-01T10:32:20+01:00"),
+			want:   true,
+		},
+		{
+			name:   "timestamp with date",
+			filter: newFilter("created", ileapv1.Filter_LT, "2022-03-01"),
+			want:   false,
+		},
+		{
+			name:   "repeated string any",
+			filter: newFilter("companyIds", ileapv1.Filter_EQ, "urn:epc:id:sgln:562958.00000.4"),
+			want:   true,
+		},
+		{
+			name:   "repeated string ne matches if no element is equal",
+			filter: newFilter("companyIds", ileapv1.Filter_NE, "urn:uuid:69585gb6"),
+			want:   false,
+		},
+		{
+			name:   "repeated message any",
+			filter: newFilter("pcf.secondaryEmissionFactorSources.name", ileapv1.Filter_EQ, "glec"),
+			want:   true,
+		},
+		{
+			name:   "number",
+			filter: newFilter("pcf.dqi.coveragePercent", ileapv1.Filter_GE, "78"),
+			want:   true,
+		},
+		{
+			name:   "boolean",
+			filter: newFilter("pcf.packagingEmissionsIncluded", ileapv1.Filter_EQ, "false"),
+			want:   true,
+		},
+		{
+			name:   "missing field",
+			filter: newFilter("updated", ileapv1.Filter_GT, "2020-01-01T00:00:00Z"),
+			want:   false,
+		},
+		{
+			name:   "missing field ne",
+			filter: newFilter("updated", ileapv1.Filter_NE, "2020-01-01T00:00:00Z"),
+			want:   true,
+		},
+		{
+			name:   "extension data",
+			filter: newFilter("extensions.data.tces.co2eWTW", ileapv1.Filter_EQ, "10.5"),
+			want:   true,
+		},
+		{
+			name:   "extension data string",
+			filter: newFilter("extensions.data.tces.incoterms", ileapv1.Filter_EQ, "fca"),
+			want:   true,
+		},
+		{
+			name:   "extension data exponent is compared as string",
+			filter: newFilter("extensions.data.tces.co2eWTW", ileapv1.Filter_EQ, "1.05e1"),
+			want:   false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Match(pf, []*ileapv1.Filter{tc.filter})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Match() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("filters are conjunctive", func(t *testing.T) {
+		got, err := Match(pf, []*ileapv1.Filter{
+			newFilter("productCategoryCpc", ileapv1.Filter_EQ, "83117"),
+			newFilter("pcf.geographyCountry", ileapv1.Filter_EQ, "FR"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got {
+			t.Error("expected no match")
+		}
+	})
+}
+
+func TestMatchEnum(t *testing.T) {
+	for _, tc := range []struct {
+		operator ileapv1.Filter_Operator
+		filter   *ileapv1.Filter
+		want     bool
+	}{
+		{ileapv1.Filter_GE, newFilter("operator", ileapv1.Filter_EQ, "ge"), true},
+		{ileapv1.Filter_GE, newFilter("operator", ileapv1.Filter_NE, "GE"), false},
+		{99, newFilter("operator", ileapv1.Filter_EQ, "99"), true},
+		{99, newFilter("operator", ileapv1.Filter_NE, "EQ"), true},
+	} {
+		// Filter is matched itself, as the footprint has no enum fields.
+		msg := newFilter("", tc.operator, "")
+		got, err := Match(msg, []*ileapv1.Filter{tc.filter})
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", tc.operator, err)
+		}
+		if got != tc.want {
+			t.Errorf("%v %v %s = %v, want %v", tc.operator, tc.filter.GetOperator(),
+				tc.filter.GetValue(), got, tc.want)
+		}
+	}
+}
+
+func TestMatchErrors(t *testing.T) {
+	pf := newTestFootprint(t)
+	testCases := []struct {
+		name   string
+		filter *ileapv1.Filter
+		want   error
+	}{
+		{
+			name:   "unknown field",
+			filter: newFilter("pcf.unknown", ileapv1.Filter_EQ, "x"),
+			want:   ErrUnknownField,
+		},
+		{
+			name:   "invalid decimal",
+			filter: newFilter("pcf.pCfExcludingBiogenic", ileapv1.Filter_GT, "high"),
+			want:   ErrInvalidValue,
+		},
+		{
+			name:   "decimal with exponent",
+			filter: newFilter("pcf.pCfExcludingBiogenic", ileapv1.Filter_GT, "1e1000000000"),
+			want:   ErrInvalidValue,
+		},
+		{
+			name:   "number with exponent",
+			filter: newFilter("pcf.dqi.coveragePercent", ileapv1.Filter_GE, "1e1000000000"),
+			want:   ErrInvalidValue,
+		},
+		{
+			name:   "invalid timestamp",
+			filter: newFilter("created", ileapv1.Filter_GT, "yesterday"),
+			want:   ErrInvalidValue,
+		},
+		{
+			name:   "ordering of booleans",
+			filter: newFilter("pcf.packagingEmissionsIncluded", ileapv1.Filter_LT, "true"),
+			want:   ErrUnsupported,
+		},
+		{
+			name:   "message comparison",
+			filter: newFilter("pcf", ileapv1.Filter_EQ, "x"),
+			want:   ErrUnsupported,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Match(pf, []*ileapv1.Filter{tc.filter})
+			if !errors.Is(err, tc.want) {
+				t.Errorf("got error %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	pf := newTestFootprint(t)
+	testCases := []struct {
+		filter string
+		want   bool
+	}{
+		{filter: "pcf/geographyCountry eq 'FR' or productCategoryCpc eq '83117'", want: true},
+		{filter: "not (pcf/geographyCountry eq 'DE')", want: false},
+		{filter: "pcf/declaredUnit in ('kilogram', 'liter')", want: true},
+		{
+			filter: "pcf/secondaryEmissionFactorSources/any(s:s/name eq 'GLEC' and s/version eq '3.1')",
+			want:   false,
+		},
+		{
+			filter: "pcf/secondaryEmissionFactorSources/any(s:s/name eq 'GLEC' and s/version eq '3.0')",
+			want:   true,
+		},
+		{filter: "companyIds/any(c:c eq 'urn:uuid:69585GB6')", want: true},
+		{filter: "companyIds/any()", want: true},
+		{filter: "productIds/any()", want: false},
+		{filter: "pcf/pCfExcludingBiogenic gt 1.5 and created lt 2023-01-01T00:00:00Z", want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.filter, func(t *testing.T) {
+			expr, err := odata.ParseExpression(tc.filter)
+			if err != nil {
+				t.Fatalf("parse filter: %v", err)
+			}
+			got, err := MatchExpression(pf, expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("MatchExpression() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	de := newTestFootprint(t)
+	fr := newTestFootprint(t)
+	fr.GetPcf().SetGeographyCountry("FR")
+	got, err := Filter(
+		[]*ileapv1.ProductFootprint{de, fr},
+		[]*ileapv1.Filter{newFilter("pcf.geographyCountry", ileapv1.Filter_EQ, "fr")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != fr {
+		t.Errorf("Filter() = %v, want [fr]", got)
+	}
+}
@@ -29,6 +29,11 @@ type ConformanceTestConfig struct {
 	// ExpiredToken is an optional pre-generated expired bearer token.
 	// If empty, TC008 is skipped.
 	ExpiredToken string
+	// CheckConsistency enables ILEAP_Consistency, which runs [ileap.Check] on
+	// all footprints of the server. It is not part of the PACT conformance
+	// tests, and fails on inconsistencies between footprints, such as TCEs
+	// referencing TOCs the server does not list.
+	CheckConsistency bool
 }
 
 // RunConformanceTests runs the full iLEAP/PACT conformance test suite
@@ -172,6 +177,30 @@ func RunConformanceTests(t *testing.T, cfg ConformanceTestConfig) {
 		t.Fatal("no footprint with HOC extension found")
 	})
 
+	t.Run("ILEAP_Consistency", func(t *testing.T) {
+		if !cfg.CheckConsistency {
+			t.Skip("CheckConsistency not enabled")
+		}
+		client := ileap.NewClient(
+			ileap.WithBaseURL(cfg.ServerURL),
+			ileap.WithReuseTokenAuth(&oauth2.Token{AccessToken: token, TokenType: "Bearer"}),
+		)
+		var fps []*ileapv1.ProductFootprint
+		for fp, err := range client.AllFootprints(t.Context(), &ileap.ListFootprintsParams{}) {
+			if err != nil {
+				t.Fatalf("list footprints: %v", err)
+			}
+			fps = append(fps, fp)
+		}
+		report := ileap.Check(fps...)
+		for _, f := range report.Warnings() {
+			t.Logf("%v [%s]", f, f.RuleID)
+		}
+		for _, f := range report.Errors() {
+			t.Errorf("%v [%s]", f, f.RuleID)
+		}
+	})
+
 	t.Run("TC004_ListAllTAD", func(t *testing.T) {
 		body := getJSON(t, cfg.ServerURL, "/2/ileap/tad", token)
 		tads := parseTADList(t, body)
@@ -40,10 +40,11 @@ func TestConformance(t *testing.T) {
 	t.Cleanup(server.Close)
 
 	ileaptest.RunConformanceTests(t, ileaptest.ConformanceTestConfig{
-		ServerURL:    server.URL,
-		Username:     "hello",
-		Password:     "pathfinder",
-		ExpiredToken: expiredToken,
+		ServerURL:        server.URL,
+		Username:         "hello",
+		Password:         "pathfinder",
+		ExpiredToken:     expiredToken,
+		CheckConsistency: true,
 	})
 }
 
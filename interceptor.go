@@ -35,3 +35,26 @@ func AuthForwardInterceptor() connect.UnaryInterceptorFunc {
 		}
 	}
 }
+
+// ValidatorForwardInterceptor returns a Connect client interceptor that copies
+// the ETag and Last-Modified headers of Connect responses to the iLEAP response
+// being served, see [ResponseHeader]. It lets a backend determine the
+// validators of the server's footprint responses.
+func ValidatorForwardInterceptor() connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			resp, err := next(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			if header := ResponseHeader(ctx); header != nil {
+				for _, name := range []string{"ETag", "Last-Modified"} {
+					if value := resp.Header().Get(name); value != "" {
+						header.Set(name, value)
+					}
+				}
+			}
+			return resp, nil
+		}
+	}
+}
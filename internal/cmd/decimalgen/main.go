@@ -0,0 +1,193 @@
+// Command decimalgen generates typed [ileapdecimal.Decimal] accessors for the
+// decimal string fields of the iLEAP messages, declared by their validation
+// pattern.
+//
+// The accessors are generated into the package of the generated messages,
+// in decimal_accessors.go in the output directory, after the proto code
+// generation:
+//
+//	go run ./internal/cmd/decimalgen -o proto/gen/wayplatform/connect/ileap/v1
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/way-platform/ileap-go/ileapdecimal"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// decimalField is a decimal string field of a generated message.
+type decimalField struct {
+	message  string
+	goName   string
+	jsonName string
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("decimalgen: ")
+	output := flag.String("o", ".", "output directory")
+	flag.Parse()
+	fields, err := decimalFields(ileapv1.File_wayplatform_connect_ileap_v1_tce_proto.Package())
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := generate(fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*output, "decimal_accessors.go"), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// decimalFields returns the decimal fields of the messages in a package, in
+// declaration order.
+func decimalFields(pkg protoreflect.FullName) ([]decimalField, error) {
+	var files []protoreflect.FileDescriptor
+	protoregistry.GlobalFiles.RangeFilesByPackage(pkg, func(fd protoreflect.FileDescriptor) bool {
+		files = append(files, fd)
+		return true
+	})
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in package %s", pkg)
+	}
+	var result []decimalField
+	var visit func(protoreflect.MessageDescriptors) error
+	visit = func(messages protoreflect.MessageDescriptors) error {
+		for i := range messages.Len() {
+			md := messages.Get(i)
+			if md.IsMapEntry() {
+				continue
+			}
+			messageName := goCamelCase(string(md.FullName()[len(pkg)+1:]))
+			for j := range md.Fields().Len() {
+				fd := md.Fields().Get(j)
+				if !isDecimalField(fd) {
+					continue
+				}
+				field := decimalField{
+					message:  messageName,
+					goName:   goCamelCase(string(fd.Name())),
+					jsonName: fd.JSONName(),
+				}
+				if err := checkAccessors(md, field); err != nil {
+					return err
+				}
+				result = append(result, field)
+			}
+			if err := visit(md.Messages()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	slices.SortFunc(files, func(a, b protoreflect.FileDescriptor) int {
+		return strings.Compare(a.Path(), b.Path())
+	})
+	for _, fd := range files {
+		if err := visit(fd.Messages()); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// isDecimalField reports whether a field is a singular string field holding
+// decimals, as declared by its validation pattern.
+func isDecimalField(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.StringKind || fd.IsList() || fd.IsMap() {
+		return false
+	}
+	rules, ok := proto.GetExtension(fd.Options(), validate.E_Field).(*validate.FieldRules)
+	return ok && rules.GetString().GetPattern() == ileapdecimal.Pattern
+}
+
+// checkAccessors checks that the generated message has the string accessors
+// of a field, to catch naming mismatches with protoc-gen-go.
+func checkAccessors(md protoreflect.MessageDescriptor, field decimalField) error {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+	if err != nil {
+		return err
+	}
+	t := reflect.TypeOf(mt.New().Interface())
+	for _, method := range []string{"Get" + field.goName, "Set" + field.goName} {
+		if _, ok := t.MethodByName(method); !ok {
+			return fmt.Errorf("%s has no method %s", t, method)
+		}
+	}
+	return nil
+}
+
+// generate returns the formatted source of the accessors.
+func generate(fields []decimalField) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by decimalgen. DO NOT EDIT.\n\n")
+	b.WriteString("package ileapv1\n\n")
+	b.WriteString("import (\n\t\"fmt\"\n\n")
+	b.WriteString("\tileapdecimal \"github.com/way-platform/ileap-go/ileapdecimal\"\n)\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, `
+// Get%[2]sDecimal returns the %[3]s field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *%[1]s) Get%[2]sDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.Get%[2]s())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("%[3]s: %%w", err)
+	}
+	return d, nil
+}
+
+// Set%[2]sDecimal sets the %[3]s field to the canonical string of a decimal.
+func (x *%[1]s) Set%[2]sDecimal(v ileapdecimal.Decimal) {
+	x.Set%[2]s(v.String())
+}
+`, f.message, f.goName, f.jsonName)
+	}
+	return format.Source(b.Bytes())
+}
+
+// goCamelCase returns the Go name of a proto identifier, as generated by
+// protoc-gen-go, e.g. "Co2EWtw" for "co2e_wtw".
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isLower(s[i+1]):
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isLower(s[i+1]):
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isLower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(s) && isLower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+func isLower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
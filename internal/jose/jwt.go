@@ -0,0 +1,275 @@
+// Package jose implements the subset of JOSE (JWS, JWK, JWT) used to verify
+// third-party tokens and client assertions.
+package jose
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/way-platform/ileap-go"
+)
+
+// SupportedAlgorithms are the JWS algorithms that can be verified.
+var SupportedAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// Header is the JOSE header of a JWT.
+type Header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Type      string `json:"typ"`
+}
+
+// JWT is a parsed, not yet verified, compact JWT.
+type JWT struct {
+	Header       Header
+	Claims       map[string]any
+	SigningInput string
+	Signature    []byte
+}
+
+// Parse parses a compact JWT without verifying it.
+func Parse(token string) (*JWT, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid JWT format")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decode JWT header: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("parse JWT header: %w", err)
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode JWT payload: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payloadBytes, &claims); err != nil {
+		return nil, fmt.Errorf("parse JWT claims: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode JWT signature: %w", err)
+	}
+	return &JWT{
+		Header:       header,
+		Claims:       claims,
+		SigningInput: parts[0] + "." + parts[1],
+		Signature:    signature,
+	}, nil
+}
+
+// KeyMatches reports whether a JWK can verify signatures with the algorithm.
+func KeyMatches(jwk ileap.JWK, alg string) bool {
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return false
+	}
+	if jwk.Algorithm != "" && jwk.Algorithm != alg {
+		return false
+	}
+	switch alg[:2] {
+	case "RS", "PS":
+		return jwk.KeyType == "RSA"
+	case "ES":
+		return jwk.KeyType == "EC"
+	default:
+		return jwk.KeyType == "OKP"
+	}
+}
+
+// PublicKey decodes the public key of a JWK.
+func PublicKey(jwk ileap.JWK) (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("decode n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("decode e: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve: %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("decode y: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, fmt.Errorf("invalid EC coordinate length")
+		}
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve: %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %q", jwk.KeyType)
+	}
+}
+
+// VerifySignature verifies a JWS signature with the algorithm and key.
+func VerifySignature(
+	alg string,
+	key crypto.PublicKey,
+	signingInput string,
+	signature []byte,
+) error {
+	if alg == "EdDSA" {
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an Ed25519 key")
+		}
+		if !ed25519.Verify(pub, []byte(signingInput), signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported JWT alg: %s", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "RS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an RSA key")
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+	case "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an RSA key")
+		}
+		return rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		})
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key is not an EC key")
+		}
+		// The curve is implied by the algorithm (RFC 7518, section 3.4).
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if wantSize := map[string]int{"256": 32, "384": 48, "512": 66}[alg[2:]]; size != wantSize {
+			return fmt.Errorf("EC curve does not match %s", alg)
+		}
+		if len(signature) != 2*size {
+			return errors.New("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported JWT alg: %s", alg)
+	}
+}
+
+// NumericDate returns a NumericDate claim as Unix seconds.
+func NumericDate(claims map[string]any, name string) (int64, bool, error) {
+	raw, ok := claims[name]
+	if !ok {
+		return 0, false, nil
+	}
+	v, ok := raw.(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("%s claim is not a number", name)
+	}
+	return int64(v), true, nil
+}
+
+// Audience returns the aud claim, which is either a string or an array of
+// strings.
+func Audience(claims map[string]any) []string {
+	switch v := claims["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, aud := range v {
+			if s, ok := aud.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// Scopes returns the scopes of the scope claim (RFC 8693, section 4.2), or
+// of the scp claim used by some providers.
+func Scopes(claims map[string]any) []string {
+	for _, name := range []string{"scope", "scp"} {
+		switch v := claims[name].(type) {
+		case string:
+			return strings.Fields(v)
+		case []any:
+			result := make([]string, 0, len(v))
+			for _, scope := range v {
+				if s, ok := scope.(string); ok {
+					result = append(result, s)
+				}
+			}
+			return result
+		}
+	}
+	return nil
+}
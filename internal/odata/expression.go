@@ -0,0 +1,683 @@
+package odata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// SyntaxError reports a $filter expression that is not valid OData.
+type SyntaxError struct {
+	// Offset is the byte offset of the error in the expression.
+	Offset int
+	// Message describes the error.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid $filter at offset %d: %s", e.Offset, e.Message)
+}
+
+// UnsupportedError reports a valid OData $filter expression that uses a
+// feature which is not supported, such as a function call or arithmetic.
+type UnsupportedError struct {
+	// Offset is the byte offset of the unsupported feature in the expression,
+	// or -1 if it is not known.
+	Offset int
+	// Feature names the unsupported feature, e.g. "function contains".
+	Feature string
+}
+
+// Error implements the error interface.
+func (e *UnsupportedError) Error() string {
+	if e.Offset < 0 {
+		return "unsupported $filter: " + e.Feature
+	}
+	return fmt.Sprintf("unsupported $filter at offset %d: %s", e.Offset, e.Feature)
+}
+
+// maxExpressionDepth is the maximum nesting depth of "not" operators, groups
+// and lambdas in a $filter expression.
+const maxExpressionDepth = 64
+
+// ParseExpression parses an OData v4 $filter expression.
+//
+// Supported are the comparison operators eq, ne, lt, le, gt and ge, the
+// logical operators and, or and not, the in operator, parenthesized groups
+// and any() lambdas. Literals may be quoted strings, numbers, dates,
+// date-times and booleans. Comparison values are carried as strings; numbers
+// and date-times are kept in their literal form.
+//
+// An empty expression returns nil. Invalid expressions, including expressions
+// nested deeper than 64 levels, return a [*SyntaxError], and valid expressions
+// using other OData features return an [*UnsupportedError].
+func ParseExpression(raw string) (*ileapv1.FilterExpression, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	tokens, err := lexExpression(raw)
+	if err != nil {
+		return nil, err
+	}
+	p := expressionParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != exprTokenEOF {
+		return nil, p.unexpected(tok)
+	}
+	return expr, nil
+}
+
+// Conjuncts returns the comparisons joined by a top-level "and" in the
+// expression as standalone filter pairs. Comparisons within an any() lambda
+// are flattened to the collection path, e.g. "productIds/any(p:p eq 'x')"
+// becomes the pair "productIds eq 'x'". Only lambdas with a single eq, lt,
+// le, gt or ge comparison are flattened.
+//
+// If the expression has other clauses, such as "or", "not" or "in", it returns
+// an [*UnsupportedError] for the first of them together with the pairs of the
+// other clauses, which match a superset of the expression.
+func Conjuncts(expr *ileapv1.FilterExpression) ([]*ileapv1.Filter, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	operands := []*ileapv1.FilterExpression{expr}
+	if expr.HasAnd() {
+		operands = expr.GetAnd().GetOperands()
+	}
+	filters := make([]*ileapv1.Filter, 0, len(operands))
+	var err error
+	unsupported := func(feature string) {
+		if err == nil {
+			err = &UnsupportedError{Offset: -1, Feature: feature + " in filter pairs"}
+		}
+	}
+	for _, operand := range operands {
+		switch operand.WhichExpression() {
+		case ileapv1.FilterExpression_Comparison_case:
+			filters = append(filters, operand.GetComparison())
+		case ileapv1.FilterExpression_Or_case:
+			unsupported("operator or")
+		case ileapv1.FilterExpression_Not_case:
+			unsupported("operator not")
+		case ileapv1.FilterExpression_In_case:
+			unsupported("operator in")
+		case ileapv1.FilterExpression_Any_case:
+			predicate := operand.GetAny().GetPredicate()
+			if !predicate.HasComparison() {
+				unsupported("lambda any without a single comparison")
+				continue
+			}
+			switch op := predicate.GetComparison().GetOperator(); op {
+			case ileapv1.Filter_EQ, ileapv1.Filter_LT, ileapv1.Filter_LE,
+				ileapv1.Filter_GT, ileapv1.Filter_GE:
+			default:
+				// "any element ne v" is not "the collection ne v".
+				unsupported("operator " + strings.ToLower(op.String()) + " in lambda any")
+				continue
+			}
+			filter := new(ileapv1.Filter)
+			filter.SetFieldPath(
+				joinFieldPath(
+					operand.GetAny().GetFieldPath(),
+					predicate.GetComparison().GetFieldPath(),
+				),
+			)
+			filter.SetOperator(predicate.GetComparison().GetOperator())
+			filter.SetValue(predicate.GetComparison().GetValue())
+			filters = append(filters, filter)
+		}
+	}
+	return filters, err
+}
+
+type exprTokenKind int
+
+const (
+	exprTokenEOF exprTokenKind = iota
+	exprTokenIdent
+	exprTokenString
+	exprTokenNumber
+	exprTokenDate
+	exprTokenSlash
+	exprTokenComma
+	exprTokenColon
+	exprTokenLParen
+	exprTokenRParen
+)
+
+type exprToken struct {
+	kind   exprTokenKind
+	text   string
+	offset int
+}
+
+var (
+	numberLiteral   = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	dateLiteral     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	dateTimeLiteral = regexp.MustCompile(
+		`^[0-9]{4}-[0-9]{2}-[0-9]{2}T[0-9]{2}:[0-9]{2}(:[0-9]{2}(\.[0-9]+)?)?(Z|[+-][0-9]{2}:[0-9]{2})$`,
+	)
+)
+
+func lexExpression(input string) ([]exprToken, error) {
+	tokens := make([]exprToken, 0, len(input)/2)
+	for i := 0; i < len(input); {
+		ch := input[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '/':
+			tokens = append(tokens, exprToken{kind: exprTokenSlash, text: "/", offset: i})
+			i++
+		case ch == ',':
+			tokens = append(tokens, exprToken{kind: exprTokenComma, text: ",", offset: i})
+			i++
+		case ch == ':':
+			tokens = append(tokens, exprToken{kind: exprTokenColon, text: ":", offset: i})
+			i++
+		case ch == '(':
+			tokens = append(tokens, exprToken{kind: exprTokenLParen, text: "(", offset: i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, exprToken{kind: exprTokenRParen, text: ")", offset: i})
+			i++
+		case ch == '\'':
+			value, next, ok := readStringLiteral(input, i)
+			if !ok {
+				return nil, &SyntaxError{Offset: i, Message: "unterminated string literal"}
+			}
+			tokens = append(tokens, exprToken{kind: exprTokenString, text: value, offset: i})
+			i = next
+		case isDigit(ch) || (ch == '-' && i+1 < len(input) && isDigit(input[i+1])):
+			start := i
+			i++
+			for i < len(input) && isLiteralPart(input[i]) {
+				i++
+			}
+			tok, err := classifyLiteral(input[start:i], start)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+		case isLetter(ch) || ch == '_' || ch == '$':
+			start := i
+			i++
+			for i < len(input) && (isLetter(input[i]) || isDigit(input[i]) || input[i] == '_') {
+				i++
+			}
+			tokens = append(
+				tokens,
+				exprToken{kind: exprTokenIdent, text: input[start:i], offset: start},
+			)
+		default:
+			return nil, &SyntaxError{Offset: i, Message: fmt.Sprintf("unexpected character %q", ch)}
+		}
+	}
+	tokens = append(tokens, exprToken{kind: exprTokenEOF, offset: len(input)})
+	return tokens, nil
+}
+
+func classifyLiteral(text string, offset int) (exprToken, error) {
+	switch {
+	case numberLiteral.MatchString(text):
+		return exprToken{kind: exprTokenNumber, text: text, offset: offset}, nil
+	case dateLiteral.MatchString(text):
+		if _, err := time.Parse(time.DateOnly, text); err != nil {
+			return exprToken{}, &SyntaxError{
+				Offset:  offset,
+				Message: fmt.Sprintf("invalid date %q", text),
+			}
+		}
+		return exprToken{kind: exprTokenDate, text: text, offset: offset}, nil
+	case dateTimeLiteral.MatchString(text):
+		if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+			if _, err := time.Parse("2006-01-02T15:04Z07:00", text); err != nil {
+				return exprToken{}, &SyntaxError{
+					Offset:  offset,
+					Message: fmt.Sprintf("invalid date-time %q", text),
+				}
+			}
+		}
+		return exprToken{kind: exprTokenDate, text: text, offset: offset}, nil
+	default:
+		return exprToken{}, &SyntaxError{
+			Offset:  offset,
+			Message: fmt.Sprintf("invalid literal %q", text),
+		}
+	}
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func isLetter(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isLiteralPart(ch byte) bool {
+	return isDigit(ch) || isLetter(ch) || ch == '.' || ch == ':' || ch == '-' || ch == '+'
+}
+
+type expressionParser struct {
+	tokens []exprToken
+	pos    int
+	// depth is the current nesting depth of "not" operators, groups and
+	// lambdas.
+	depth int
+	// aliases are the lambda variables in scope, innermost last.
+	aliases []string
+}
+
+// nest enters a nesting level at tok, and returns a [*SyntaxError] when the
+// expression is nested too deeply. Callers must call p.unnest when leaving
+// the level.
+func (p *expressionParser) nest(tok exprToken) error {
+	p.depth++
+	if p.depth > maxExpressionDepth {
+		return &SyntaxError{
+			Offset:  tok.offset,
+			Message: fmt.Sprintf("expression nested deeper than %d levels", maxExpressionDepth),
+		}
+	}
+	return nil
+}
+
+func (p *expressionParser) unnest() {
+	p.depth--
+}
+
+func (p *expressionParser) parseOr() (*ileapv1.FilterExpression, error) {
+	return p.parseBinary(
+		"or",
+		(*expressionParser).parseAnd,
+		func(expr *ileapv1.FilterExpression, operands []*ileapv1.FilterExpression) {
+			group := new(ileapv1.FilterExpression_Operands)
+			group.SetOperands(operands)
+			expr.SetOr(group)
+		},
+	)
+}
+
+func (p *expressionParser) parseAnd() (*ileapv1.FilterExpression, error) {
+	return p.parseBinary(
+		"and",
+		(*expressionParser).parseUnary,
+		func(expr *ileapv1.FilterExpression, operands []*ileapv1.FilterExpression) {
+			group := new(ileapv1.FilterExpression_Operands)
+			group.SetOperands(operands)
+			expr.SetAnd(group)
+		},
+	)
+}
+
+// parseBinary parses operands joined by a left-associative keyword operator,
+// flattening chains such as "a and b and c" into a single group.
+func (p *expressionParser) parseBinary(
+	keyword string,
+	next func(*expressionParser) (*ileapv1.FilterExpression, error),
+	set func(*ileapv1.FilterExpression, []*ileapv1.FilterExpression),
+) (*ileapv1.FilterExpression, error) {
+	first, err := next(p)
+	if err != nil {
+		return nil, err
+	}
+	operands := []*ileapv1.FilterExpression{first}
+	for p.matchKeyword(keyword) {
+		operand, err := next(p)
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	expr := new(ileapv1.FilterExpression)
+	set(expr, operands)
+	return expr, nil
+}
+
+func (p *expressionParser) parseUnary() (*ileapv1.FilterExpression, error) {
+	if tok := p.peek(); p.matchKeyword("not") {
+		if err := p.nest(tok); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		expr := new(ileapv1.FilterExpression)
+		expr.SetNot(operand)
+		return expr, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (*ileapv1.FilterExpression, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case exprTokenLParen:
+		p.pos++
+		if err := p.nest(tok); err != nil {
+			return nil, err
+		}
+		defer p.unnest()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(exprTokenRParen); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case exprTokenIdent:
+		if p.peekN(1).kind == exprTokenLParen {
+			return nil, &UnsupportedError{Offset: tok.offset, Feature: "function " + tok.text}
+		}
+		if isKeywordLiteral(tok.text) {
+			return nil, &UnsupportedError{
+				Offset:  tok.offset,
+				Feature: "literal on the left side of a comparison",
+			}
+		}
+		return p.parsePathExpression()
+	case exprTokenString, exprTokenNumber, exprTokenDate:
+		return nil, &UnsupportedError{
+			Offset:  tok.offset,
+			Feature: "literal on the left side of a comparison",
+		}
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parsePathExpression parses a comparison, an in test or a lambda starting
+// with a property path.
+func (p *expressionParser) parsePathExpression() (*ileapv1.FilterExpression, error) {
+	start := p.peek()
+	segments, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == exprTokenSlash {
+		// The path is followed by a lambda operator.
+		p.pos++
+		lambda, ok := p.matchIdent()
+		if !ok {
+			return nil, p.unexpected(p.peek())
+		}
+		switch strings.ToLower(lambda.text) {
+		case "any":
+		case "all":
+			return nil, &UnsupportedError{Offset: lambda.offset, Feature: "lambda all"}
+		default:
+			return nil, &UnsupportedError{
+				Offset:  lambda.offset,
+				Feature: "path segment " + lambda.text,
+			}
+		}
+		fieldPath, err := p.resolvePath(start, segments)
+		if err != nil {
+			return nil, err
+		}
+		return p.parseAny(fieldPath)
+	}
+	fieldPath, err := p.resolvePath(start, segments)
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.peek()
+	if opTok.kind != exprTokenIdent {
+		return nil, p.unexpected(opTok)
+	}
+	if strings.EqualFold(opTok.text, "in") {
+		p.pos++
+		return p.parseIn(fieldPath)
+	}
+	operator, ok := operatorFromToken(opTok.text)
+	if !ok {
+		if isUnsupportedOperator(opTok.text) {
+			return nil, &UnsupportedError{Offset: opTok.offset, Feature: "operator " + opTok.text}
+		}
+		return nil, p.unexpected(opTok)
+	}
+	p.pos++
+	value, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	filter := new(ileapv1.Filter)
+	filter.SetFieldPath(fieldPath)
+	filter.SetOperator(operator)
+	filter.SetValue(value)
+	expr := new(ileapv1.FilterExpression)
+	expr.SetComparison(filter)
+	return expr, nil
+}
+
+func (p *expressionParser) parseAny(fieldPath string) (*ileapv1.FilterExpression, error) {
+	tok := p.peek()
+	if err := p.expect(exprTokenLParen); err != nil {
+		return nil, err
+	}
+	if err := p.nest(tok); err != nil {
+		return nil, err
+	}
+	defer p.unnest()
+	lambda := new(ileapv1.FilterExpression_Any)
+	lambda.SetFieldPath(fieldPath)
+	if p.peek().kind != exprTokenRParen {
+		alias, ok := p.matchIdent()
+		if !ok {
+			return nil, p.unexpected(p.peek())
+		}
+		if err := p.expect(exprTokenColon); err != nil {
+			return nil, err
+		}
+		p.aliases = append(p.aliases, alias.text)
+		predicate, err := p.parseOr()
+		p.aliases = p.aliases[:len(p.aliases)-1]
+		if err != nil {
+			return nil, err
+		}
+		lambda.SetPredicate(predicate)
+	}
+	if err := p.expect(exprTokenRParen); err != nil {
+		return nil, err
+	}
+	expr := new(ileapv1.FilterExpression)
+	expr.SetAny(lambda)
+	return expr, nil
+}
+
+func (p *expressionParser) parseIn(fieldPath string) (*ileapv1.FilterExpression, error) {
+	if err := p.expect(exprTokenLParen); err != nil {
+		return nil, err
+	}
+	var values []string
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if !p.matchKind(exprTokenComma) {
+			break
+		}
+	}
+	if err := p.expect(exprTokenRParen); err != nil {
+		return nil, err
+	}
+	in := new(ileapv1.FilterExpression_In)
+	in.SetFieldPath(fieldPath)
+	in.SetValues(values)
+	expr := new(ileapv1.FilterExpression)
+	expr.SetIn(in)
+	return expr, nil
+}
+
+// parsePath parses slash-separated property path segments, stopping before a
+// lambda operator.
+func (p *expressionParser) parsePath() ([]string, error) {
+	first, ok := p.matchIdent()
+	if !ok {
+		return nil, p.unexpected(p.peek())
+	}
+	segments := []string{first.text}
+	for p.peek().kind == exprTokenSlash {
+		next := p.peekN(1)
+		if next.kind != exprTokenIdent {
+			return nil, p.unexpected(next)
+		}
+		if p.peekN(2).kind == exprTokenLParen {
+			// A lambda or function call, handled by the caller.
+			break
+		}
+		p.pos += 2
+		segments = append(segments, next.text)
+	}
+	return segments, nil
+}
+
+// resolvePath returns the dot notation field path for path segments. Within
+// a lambda, paths must start with the innermost lambda variable and are
+// returned relative to the collection element.
+func (p *expressionParser) resolvePath(start exprToken, segments []string) (string, error) {
+	if len(p.aliases) == 0 {
+		if strings.HasPrefix(segments[0], "$") {
+			return "", &UnsupportedError{Offset: start.offset, Feature: "path " + segments[0]}
+		}
+		return strings.Join(segments, "."), nil
+	}
+	alias := p.aliases[len(p.aliases)-1]
+	if segments[0] != alias {
+		return "", &UnsupportedError{
+			Offset: start.offset,
+			Feature: fmt.Sprintf(
+				"path %q not relative to lambda variable %q",
+				strings.Join(segments, "/"),
+				alias,
+			),
+		}
+	}
+	return strings.Join(segments[1:], "."), nil
+}
+
+func (p *expressionParser) parseLiteral() (string, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case exprTokenString, exprTokenNumber, exprTokenDate:
+		p.pos++
+		return tok.text, nil
+	case exprTokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "true", "false":
+			p.pos++
+			return strings.ToLower(tok.text), nil
+		case "null":
+			return "", &UnsupportedError{Offset: tok.offset, Feature: "literal null"}
+		}
+		if p.peekN(1).kind == exprTokenLParen {
+			return "", &UnsupportedError{Offset: tok.offset, Feature: "function " + tok.text}
+		}
+		return "", &UnsupportedError{Offset: tok.offset, Feature: "comparison with a property path"}
+	default:
+		return "", p.unexpected(tok)
+	}
+}
+
+func (p *expressionParser) expect(kind exprTokenKind) error {
+	if !p.matchKind(kind) {
+		return p.unexpected(p.peek())
+	}
+	return nil
+}
+
+func (p *expressionParser) unexpected(tok exprToken) error {
+	if tok.kind == exprTokenEOF {
+		return &SyntaxError{Offset: tok.offset, Message: "unexpected end of expression"}
+	}
+	text := tok.text
+	if tok.kind == exprTokenString {
+		text = "'" + strings.ReplaceAll(text, "'", "''") + "'"
+	}
+	return &SyntaxError{Offset: tok.offset, Message: fmt.Sprintf("unexpected %q", text)}
+}
+
+func (p *expressionParser) matchKeyword(keyword string) bool {
+	tok := p.peek()
+	if tok.kind != exprTokenIdent || !strings.EqualFold(tok.text, keyword) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *expressionParser) matchKind(kind exprTokenKind) bool {
+	if p.peek().kind != kind {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *expressionParser) matchIdent() (exprToken, bool) {
+	tok := p.peek()
+	if tok.kind != exprTokenIdent {
+		return exprToken{}, false
+	}
+	p.pos++
+	return tok, true
+}
+
+func (p *expressionParser) peek() exprToken {
+	return p.peekN(0)
+}
+
+func (p *expressionParser) peekN(offset int) exprToken {
+	index := p.pos + offset
+	if index >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[index]
+}
+
+func isKeywordLiteral(text string) bool {
+	switch strings.ToLower(text) {
+	case "true", "false", "null":
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnsupportedOperator reports whether text is a valid OData operator that
+// is not supported.
+func isUnsupportedOperator(text string) bool {
+	switch strings.ToLower(text) {
+	case "has", "add", "sub", "mul", "div", "divby", "mod":
+		return true
+	default:
+		return false
+	}
+}
+
+func joinFieldPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	default:
+		return prefix + "." + path
+	}
+}
@@ -0,0 +1,258 @@
+package odata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+func TestParseExpression(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "empty",
+			in:   "  ",
+			want: "",
+		},
+		{
+			name: "comparison",
+			in:   "productCategoryCpc eq '83117'",
+			want: "productCategoryCpc|EQ|83117",
+		},
+		{
+			name: "and chain is flattened",
+			in:   "a eq '1' and b ne '2' and c lt '3'",
+			want: "and(a|EQ|1, b|NE|2, c|LT|3)",
+		},
+		{
+			name: "or binds weaker than and",
+			in:   "a eq '1' or b eq '2' and c eq '3'",
+			want: "or(a|EQ|1, and(b|EQ|2, c|EQ|3))",
+		},
+		{
+			name: "groups",
+			in:   "(a eq '1' or b eq '2') and c eq '3'",
+			want: "and(or(a|EQ|1, b|EQ|2), c|EQ|3)",
+		},
+		{
+			name: "not",
+			in:   "not (a eq '1' or b eq '2')",
+			want: "not(or(a|EQ|1, b|EQ|2))",
+		},
+		{
+			name: "in",
+			in:   "pcf/geographyCountry in ('DE', 'FR')",
+			want: "in(pcf.geographyCountry, DE, FR)",
+		},
+		{
+			name: "numeric literals",
+			in:   "a gt 1.5 and b le -3 and c eq 2e10",
+			want: "and(a|GT|1.5, b|LE|-3, c|EQ|2e10)",
+		},
+		{
+			name: "date and date-time literals",
+			in:   "created ge 2024-01-01 and updated lt 2024-06-30T12:00:00.000+02:00",
+			want: "and(created|GE|2024-01-01, updated|LT|2024-06-30T12:00:00.000+02:00)",
+		},
+		{
+			name: "boolean literal",
+			in:   "isActive eq TRUE",
+			want: "isActive|EQ|true",
+		},
+		{
+			name: "escaped quote",
+			in:   "name eq 'O''Brien'",
+			want: "name|EQ|O'Brien",
+		},
+		{
+			name: "any with primitive element",
+			in:   "productIds/any(p:p eq 'urn:1')",
+			want: "any(productIds, |EQ|urn:1)",
+		},
+		{
+			name: "any with compound predicate",
+			in:   "tces/any(t:(t/origin/city eq 'Berlin' and t/mass gt 10))",
+			want: "any(tces, and(origin.city|EQ|Berlin, mass|GT|10))",
+		},
+		{
+			name: "nested any",
+			in:   "tces/any(t:t/legs/any(l:l/mode eq 'Road'))",
+			want: "any(tces, any(legs, mode|EQ|Road))",
+		},
+		{
+			name: "any without predicate",
+			in:   "companyIds/any()",
+			want: "any(companyIds)",
+		},
+		{
+			name: "keywords are case-insensitive",
+			in:   "A EQ '1' OR NOT B Ne '2'",
+			want: "or(A|EQ|1, not(B|NE|2))",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseExpression(tc.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if s := formatExpression(got); s != tc.want {
+				t.Errorf("got %s, want %s", s, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		in          string
+		unsupported bool
+		offset      int
+	}{
+		{name: "missing value", in: "a eq", offset: 4},
+		{name: "unknown operator", in: "a weird '1'", offset: 2},
+		{name: "unterminated string", in: "a eq 'x", offset: 5},
+		{name: "unbalanced parenthesis", in: "(a eq '1'", offset: 9},
+		{name: "trailing tokens", in: "a eq '1' b", offset: 9},
+		{name: "dangling and", in: "a eq '1' and", offset: 12},
+		{name: "invalid date", in: "a eq 2024-13-01", offset: 5},
+		{name: "invalid character", in: "a eq '1' & b eq '2'", offset: 9},
+		{name: "function", in: "contains(a, 'x')", unsupported: true, offset: 0},
+		{name: "lambda all", in: "a/all(x:x eq '1')", unsupported: true, offset: 2},
+		{name: "arithmetic", in: "a add 1 eq 2", unsupported: true, offset: 2},
+		{name: "null literal", in: "a eq null", unsupported: true, offset: 5},
+		{name: "property on right side", in: "a eq b", unsupported: true, offset: 5},
+		{name: "literal on left side", in: "'x' eq a", unsupported: true, offset: 0},
+		{
+			name:        "outer path in lambda",
+			in:          "a/any(x:b eq '1')",
+			unsupported: true,
+			offset:      8,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseExpression(tc.in)
+			var syntaxErr *SyntaxError
+			var unsupportedErr *UnsupportedError
+			switch {
+			case tc.unsupported && errors.As(err, &unsupportedErr):
+				if unsupportedErr.Offset != tc.offset {
+					t.Errorf("offset = %d, want %d (%v)", unsupportedErr.Offset, tc.offset, err)
+				}
+			case !tc.unsupported && errors.As(err, &syntaxErr):
+				if syntaxErr.Offset != tc.offset {
+					t.Errorf("offset = %d, want %d (%v)", syntaxErr.Offset, tc.offset, err)
+				}
+			default:
+				t.Fatalf("unexpected error %v (unsupported=%v)", err, tc.unsupported)
+			}
+		})
+	}
+}
+
+func TestConjuncts(t *testing.T) {
+	expr, err := ParseExpression(
+		"a eq '1' and (b eq '2' or c eq '3') and productIds/any(p:p eq 'x') and d in ('4')",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filters, err := Conjuncts(expr)
+	var unsupportedErr *UnsupportedError
+	if !errors.As(err, &unsupportedErr) {
+		t.Errorf("expected *UnsupportedError for or and in clauses, got %v", err)
+	}
+	assertFilterSet(t, filters, "a|EQ|1", "productIds|EQ|x")
+
+	expr, err = ParseExpression("a eq '1' and productIds/any(p:p eq 'x')")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filters, err = Conjuncts(expr)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	assertFilterSet(t, filters, "a|EQ|1", "productIds|EQ|x")
+
+	for _, in := range []string{
+		"a eq '1' or b eq '2'",
+		"not a eq '1'",
+		"a in ('1')",
+		"productIds/any(p:p ne 'x')",
+		"productIds/any(p:p eq 'x' or p eq 'y')",
+		"productIds/any(p:not (p eq 'x'))",
+	} {
+		expr, err = ParseExpression(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filters, err = Conjuncts(expr)
+		if !errors.As(err, &unsupportedErr) {
+			t.Errorf("%s: expected *UnsupportedError, got %v", in, err)
+		}
+		assertFilterSet(t, filters)
+	}
+}
+
+func TestParseExpressionDepth(t *testing.T) {
+	nested := func(n int) string {
+		return strings.Repeat("(", n) + "a eq '1'" + strings.Repeat(")", n)
+	}
+	if _, err := ParseExpression(nested(maxExpressionDepth)); err != nil {
+		t.Errorf("unexpected error at maximum depth: %v", err)
+	}
+	for _, in := range []string{
+		nested(maxExpressionDepth + 1),
+		strings.Repeat("not ", 2_000_000) + "a eq '1'",
+		"a/any(x:" + strings.Repeat("x/any(x:", maxExpressionDepth) + "x eq '1'" +
+			strings.Repeat(")", maxExpressionDepth+1),
+	} {
+		var syntaxErr *SyntaxError
+		if _, err := ParseExpression(in); !errors.As(err, &syntaxErr) {
+			t.Errorf("expected *SyntaxError for deep nesting, got %v", err)
+		}
+	}
+}
+
+// formatExpression formats an expression in a compact prefix notation.
+func formatExpression(expr *ileapv1.FilterExpression) string {
+	formatOperands := func(operands []*ileapv1.FilterExpression) string {
+		parts := make([]string, 0, len(operands))
+		for _, operand := range operands {
+			parts = append(parts, formatExpression(operand))
+		}
+		return strings.Join(parts, ", ")
+	}
+	switch expr.WhichExpression() {
+	case ileapv1.FilterExpression_Comparison_case:
+		c := expr.GetComparison()
+		return c.GetFieldPath() + "|" + c.GetOperator().String() + "|" + c.GetValue()
+	case ileapv1.FilterExpression_And_case:
+		return "and(" + formatOperands(expr.GetAnd().GetOperands()) + ")"
+	case ileapv1.FilterExpression_Or_case:
+		return "or(" + formatOperands(expr.GetOr().GetOperands()) + ")"
+	case ileapv1.FilterExpression_Not_case:
+		return "not(" + formatExpression(expr.GetNot()) + ")"
+	case ileapv1.FilterExpression_In_case:
+		in := expr.GetIn()
+		return "in(" + strings.Join(
+			append([]string{in.GetFieldPath()}, in.GetValues()...),
+			", ",
+		) + ")"
+	case ileapv1.FilterExpression_Any_case:
+		lambda := expr.GetAny()
+		if !lambda.HasPredicate() {
+			return "any(" + lambda.GetFieldPath() + ")"
+		}
+		return "any(" + lambda.GetFieldPath() + ", " + formatExpression(lambda.GetPredicate()) + ")"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,40 @@
+// Package testfixture loads the iLEAP messages of testdata/fixture.json, the
+// fixture shared by the tests of all packages.
+package testfixture
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Load unmarshals the message at key in the fixture into m. Tests apply their
+// deltas to the loaded message.
+func Load(t testing.TB, key string, m proto.Message) {
+	t.Helper()
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("locate fixture: no caller information")
+	}
+	path := filepath.Join(filepath.Dir(file), "..", "..", "testdata", "fixture.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var fixture map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("unmarshal fixture: %v", err)
+	}
+	raw, ok := fixture[key]
+	if !ok {
+		t.Fatalf("fixture has no %s", key)
+	}
+	if err := protojson.Unmarshal(raw, m); err != nil {
+		t.Fatalf("unmarshal fixture %s: %v", key, err)
+	}
+}
@@ -26,6 +26,10 @@ const (
 	// or is otherwise malformed.
 	OAuthErrorCodeInvalidRequest OAuthErrorCode = "invalid_request"
 
+	// OAuthErrorCodeInvalidClient means client authentication failed, e.g. due to
+	// an unknown client or invalid client credentials.
+	OAuthErrorCodeInvalidClient OAuthErrorCode = "invalid_client"
+
 	// OAuthErrorCodeUnauthorizedClient means the client is not authorized to request an access
 	// token using this method.
 	OAuthErrorCodeUnauthorizedClient OAuthErrorCode = "unauthorized_client"
@@ -51,4 +55,8 @@ const (
 	// OAuthErrorCodeUnsupportedGrantType means the authorization server does not support
 	// the authorization grant type used.
 	OAuthErrorCodeUnsupportedGrantType OAuthErrorCode = "unsupported_grant_type"
+
+	// OAuthErrorCodeUnsupportedTokenType means the authorization server does not
+	// support the revocation of the presented token type (RFC 7009).
+	OAuthErrorCodeUnsupportedTokenType OAuthErrorCode = "unsupported_token_type"
 )
@@ -0,0 +1,654 @@
+// Code generated by decimalgen. DO NOT EDIT.
+
+package ileapv1
+
+import (
+	"fmt"
+
+	ileapdecimal "github.com/way-platform/ileap-go/ileapdecimal"
+)
+
+// GetUnitaryProductAmountDecimal returns the unitaryProductAmount field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetUnitaryProductAmountDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetUnitaryProductAmount())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("unitaryProductAmount: %w", err)
+	}
+	return d, nil
+}
+
+// SetUnitaryProductAmountDecimal sets the unitaryProductAmount field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetUnitaryProductAmountDecimal(v ileapdecimal.Decimal) {
+	x.SetUnitaryProductAmount(v.String())
+}
+
+// GetPCfExcludingBiogenicDecimal returns the pCfExcludingBiogenic field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetPCfExcludingBiogenicDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetPCfExcludingBiogenic())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("pCfExcludingBiogenic: %w", err)
+	}
+	return d, nil
+}
+
+// SetPCfExcludingBiogenicDecimal sets the pCfExcludingBiogenic field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetPCfExcludingBiogenicDecimal(v ileapdecimal.Decimal) {
+	x.SetPCfExcludingBiogenic(v.String())
+}
+
+// GetPCfIncludingBiogenicDecimal returns the pCfIncludingBiogenic field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetPCfIncludingBiogenicDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetPCfIncludingBiogenic())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("pCfIncludingBiogenic: %w", err)
+	}
+	return d, nil
+}
+
+// SetPCfIncludingBiogenicDecimal sets the pCfIncludingBiogenic field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetPCfIncludingBiogenicDecimal(v ileapdecimal.Decimal) {
+	x.SetPCfIncludingBiogenic(v.String())
+}
+
+// GetFossilGhgEmissionsDecimal returns the fossilGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetFossilGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetFossilGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("fossilGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetFossilGhgEmissionsDecimal sets the fossilGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetFossilGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetFossilGhgEmissions(v.String())
+}
+
+// GetFossilCarbonContentDecimal returns the fossilCarbonContent field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetFossilCarbonContentDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetFossilCarbonContent())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("fossilCarbonContent: %w", err)
+	}
+	return d, nil
+}
+
+// SetFossilCarbonContentDecimal sets the fossilCarbonContent field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetFossilCarbonContentDecimal(v ileapdecimal.Decimal) {
+	x.SetFossilCarbonContent(v.String())
+}
+
+// GetBiogenicCarbonContentDecimal returns the biogenicCarbonContent field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetBiogenicCarbonContentDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetBiogenicCarbonContent())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("biogenicCarbonContent: %w", err)
+	}
+	return d, nil
+}
+
+// SetBiogenicCarbonContentDecimal sets the biogenicCarbonContent field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetBiogenicCarbonContentDecimal(v ileapdecimal.Decimal) {
+	x.SetBiogenicCarbonContent(v.String())
+}
+
+// GetDLucGhgEmissionsDecimal returns the dLucGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetDLucGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetDLucGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("dLucGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetDLucGhgEmissionsDecimal sets the dLucGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetDLucGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetDLucGhgEmissions(v.String())
+}
+
+// GetLandManagementGhgEmissionsDecimal returns the landManagementGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetLandManagementGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetLandManagementGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("landManagementGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetLandManagementGhgEmissionsDecimal sets the landManagementGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetLandManagementGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetLandManagementGhgEmissions(v.String())
+}
+
+// GetOtherBiogenicGhgEmissionsDecimal returns the otherBiogenicGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetOtherBiogenicGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetOtherBiogenicGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("otherBiogenicGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetOtherBiogenicGhgEmissionsDecimal sets the otherBiogenicGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetOtherBiogenicGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetOtherBiogenicGhgEmissions(v.String())
+}
+
+// GetILucGhgEmissionsDecimal returns the iLucGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetILucGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetILucGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("iLucGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetILucGhgEmissionsDecimal sets the iLucGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetILucGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetILucGhgEmissions(v.String())
+}
+
+// GetBiogenicCarbonWithdrawalDecimal returns the biogenicCarbonWithdrawal field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetBiogenicCarbonWithdrawalDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetBiogenicCarbonWithdrawal())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("biogenicCarbonWithdrawal: %w", err)
+	}
+	return d, nil
+}
+
+// SetBiogenicCarbonWithdrawalDecimal sets the biogenicCarbonWithdrawal field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetBiogenicCarbonWithdrawalDecimal(v ileapdecimal.Decimal) {
+	x.SetBiogenicCarbonWithdrawal(v.String())
+}
+
+// GetAircraftGhgEmissionsDecimal returns the aircraftGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetAircraftGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetAircraftGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("aircraftGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetAircraftGhgEmissionsDecimal sets the aircraftGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetAircraftGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetAircraftGhgEmissions(v.String())
+}
+
+// GetPackagingGhgEmissionsDecimal returns the packagingGhgEmissions field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *CarbonFootprint) GetPackagingGhgEmissionsDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetPackagingGhgEmissions())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("packagingGhgEmissions: %w", err)
+	}
+	return d, nil
+}
+
+// SetPackagingGhgEmissionsDecimal sets the packagingGhgEmissions field to the canonical string of a decimal.
+func (x *CarbonFootprint) SetPackagingGhgEmissionsDecimal(v ileapdecimal.Decimal) {
+	x.SetPackagingGhgEmissions(v.String())
+}
+
+// GetRelativeShareDecimal returns the relativeShare field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *EnergyCarrier) GetRelativeShareDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetRelativeShare())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("relativeShare: %w", err)
+	}
+	return d, nil
+}
+
+// SetRelativeShareDecimal sets the relativeShare field to the canonical string of a decimal.
+func (x *EnergyCarrier) SetRelativeShareDecimal(v ileapdecimal.Decimal) {
+	x.SetRelativeShare(v.String())
+}
+
+// GetEnergyConsumptionDecimal returns the energyConsumption field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *EnergyCarrier) GetEnergyConsumptionDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetEnergyConsumption())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("energyConsumption: %w", err)
+	}
+	return d, nil
+}
+
+// SetEnergyConsumptionDecimal sets the energyConsumption field to the canonical string of a decimal.
+func (x *EnergyCarrier) SetEnergyConsumptionDecimal(v ileapdecimal.Decimal) {
+	x.SetEnergyConsumption(v.String())
+}
+
+// GetEmissionFactorWtwDecimal returns the emissionFactorWTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *EnergyCarrier) GetEmissionFactorWtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetEmissionFactorWtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("emissionFactorWTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetEmissionFactorWtwDecimal sets the emissionFactorWTW field to the canonical string of a decimal.
+func (x *EnergyCarrier) SetEmissionFactorWtwDecimal(v ileapdecimal.Decimal) {
+	x.SetEmissionFactorWtw(v.String())
+}
+
+// GetEmissionFactorTtwDecimal returns the emissionFactorTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *EnergyCarrier) GetEmissionFactorTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetEmissionFactorTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("emissionFactorTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetEmissionFactorTtwDecimal sets the emissionFactorTTW field to the canonical string of a decimal.
+func (x *EnergyCarrier) SetEmissionFactorTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetEmissionFactorTtw(v.String())
+}
+
+// GetFeedstockShareDecimal returns the feedstockShare field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *Feedstock) GetFeedstockShareDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetFeedstockShare())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("feedstockShare: %w", err)
+	}
+	return d, nil
+}
+
+// SetFeedstockShareDecimal sets the feedstockShare field to the canonical string of a decimal.
+func (x *Feedstock) SetFeedstockShareDecimal(v ileapdecimal.Decimal) {
+	x.SetFeedstockShare(v.String())
+}
+
+// GetActualDecimal returns the actual field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *GLECDistance) GetActualDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetActual())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("actual: %w", err)
+	}
+	return d, nil
+}
+
+// SetActualDecimal sets the actual field to the canonical string of a decimal.
+func (x *GLECDistance) SetActualDecimal(v ileapdecimal.Decimal) {
+	x.SetActual(v.String())
+}
+
+// GetGcdDecimal returns the gcd field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *GLECDistance) GetGcdDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetGcd())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("gcd: %w", err)
+	}
+	return d, nil
+}
+
+// SetGcdDecimal sets the gcd field to the canonical string of a decimal.
+func (x *GLECDistance) SetGcdDecimal(v ileapdecimal.Decimal) {
+	x.SetGcd(v.String())
+}
+
+// GetSfdDecimal returns the sfd field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *GLECDistance) GetSfdDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetSfd())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("sfd: %w", err)
+	}
+	return d, nil
+}
+
+// SetSfdDecimal sets the sfd field to the canonical string of a decimal.
+func (x *GLECDistance) SetSfdDecimal(v ileapdecimal.Decimal) {
+	x.SetSfd(v.String())
+}
+
+// GetCo2EIntensityWtwDecimal returns the co2eIntensityWTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *HOC) GetCo2EIntensityWtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2EIntensityWtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eIntensityWTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2EIntensityWtwDecimal sets the co2eIntensityWTW field to the canonical string of a decimal.
+func (x *HOC) SetCo2EIntensityWtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2EIntensityWtw(v.String())
+}
+
+// GetCo2EIntensityTtwDecimal returns the co2eIntensityTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *HOC) GetCo2EIntensityTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2EIntensityTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eIntensityTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2EIntensityTtwDecimal sets the co2eIntensityTTW field to the canonical string of a decimal.
+func (x *HOC) SetCo2EIntensityTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2EIntensityTtw(v.String())
+}
+
+// GetLatDecimal returns the lat field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *Location) GetLatDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetLat())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("lat: %w", err)
+	}
+	return d, nil
+}
+
+// SetLatDecimal sets the lat field to the canonical string of a decimal.
+func (x *Location) SetLatDecimal(v ileapdecimal.Decimal) {
+	x.SetLat(v.String())
+}
+
+// GetLngDecimal returns the lng field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *Location) GetLngDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetLng())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("lng: %w", err)
+	}
+	return d, nil
+}
+
+// SetLngDecimal sets the lng field to the canonical string of a decimal.
+func (x *Location) SetLngDecimal(v ileapdecimal.Decimal) {
+	x.SetLng(v.String())
+}
+
+// GetMassDecimal returns the mass field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *ShipmentFootprint) GetMassDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetMass())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("mass: %w", err)
+	}
+	return d, nil
+}
+
+// SetMassDecimal sets the mass field to the canonical string of a decimal.
+func (x *ShipmentFootprint) SetMassDecimal(v ileapdecimal.Decimal) {
+	x.SetMass(v.String())
+}
+
+// GetVolumeDecimal returns the volume field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *ShipmentFootprint) GetVolumeDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetVolume())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("volume: %w", err)
+	}
+	return d, nil
+}
+
+// SetVolumeDecimal sets the volume field to the canonical string of a decimal.
+func (x *ShipmentFootprint) SetVolumeDecimal(v ileapdecimal.Decimal) {
+	x.SetVolume(v.String())
+}
+
+// GetMassDecimal returns the mass field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TAD) GetMassDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetMass())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("mass: %w", err)
+	}
+	return d, nil
+}
+
+// SetMassDecimal sets the mass field to the canonical string of a decimal.
+func (x *TAD) SetMassDecimal(v ileapdecimal.Decimal) {
+	x.SetMass(v.String())
+}
+
+// GetLoadFactorDecimal returns the loadFactor field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TAD) GetLoadFactorDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetLoadFactor())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("loadFactor: %w", err)
+	}
+	return d, nil
+}
+
+// SetLoadFactorDecimal sets the loadFactor field to the canonical string of a decimal.
+func (x *TAD) SetLoadFactorDecimal(v ileapdecimal.Decimal) {
+	x.SetLoadFactor(v.String())
+}
+
+// GetEmptyDistanceFactorDecimal returns the emptyDistanceFactor field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TAD) GetEmptyDistanceFactorDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetEmptyDistanceFactor())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("emptyDistanceFactor: %w", err)
+	}
+	return d, nil
+}
+
+// SetEmptyDistanceFactorDecimal sets the emptyDistanceFactor field to the canonical string of a decimal.
+func (x *TAD) SetEmptyDistanceFactorDecimal(v ileapdecimal.Decimal) {
+	x.SetEmptyDistanceFactor(v.String())
+}
+
+// GetMassDecimal returns the mass field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetMassDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetMass())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("mass: %w", err)
+	}
+	return d, nil
+}
+
+// SetMassDecimal sets the mass field to the canonical string of a decimal.
+func (x *TCE) SetMassDecimal(v ileapdecimal.Decimal) {
+	x.SetMass(v.String())
+}
+
+// GetPackagingOrTrEqAmountDecimal returns the packagingOrTrEqAmount field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetPackagingOrTrEqAmountDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetPackagingOrTrEqAmount())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("packagingOrTrEqAmount: %w", err)
+	}
+	return d, nil
+}
+
+// SetPackagingOrTrEqAmountDecimal sets the packagingOrTrEqAmount field to the canonical string of a decimal.
+func (x *TCE) SetPackagingOrTrEqAmountDecimal(v ileapdecimal.Decimal) {
+	x.SetPackagingOrTrEqAmount(v.String())
+}
+
+// GetTransportActivityDecimal returns the transportActivity field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetTransportActivityDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetTransportActivity())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("transportActivity: %w", err)
+	}
+	return d, nil
+}
+
+// SetTransportActivityDecimal sets the transportActivity field to the canonical string of a decimal.
+func (x *TCE) SetTransportActivityDecimal(v ileapdecimal.Decimal) {
+	x.SetTransportActivity(v.String())
+}
+
+// GetCo2EWtwDecimal returns the co2eWTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetCo2EWtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2EWtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eWTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2EWtwDecimal sets the co2eWTW field to the canonical string of a decimal.
+func (x *TCE) SetCo2EWtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2EWtw(v.String())
+}
+
+// GetCo2ETtwDecimal returns the co2eTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetCo2ETtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2ETtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2ETtwDecimal sets the co2eTTW field to the canonical string of a decimal.
+func (x *TCE) SetCo2ETtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2ETtw(v.String())
+}
+
+// GetNoxTtwDecimal returns the noxTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetNoxTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetNoxTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("noxTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetNoxTtwDecimal sets the noxTTW field to the canonical string of a decimal.
+func (x *TCE) SetNoxTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetNoxTtw(v.String())
+}
+
+// GetSoxTtwDecimal returns the soxTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetSoxTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetSoxTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("soxTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetSoxTtwDecimal sets the soxTTW field to the canonical string of a decimal.
+func (x *TCE) SetSoxTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetSoxTtw(v.String())
+}
+
+// GetCh4TtwDecimal returns the ch4TTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetCh4TtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCh4Ttw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("ch4TTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCh4TtwDecimal sets the ch4TTW field to the canonical string of a decimal.
+func (x *TCE) SetCh4TtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCh4Ttw(v.String())
+}
+
+// GetPmTtwDecimal returns the pmTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TCE) GetPmTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetPmTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("pmTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetPmTtwDecimal sets the pmTTW field to the canonical string of a decimal.
+func (x *TCE) SetPmTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetPmTtw(v.String())
+}
+
+// GetLoadFactorDecimal returns the loadFactor field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TOC) GetLoadFactorDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetLoadFactor())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("loadFactor: %w", err)
+	}
+	return d, nil
+}
+
+// SetLoadFactorDecimal sets the loadFactor field to the canonical string of a decimal.
+func (x *TOC) SetLoadFactorDecimal(v ileapdecimal.Decimal) {
+	x.SetLoadFactor(v.String())
+}
+
+// GetEmptyDistanceFactorDecimal returns the emptyDistanceFactor field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TOC) GetEmptyDistanceFactorDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetEmptyDistanceFactor())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("emptyDistanceFactor: %w", err)
+	}
+	return d, nil
+}
+
+// SetEmptyDistanceFactorDecimal sets the emptyDistanceFactor field to the canonical string of a decimal.
+func (x *TOC) SetEmptyDistanceFactorDecimal(v ileapdecimal.Decimal) {
+	x.SetEmptyDistanceFactor(v.String())
+}
+
+// GetCo2EIntensityWtwDecimal returns the co2eIntensityWTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TOC) GetCo2EIntensityWtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2EIntensityWtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eIntensityWTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2EIntensityWtwDecimal sets the co2eIntensityWTW field to the canonical string of a decimal.
+func (x *TOC) SetCo2EIntensityWtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2EIntensityWtw(v.String())
+}
+
+// GetCo2EIntensityTtwDecimal returns the co2eIntensityTTW field as a decimal.
+// It returns an error if the field is unset or not a valid decimal.
+func (x *TOC) GetCo2EIntensityTtwDecimal() (ileapdecimal.Decimal, error) {
+	d, err := ileapdecimal.Parse(x.GetCo2EIntensityTtw())
+	if err != nil {
+		return ileapdecimal.Decimal{}, fmt.Errorf("co2eIntensityTTW: %w", err)
+	}
+	return d, nil
+}
+
+// SetCo2EIntensityTtwDecimal sets the co2eIntensityTTW field to the canonical string of a decimal.
+func (x *TOC) SetCo2EIntensityTtwDecimal(v ileapdecimal.Decimal) {
+	x.SetCo2EIntensityTtw(v.String())
+}
@@ -238,6 +238,636 @@ func (b0 Filter_builder) Build() *Filter {
 	return m0
 }
 
+// FilterExpression is a boolean filter expression, such as a parsed OData v4
+// $filter query parameter.
+//
+// Field paths use dot notation and are relative to the filtered message, or
+// to the collection element within an Any expression. An empty field path
+// within an Any expression refers to the element itself.
+type FilterExpression struct {
+	state                 protoimpl.MessageState        `protogen:"opaque.v1"`
+	xxx_hidden_Expression isFilterExpression_Expression `protobuf_oneof:"expression"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *FilterExpression) Reset() {
+	*x = FilterExpression{}
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterExpression) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterExpression) ProtoMessage() {}
+
+func (x *FilterExpression) ProtoReflect() protoreflect.Message {
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FilterExpression) GetComparison() *Filter {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_Comparison); ok {
+			return x.Comparison
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetAnd() *FilterExpression_Operands {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_And); ok {
+			return x.And
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetOr() *FilterExpression_Operands {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_Or); ok {
+			return x.Or
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetNot() *FilterExpression {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_Not); ok {
+			return x.Not
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetIn() *FilterExpression_In {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_In_); ok {
+			return x.In
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) GetAny() *FilterExpression_Any {
+	if x != nil {
+		if x, ok := x.xxx_hidden_Expression.(*filterExpression_Any_); ok {
+			return x.Any
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression) SetComparison(v *Filter) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_Comparison{v}
+}
+
+func (x *FilterExpression) SetAnd(v *FilterExpression_Operands) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_And{v}
+}
+
+func (x *FilterExpression) SetOr(v *FilterExpression_Operands) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_Or{v}
+}
+
+func (x *FilterExpression) SetNot(v *FilterExpression) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_Not{v}
+}
+
+func (x *FilterExpression) SetIn(v *FilterExpression_In) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_In_{v}
+}
+
+func (x *FilterExpression) SetAny(v *FilterExpression_Any) {
+	if v == nil {
+		x.xxx_hidden_Expression = nil
+		return
+	}
+	x.xxx_hidden_Expression = &filterExpression_Any_{v}
+}
+
+func (x *FilterExpression) HasExpression() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Expression != nil
+}
+
+func (x *FilterExpression) HasComparison() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_Comparison)
+	return ok
+}
+
+func (x *FilterExpression) HasAnd() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_And)
+	return ok
+}
+
+func (x *FilterExpression) HasOr() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_Or)
+	return ok
+}
+
+func (x *FilterExpression) HasNot() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_Not)
+	return ok
+}
+
+func (x *FilterExpression) HasIn() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_In_)
+	return ok
+}
+
+func (x *FilterExpression) HasAny() bool {
+	if x == nil {
+		return false
+	}
+	_, ok := x.xxx_hidden_Expression.(*filterExpression_Any_)
+	return ok
+}
+
+func (x *FilterExpression) ClearExpression() {
+	x.xxx_hidden_Expression = nil
+}
+
+func (x *FilterExpression) ClearComparison() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_Comparison); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+func (x *FilterExpression) ClearAnd() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_And); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+func (x *FilterExpression) ClearOr() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_Or); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+func (x *FilterExpression) ClearNot() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_Not); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+func (x *FilterExpression) ClearIn() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_In_); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+func (x *FilterExpression) ClearAny() {
+	if _, ok := x.xxx_hidden_Expression.(*filterExpression_Any_); ok {
+		x.xxx_hidden_Expression = nil
+	}
+}
+
+const FilterExpression_Expression_not_set_case case_FilterExpression_Expression = 0
+const FilterExpression_Comparison_case case_FilterExpression_Expression = 1
+const FilterExpression_And_case case_FilterExpression_Expression = 2
+const FilterExpression_Or_case case_FilterExpression_Expression = 3
+const FilterExpression_Not_case case_FilterExpression_Expression = 4
+const FilterExpression_In_case case_FilterExpression_Expression = 5
+const FilterExpression_Any_case case_FilterExpression_Expression = 6
+
+func (x *FilterExpression) WhichExpression() case_FilterExpression_Expression {
+	if x == nil {
+		return FilterExpression_Expression_not_set_case
+	}
+	switch x.xxx_hidden_Expression.(type) {
+	case *filterExpression_Comparison:
+		return FilterExpression_Comparison_case
+	case *filterExpression_And:
+		return FilterExpression_And_case
+	case *filterExpression_Or:
+		return FilterExpression_Or_case
+	case *filterExpression_Not:
+		return FilterExpression_Not_case
+	case *filterExpression_In_:
+		return FilterExpression_In_case
+	case *filterExpression_Any_:
+		return FilterExpression_Any_case
+	default:
+		return FilterExpression_Expression_not_set_case
+	}
+}
+
+type FilterExpression_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// The kind of expression.
+
+	// Fields of oneof xxx_hidden_Expression:
+	// A comparison of a field path with a value.
+	Comparison *Filter
+	// A conjunction, true if all operands are true.
+	And *FilterExpression_Operands
+	// A disjunction, true if any operand is true.
+	Or *FilterExpression_Operands
+	// A negation, true if the operand is false.
+	Not *FilterExpression
+	// A membership test, true if the field path equals any of the values.
+	In *FilterExpression_In
+	// A lambda, true if the predicate holds for any element of a collection.
+	Any *FilterExpression_Any
+	// -- end of xxx_hidden_Expression
+}
+
+func (b0 FilterExpression_builder) Build() *FilterExpression {
+	m0 := &FilterExpression{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Comparison != nil {
+		x.xxx_hidden_Expression = &filterExpression_Comparison{b.Comparison}
+	}
+	if b.And != nil {
+		x.xxx_hidden_Expression = &filterExpression_And{b.And}
+	}
+	if b.Or != nil {
+		x.xxx_hidden_Expression = &filterExpression_Or{b.Or}
+	}
+	if b.Not != nil {
+		x.xxx_hidden_Expression = &filterExpression_Not{b.Not}
+	}
+	if b.In != nil {
+		x.xxx_hidden_Expression = &filterExpression_In_{b.In}
+	}
+	if b.Any != nil {
+		x.xxx_hidden_Expression = &filterExpression_Any_{b.Any}
+	}
+	return m0
+}
+
+type case_FilterExpression_Expression protoreflect.FieldNumber
+
+func (x case_FilterExpression_Expression) String() string {
+	md := file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[1].Descriptor()
+	if x == 0 {
+		return "not set"
+	}
+	return protoimpl.X.MessageFieldStringOf(md, protoreflect.FieldNumber(x))
+}
+
+type isFilterExpression_Expression interface {
+	isFilterExpression_Expression()
+}
+
+type filterExpression_Comparison struct {
+	// A comparison of a field path with a value.
+	Comparison *Filter `protobuf:"bytes,1,opt,name=comparison,oneof"`
+}
+
+type filterExpression_And struct {
+	// A conjunction, true if all operands are true.
+	And *FilterExpression_Operands `protobuf:"bytes,2,opt,name=and,oneof"`
+}
+
+type filterExpression_Or struct {
+	// A disjunction, true if any operand is true.
+	Or *FilterExpression_Operands `protobuf:"bytes,3,opt,name=or,oneof"`
+}
+
+type filterExpression_Not struct {
+	// A negation, true if the operand is false.
+	Not *FilterExpression `protobuf:"bytes,4,opt,name=not,oneof"`
+}
+
+type filterExpression_In_ struct {
+	// A membership test, true if the field path equals any of the values.
+	In *FilterExpression_In `protobuf:"bytes,5,opt,name=in,oneof"`
+}
+
+type filterExpression_Any_ struct {
+	// A lambda, true if the predicate holds for any element of a collection.
+	Any *FilterExpression_Any `protobuf:"bytes,6,opt,name=any,oneof"`
+}
+
+func (*filterExpression_Comparison) isFilterExpression_Expression() {}
+
+func (*filterExpression_And) isFilterExpression_Expression() {}
+
+func (*filterExpression_Or) isFilterExpression_Expression() {}
+
+func (*filterExpression_Not) isFilterExpression_Expression() {}
+
+func (*filterExpression_In_) isFilterExpression_Expression() {}
+
+func (*filterExpression_Any_) isFilterExpression_Expression() {}
+
+// Operands of a conjunction or disjunction.
+type FilterExpression_Operands struct {
+	state               protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Operands *[]*FilterExpression   `protobuf:"bytes,1,rep,name=operands"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *FilterExpression_Operands) Reset() {
+	*x = FilterExpression_Operands{}
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterExpression_Operands) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterExpression_Operands) ProtoMessage() {}
+
+func (x *FilterExpression_Operands) ProtoReflect() protoreflect.Message {
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FilterExpression_Operands) GetOperands() []*FilterExpression {
+	if x != nil {
+		if x.xxx_hidden_Operands != nil {
+			return *x.xxx_hidden_Operands
+		}
+	}
+	return nil
+}
+
+func (x *FilterExpression_Operands) SetOperands(v []*FilterExpression) {
+	x.xxx_hidden_Operands = &v
+}
+
+type FilterExpression_Operands_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// The operands, in the order they appeared in the expression.
+	Operands []*FilterExpression
+}
+
+func (b0 FilterExpression_Operands_builder) Build() *FilterExpression_Operands {
+	m0 := &FilterExpression_Operands{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Operands = &b.Operands
+	return m0
+}
+
+// In tests whether a field path equals any of a list of values.
+type FilterExpression_In struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_FieldPath   *string                `protobuf:"bytes,1,opt,name=field_path,json=fieldPath"`
+	xxx_hidden_Values      []string               `protobuf:"bytes,2,rep,name=values"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *FilterExpression_In) Reset() {
+	*x = FilterExpression_In{}
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterExpression_In) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterExpression_In) ProtoMessage() {}
+
+func (x *FilterExpression_In) ProtoReflect() protoreflect.Message {
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FilterExpression_In) GetFieldPath() string {
+	if x != nil {
+		if x.xxx_hidden_FieldPath != nil {
+			return *x.xxx_hidden_FieldPath
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *FilterExpression_In) GetValues() []string {
+	if x != nil {
+		return x.xxx_hidden_Values
+	}
+	return nil
+}
+
+func (x *FilterExpression_In) SetFieldPath(v string) {
+	x.xxx_hidden_FieldPath = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *FilterExpression_In) SetValues(v []string) {
+	x.xxx_hidden_Values = v
+}
+
+func (x *FilterExpression_In) HasFieldPath() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *FilterExpression_In) ClearFieldPath() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_FieldPath = nil
+}
+
+type FilterExpression_In_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Case-sensitive field path, optionally using dot notation for nesting.
+	FieldPath *string
+	// Case-insensitive values.
+	Values []string
+}
+
+func (b0 FilterExpression_In_builder) Build() *FilterExpression_In {
+	m0 := &FilterExpression_In{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.FieldPath != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_FieldPath = b.FieldPath
+	}
+	x.xxx_hidden_Values = b.Values
+	return m0
+}
+
+// Any tests whether a predicate holds for any element of a collection.
+type FilterExpression_Any struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_FieldPath   *string                `protobuf:"bytes,1,opt,name=field_path,json=fieldPath"`
+	xxx_hidden_Predicate   *FilterExpression      `protobuf:"bytes,2,opt,name=predicate"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *FilterExpression_Any) Reset() {
+	*x = FilterExpression_Any{}
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilterExpression_Any) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterExpression_Any) ProtoMessage() {}
+
+func (x *FilterExpression_Any) ProtoReflect() protoreflect.Message {
+	mi := &file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *FilterExpression_Any) GetFieldPath() string {
+	if x != nil {
+		if x.xxx_hidden_FieldPath != nil {
+			return *x.xxx_hidden_FieldPath
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *FilterExpression_Any) GetPredicate() *FilterExpression {
+	if x != nil {
+		return x.xxx_hidden_Predicate
+	}
+	return nil
+}
+
+func (x *FilterExpression_Any) SetFieldPath(v string) {
+	x.xxx_hidden_FieldPath = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *FilterExpression_Any) SetPredicate(v *FilterExpression) {
+	x.xxx_hidden_Predicate = v
+}
+
+func (x *FilterExpression_Any) HasFieldPath() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *FilterExpression_Any) HasPredicate() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Predicate != nil
+}
+
+func (x *FilterExpression_Any) ClearFieldPath() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_FieldPath = nil
+}
+
+func (x *FilterExpression_Any) ClearPredicate() {
+	x.xxx_hidden_Predicate = nil
+}
+
+type FilterExpression_Any_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	// Case-sensitive field path of the collection.
+	FieldPath *string
+	// Predicate evaluated for each element. When unset, Any is true if the
+	// collection is not empty.
+	Predicate *FilterExpression
+}
+
+func (b0 FilterExpression_Any_builder) Build() *FilterExpression_Any {
+	m0 := &FilterExpression_Any{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.FieldPath != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_FieldPath = b.FieldPath
+	}
+	x.xxx_hidden_Predicate = b.Predicate
+	return m0
+}
+
 var File_wayplatform_connect_ileap_v1_filter_proto protoreflect.FileDescriptor
 
 const file_wayplatform_connect_ileap_v1_filter_proto_rawDesc = "" +
@@ -255,22 +885,55 @@ const file_wayplatform_connect_ileap_v1_filter_proto_rawDesc = "" +
 	"\x02LT\x10\x03\x12\x06\n" +
 	"\x02LE\x10\x04\x12\x06\n" +
 	"\x02GT\x10\x05\x12\x06\n" +
-	"\x02GE\x10\x06B\x93\x02\n" +
+	"\x02GE\x10\x06\"\xda\x05\n" +
+	"\x10FilterExpression\x12F\n" +
+	"\n" +
+	"comparison\x18\x01 \x01(\v2$.wayplatform.connect.ileap.v1.FilterH\x00R\n" +
+	"comparison\x12K\n" +
+	"\x03and\x18\x02 \x01(\v27.wayplatform.connect.ileap.v1.FilterExpression.OperandsH\x00R\x03and\x12I\n" +
+	"\x02or\x18\x03 \x01(\v27.wayplatform.connect.ileap.v1.FilterExpression.OperandsH\x00R\x02or\x12B\n" +
+	"\x03not\x18\x04 \x01(\v2..wayplatform.connect.ileap.v1.FilterExpressionH\x00R\x03not\x12C\n" +
+	"\x02in\x18\x05 \x01(\v21.wayplatform.connect.ileap.v1.FilterExpression.InH\x00R\x02in\x12F\n" +
+	"\x03any\x18\x06 \x01(\v22.wayplatform.connect.ileap.v1.FilterExpression.AnyH\x00R\x03any\x1aV\n" +
+	"\bOperands\x12J\n" +
+	"\boperands\x18\x01 \x03(\v2..wayplatform.connect.ileap.v1.FilterExpressionR\boperands\x1a;\n" +
+	"\x02In\x12\x1d\n" +
+	"\n" +
+	"field_path\x18\x01 \x01(\tR\tfieldPath\x12\x16\n" +
+	"\x06values\x18\x02 \x03(\tR\x06values\x1ar\n" +
+	"\x03Any\x12\x1d\n" +
+	"\n" +
+	"field_path\x18\x01 \x01(\tR\tfieldPath\x12L\n" +
+	"\tpredicate\x18\x02 \x01(\v2..wayplatform.connect.ileap.v1.FilterExpressionR\tpredicateB\f\n" +
+	"\n" +
+	"expressionB\x93\x02\n" +
 	" com.wayplatform.connect.ileap.v1B\vFilterProtoP\x01ZOgithub.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1;ileapv1\xa2\x02\x03WCI\xaa\x02\x1cWayplatform.Connect.Ileap.V1\xca\x02\x1cWayplatform\\Connect\\Ileap\\V1\xe2\x02(Wayplatform\\Connect\\Ileap\\V1\\GPBMetadata\xea\x02\x1fWayplatform::Connect::Ileap::V1b\beditionsp\xe8\a"
 
 var file_wayplatform_connect_ileap_v1_filter_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_wayplatform_connect_ileap_v1_filter_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_wayplatform_connect_ileap_v1_filter_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_wayplatform_connect_ileap_v1_filter_proto_goTypes = []any{
-	(Filter_Operator)(0), // 0: wayplatform.connect.ileap.v1.Filter.Operator
-	(*Filter)(nil),       // 1: wayplatform.connect.ileap.v1.Filter
+	(Filter_Operator)(0),              // 0: wayplatform.connect.ileap.v1.Filter.Operator
+	(*Filter)(nil),                    // 1: wayplatform.connect.ileap.v1.Filter
+	(*FilterExpression)(nil),          // 2: wayplatform.connect.ileap.v1.FilterExpression
+	(*FilterExpression_Operands)(nil), // 3: wayplatform.connect.ileap.v1.FilterExpression.Operands
+	(*FilterExpression_In)(nil),       // 4: wayplatform.connect.ileap.v1.FilterExpression.In
+	(*FilterExpression_Any)(nil),      // 5: wayplatform.connect.ileap.v1.FilterExpression.Any
 }
 var file_wayplatform_connect_ileap_v1_filter_proto_depIdxs = []int32{
 	0, // 0: wayplatform.connect.ileap.v1.Filter.operator:type_name -> wayplatform.connect.ileap.v1.Filter.Operator
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1, // 1: wayplatform.connect.ileap.v1.FilterExpression.comparison:type_name -> wayplatform.connect.ileap.v1.Filter
+	3, // 2: wayplatform.connect.ileap.v1.FilterExpression.and:type_name -> wayplatform.connect.ileap.v1.FilterExpression.Operands
+	3, // 3: wayplatform.connect.ileap.v1.FilterExpression.or:type_name -> wayplatform.connect.ileap.v1.FilterExpression.Operands
+	2, // 4: wayplatform.connect.ileap.v1.FilterExpression.not:type_name -> wayplatform.connect.ileap.v1.FilterExpression
+	4, // 5: wayplatform.connect.ileap.v1.FilterExpression.in:type_name -> wayplatform.connect.ileap.v1.FilterExpression.In
+	5, // 6: wayplatform.connect.ileap.v1.FilterExpression.any:type_name -> wayplatform.connect.ileap.v1.FilterExpression.Any
+	2, // 7: wayplatform.connect.ileap.v1.FilterExpression.Operands.operands:type_name -> wayplatform.connect.ileap.v1.FilterExpression
+	2, // 8: wayplatform.connect.ileap.v1.FilterExpression.Any.predicate:type_name -> wayplatform.connect.ileap.v1.FilterExpression
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_wayplatform_connect_ileap_v1_filter_proto_init() }
@@ -278,13 +941,21 @@ func file_wayplatform_connect_ileap_v1_filter_proto_init() {
 	if File_wayplatform_connect_ileap_v1_filter_proto != nil {
 		return
 	}
+	file_wayplatform_connect_ileap_v1_filter_proto_msgTypes[1].OneofWrappers = []any{
+		(*filterExpression_Comparison)(nil),
+		(*filterExpression_And)(nil),
+		(*filterExpression_Or)(nil),
+		(*filterExpression_Not)(nil),
+		(*filterExpression_In_)(nil),
+		(*filterExpression_Any_)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_wayplatform_connect_ileap_v1_filter_proto_rawDesc), len(file_wayplatform_connect_ileap_v1_filter_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   1,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -31,6 +31,7 @@ const (
 type ListFootprintsRequest struct {
 	state                  protoimpl.MessageState `protogen:"opaque.v1"`
 	xxx_hidden_Filters     *[]*Filter             `protobuf:"bytes,4,rep,name=filters"`
+	xxx_hidden_Filter      *FilterExpression      `protobuf:"bytes,5,opt,name=filter"`
 	xxx_hidden_Limit       int32                  `protobuf:"varint,2,opt,name=limit"`
 	xxx_hidden_Offset      int32                  `protobuf:"varint,3,opt,name=offset"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
@@ -73,6 +74,13 @@ func (x *ListFootprintsRequest) GetFilters() []*Filter {
 	return nil
 }
 
+func (x *ListFootprintsRequest) GetFilter() *FilterExpression {
+	if x != nil {
+		return x.xxx_hidden_Filter
+	}
+	return nil
+}
+
 func (x *ListFootprintsRequest) GetLimit() int32 {
 	if x != nil {
 		return x.xxx_hidden_Limit
@@ -91,37 +99,52 @@ func (x *ListFootprintsRequest) SetFilters(v []*Filter) {
 	x.xxx_hidden_Filters = &v
 }
 
+func (x *ListFootprintsRequest) SetFilter(v *FilterExpression) {
+	x.xxx_hidden_Filter = v
+}
+
 func (x *ListFootprintsRequest) SetLimit(v int32) {
 	x.xxx_hidden_Limit = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
 }
 
 func (x *ListFootprintsRequest) SetOffset(v int32) {
 	x.xxx_hidden_Offset = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *ListFootprintsRequest) HasFilter() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Filter != nil
 }
 
 func (x *ListFootprintsRequest) HasLimit() bool {
 	if x == nil {
 		return false
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
 func (x *ListFootprintsRequest) HasOffset() bool {
 	if x == nil {
 		return false
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *ListFootprintsRequest) ClearFilter() {
+	x.xxx_hidden_Filter = nil
 }
 
 func (x *ListFootprintsRequest) ClearLimit() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
 	x.xxx_hidden_Limit = 0
 }
 
 func (x *ListFootprintsRequest) ClearOffset() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
 	x.xxx_hidden_Offset = 0
 }
 
@@ -135,14 +158,22 @@ type ListFootprintsRequest_builder struct {
 	// to reference nested properties.
 	//
 	// Filtering semantics are best-effort:
-	//   - A host system can ignore the filter statement or parts of it.
-	//   - A host system can return an error response (for example, NotImplemented)
-	//     if it does not support a specific filter pair.
-	//   - A host system can treat concatenated filters disjunctively, returning the
-	//     union of results from individual filters.
+	// - A host system can ignore the filter statement or parts of it.
+	// - A host system can return an error response (for example, NotImplemented)
+	//   if it does not support a specific filter pair.
+	// - A host system can treat concatenated filters disjunctively, returning the
+	//   union of results from individual filters.
 	//
 	// See iLEAP "Common HTTP Endpoint Behaviors / Filtering".
 	Filters []*Filter
+	// The full filter expression, as parsed from the OData $filter query
+	// parameter.
+	//
+	// Filters only carries the comparisons joined by a top-level "and". The
+	// expression additionally preserves "or", "not", "in", groups and lambdas.
+	// Host systems that evaluate the expression SHOULD prefer it over filters.
+	// Unset if no filter was provided.
+	Filter *FilterExpression
 	// Maximum number of ProductFootprints to return. The host system MAY
 	// return fewer ProductFootprints than requested. If there are additional
 	// ProductFootprints, the response total will exceed offset + len(data).
@@ -166,12 +197,13 @@ func (b0 ListFootprintsRequest_builder) Build() *ListFootprintsRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	x.xxx_hidden_Filters = &b.Filters
+	x.xxx_hidden_Filter = b.Filter
 	if b.Limit != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
 		x.xxx_hidden_Limit = *b.Limit
 	}
 	if b.Offset != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
 		x.xxx_hidden_Offset = *b.Offset
 	}
 	return m0
@@ -582,11 +614,11 @@ type ListTransportActivityDataRequest_builder struct {
 	// to reference nested properties.
 	//
 	// Filtering semantics are best-effort:
-	//   - A host system can ignore the filter statement or parts of it.
-	//   - A host system can return an error response (for example, NotImplemented)
-	//     if it does not support a specific filter pair.
-	//   - A host system can treat concatenated filters disjunctively, returning the
-	//     union of results from individual filters.
+	// - A host system can ignore the filter statement or parts of it.
+	// - A host system can return an error response (for example, NotImplemented)
+	//   if it does not support a specific filter pair.
+	// - A host system can treat concatenated filters disjunctively, returning the
+	//   union of results from individual filters.
 	//
 	// Example: energyCarriers.feedstocks.feedstock=fossil
 	Filters []*Filter
@@ -738,9 +770,10 @@ var File_wayplatform_connect_ileap_v1_ileap_service_proto protoreflect.FileDescr
 
 const file_wayplatform_connect_ileap_v1_ileap_service_proto_rawDesc = "" +
 	"\n" +
-	"0wayplatform/connect/ileap/v1/ileap_service.proto\x12\x1cwayplatform.connect.ileap.v1\x1a\x1bbuf/validate/validate.proto\x1a)wayplatform/connect/ileap/v1/filter.proto\x1a4wayplatform/connect/ileap/v1/product_footprint.proto\x1a&wayplatform/connect/ileap/v1/tad.proto\"\x97\x01\n" +
+	"0wayplatform/connect/ileap/v1/ileap_service.proto\x12\x1cwayplatform.connect.ileap.v1\x1a\x1bbuf/validate/validate.proto\x1a)wayplatform/connect/ileap/v1/filter.proto\x1a4wayplatform/connect/ileap/v1/product_footprint.proto\x1a&wayplatform/connect/ileap/v1/tad.proto\"\xdf\x01\n" +
 	"\x15ListFootprintsRequest\x12>\n" +
-	"\afilters\x18\x04 \x03(\v2$.wayplatform.connect.ileap.v1.FilterR\afilters\x12\x1d\n" +
+	"\afilters\x18\x04 \x03(\v2$.wayplatform.connect.ileap.v1.FilterR\afilters\x12F\n" +
+	"\x06filter\x18\x05 \x01(\v2..wayplatform.connect.ileap.v1.FilterExpressionR\x06filter\x12\x1d\n" +
 	"\x05limit\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x05limit\x12\x1f\n" +
 	"\x06offset\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x06offset\"r\n" +
 	"\x16ListFootprintsResponse\x12B\n" +
@@ -772,26 +805,28 @@ var file_wayplatform_connect_ileap_v1_ileap_service_proto_goTypes = []any{
 	(*ListTransportActivityDataRequest)(nil),  // 4: wayplatform.connect.ileap.v1.ListTransportActivityDataRequest
 	(*ListTransportActivityDataResponse)(nil), // 5: wayplatform.connect.ileap.v1.ListTransportActivityDataResponse
 	(*Filter)(nil),                            // 6: wayplatform.connect.ileap.v1.Filter
-	(*ProductFootprint)(nil),                  // 7: wayplatform.connect.ileap.v1.ProductFootprint
-	(*TAD)(nil),                               // 8: wayplatform.connect.ileap.v1.TAD
+	(*FilterExpression)(nil),                  // 7: wayplatform.connect.ileap.v1.FilterExpression
+	(*ProductFootprint)(nil),                  // 8: wayplatform.connect.ileap.v1.ProductFootprint
+	(*TAD)(nil),                               // 9: wayplatform.connect.ileap.v1.TAD
 }
 var file_wayplatform_connect_ileap_v1_ileap_service_proto_depIdxs = []int32{
 	6, // 0: wayplatform.connect.ileap.v1.ListFootprintsRequest.filters:type_name -> wayplatform.connect.ileap.v1.Filter
-	7, // 1: wayplatform.connect.ileap.v1.ListFootprintsResponse.data:type_name -> wayplatform.connect.ileap.v1.ProductFootprint
-	7, // 2: wayplatform.connect.ileap.v1.GetFootprintResponse.data:type_name -> wayplatform.connect.ileap.v1.ProductFootprint
-	6, // 3: wayplatform.connect.ileap.v1.ListTransportActivityDataRequest.filters:type_name -> wayplatform.connect.ileap.v1.Filter
-	8, // 4: wayplatform.connect.ileap.v1.ListTransportActivityDataResponse.data:type_name -> wayplatform.connect.ileap.v1.TAD
-	0, // 5: wayplatform.connect.ileap.v1.ILeapService.ListFootprints:input_type -> wayplatform.connect.ileap.v1.ListFootprintsRequest
-	2, // 6: wayplatform.connect.ileap.v1.ILeapService.GetFootprint:input_type -> wayplatform.connect.ileap.v1.GetFootprintRequest
-	4, // 7: wayplatform.connect.ileap.v1.ILeapService.ListTransportActivityData:input_type -> wayplatform.connect.ileap.v1.ListTransportActivityDataRequest
-	1, // 8: wayplatform.connect.ileap.v1.ILeapService.ListFootprints:output_type -> wayplatform.connect.ileap.v1.ListFootprintsResponse
-	3, // 9: wayplatform.connect.ileap.v1.ILeapService.GetFootprint:output_type -> wayplatform.connect.ileap.v1.GetFootprintResponse
-	5, // 10: wayplatform.connect.ileap.v1.ILeapService.ListTransportActivityData:output_type -> wayplatform.connect.ileap.v1.ListTransportActivityDataResponse
-	8, // [8:11] is the sub-list for method output_type
-	5, // [5:8] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	7, // 1: wayplatform.connect.ileap.v1.ListFootprintsRequest.filter:type_name -> wayplatform.connect.ileap.v1.FilterExpression
+	8, // 2: wayplatform.connect.ileap.v1.ListFootprintsResponse.data:type_name -> wayplatform.connect.ileap.v1.ProductFootprint
+	8, // 3: wayplatform.connect.ileap.v1.GetFootprintResponse.data:type_name -> wayplatform.connect.ileap.v1.ProductFootprint
+	6, // 4: wayplatform.connect.ileap.v1.ListTransportActivityDataRequest.filters:type_name -> wayplatform.connect.ileap.v1.Filter
+	9, // 5: wayplatform.connect.ileap.v1.ListTransportActivityDataResponse.data:type_name -> wayplatform.connect.ileap.v1.TAD
+	0, // 6: wayplatform.connect.ileap.v1.ILeapService.ListFootprints:input_type -> wayplatform.connect.ileap.v1.ListFootprintsRequest
+	2, // 7: wayplatform.connect.ileap.v1.ILeapService.GetFootprint:input_type -> wayplatform.connect.ileap.v1.GetFootprintRequest
+	4, // 8: wayplatform.connect.ileap.v1.ILeapService.ListTransportActivityData:input_type -> wayplatform.connect.ileap.v1.ListTransportActivityDataRequest
+	1, // 9: wayplatform.connect.ileap.v1.ILeapService.ListFootprints:output_type -> wayplatform.connect.ileap.v1.ListFootprintsResponse
+	3, // 10: wayplatform.connect.ileap.v1.ILeapService.GetFootprint:output_type -> wayplatform.connect.ileap.v1.GetFootprintResponse
+	5, // 11: wayplatform.connect.ileap.v1.ILeapService.ListTransportActivityData:output_type -> wayplatform.connect.ileap.v1.ListTransportActivityDataResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_wayplatform_connect_ileap_v1_ileap_service_proto_init() }
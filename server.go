@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -31,8 +32,19 @@ import (
 type Server struct {
 	service    ileapv1connect.ILeapServiceHandler
 	auth       AuthHandler
+	events     EventHandler
 	pathPrefix string
 	serveMux   *http.ServeMux
+
+	accessPolicy     AccessPolicy
+	revocations      RevocationList
+	clientCertHeader string
+	rateLimit        *RateLimitConfig
+	telemetry        *serverTelemetry
+	audit            AuditSink
+
+	validateResponses bool
+	strictFiltering   bool
 }
 
 const ileapGoVersionHeader = "Way-ILeap-Go-Version"
@@ -45,6 +57,10 @@ var uuidRegexp = regexp.MustCompile(
 type ServerOption func(*Server)
 
 // WithServiceHandler sets the ILeapService handler for footprints and TAD.
+//
+// List requests with a limit of 0 ask for all matching records from the
+// offset on. Handlers must not apply a default page size to them: the server
+// sends them for requests without a limit, and with [WithAccessPolicy].
 func WithServiceHandler(h ileapv1connect.ILeapServiceHandler) ServerOption {
 	return func(s *Server) { s.service = h }
 }
@@ -54,6 +70,69 @@ func WithAuthHandler(a AuthHandler) ServerOption {
 	return func(s *Server) { s.auth = a }
 }
 
+// WithEventHandler sets the handler for PACT events received on POST /2/events.
+// Without an event handler, events of a known type are accepted and discarded
+// without decoding their payload.
+func WithEventHandler(h EventHandler) ServerOption {
+	return func(s *Server) { s.events = h }
+}
+
+// WithAccessPolicy restricts the footprints and TADs returned to each caller.
+// Records the caller may not access are left out of list results, and are
+// reported as not found by GET /2/footprints/{id}.
+//
+// To report correct totals and pagination links, the server requests all
+// matching records from the service handler, with a limit and offset of 0,
+// and paginates the accessible records itself. Pass the policy to the
+// resolver of package ileapasync too, to apply it to asynchronous footprint
+// requests.
+func WithAccessPolicy(p AccessPolicy) ServerOption {
+	return func(s *Server) { s.accessPolicy = p }
+}
+
+// WithRevocationList sets the list of revoked tokens consulted by the auth
+// middlewares and token introspection, and updated on token revocation.
+// Defaults to a [MemoryRevocationList] when the auth handler implements
+// [TokenRevoker]. Use a shared list when running several server instances.
+func WithRevocationList(l RevocationList) ServerOption {
+	return func(s *Server) { s.revocations = l }
+}
+
+// WithClientCertificateHeader reads TLS client certificates from a request
+// header set by a TLS-terminating reverse proxy, instead of from the TLS
+// connection. The header holds the URL-encoded PEM certificate, such as
+// nginx's $ssl_client_escaped_cert. Only use it behind a proxy that removes
+// the header from client requests, since anyone could otherwise present any
+// certificate.
+func WithClientCertificateHeader(name string) ServerOption {
+	return func(s *Server) { s.clientCertHeader = name }
+}
+
+// WithRateLimit limits the rate of requests per client IP address and per
+// client, and locks out clients after repeated failed authentication attempts.
+// Exceeded limits are reported with HTTP 429 and a Retry-After header, as a
+// temporarily_unavailable OAuth error on the auth endpoints, and with the
+// TooManyRequests error code on the data endpoints.
+func WithRateLimit(cfg RateLimitConfig) ServerOption {
+	return func(s *Server) { s.rateLimit = &cfg }
+}
+
+// WithResponseValidation enables validation of footprints and TADs returned by
+// the service handler against the iLEAP data model rules, see [Validate].
+// Responses with violations are replaced by an InternalError response.
+func WithResponseValidation() ServerOption {
+	return func(s *Server) { s.validateResponses = true }
+}
+
+// WithStrictFiltering rejects $filter expressions on GET /2/footprints that the
+// server cannot parse: invalid expressions with a BadRequest response, and
+// expressions using unsupported OData features with a NotImplemented response.
+// By default, the server filters on a best-effort basis and ignores the clauses
+// it does not understand.
+func WithStrictFiltering() ServerOption {
+	return func(s *Server) { s.strictFiltering = true }
+}
+
 // WithPathPrefix sets the path prefix for the service (e.g. "/ileap").
 // Leading slashes are added if missing, and trailing slashes are trimmed.
 func WithPathPrefix(p string) ServerOption {
@@ -89,32 +168,53 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) registerRoutes() {
-	s.serveMux.Handle(
-		"GET "+s.pathPrefix+"/2/footprints",
-		s.pactAuthMiddleware(http.HandlerFunc(s.listFootprints)),
-	)
-	s.serveMux.Handle(
-		"GET "+s.pathPrefix+"/2/footprints/{id}",
-		s.pactAuthMiddleware(http.HandlerFunc(s.getFootprint)),
+	s.handle(
+		"GET /2/footprints",
+		"ListFootprints",
+		s.dataRoute(s.pactAuthMiddleware, s.listFootprints),
 	)
-	s.serveMux.Handle(
-		"GET "+s.pathPrefix+"/2/ileap/tad",
-		s.ileapAuthMiddleware(http.HandlerFunc(s.listTADs)),
+	s.handle(
+		"GET /2/footprints/{id}",
+		"GetFootprint",
+		s.dataRoute(s.pactAuthMiddleware, s.getFootprint),
 	)
-	s.serveMux.Handle(
-		"POST "+s.pathPrefix+"/2/events",
-		s.pactEventsAuthMiddleware(http.HandlerFunc(s.events)),
-	)
-	s.serveMux.HandleFunc("POST "+s.pathPrefix+"/auth/token", s.authToken)
+	s.handle("GET /2/ileap/tad", "ListTADs", s.dataRoute(s.ileapAuthMiddleware, s.listTADs))
+	s.handle("POST /2/events", "PostEvent", s.dataRoute(s.pactEventsAuthMiddleware, s.postEvent))
+	s.handle("POST /auth/token", "IssueToken", s.authRoute(s.authToken))
+	s.handle("POST /auth/introspect", "IntrospectToken", s.authRoute(s.authIntrospect))
+	s.handle("POST /auth/revoke", "RevokeToken", s.authRoute(s.authRevoke))
 	// Workaround for ACT bug: PACT TC18/19 (OpenID Connect flow) mistakenly POSTs
 	// to the base URL (/) instead of the token_endpoint advertised in
 	// /.well-known/openid-configuration.
-	s.serveMux.HandleFunc("POST "+s.pathPrefix+"/", s.authToken)
-	s.serveMux.HandleFunc(
-		"GET "+s.pathPrefix+"/.well-known/openid-configuration",
-		s.openIDConfig,
+	s.handle("POST /", "IssueToken", s.authRoute(s.authToken))
+	s.handle(
+		"GET /.well-known/openid-configuration",
+		"OpenIDConfiguration",
+		s.ipRateLimitMiddleware(http.HandlerFunc(s.openIDConfig), false),
 	)
-	s.serveMux.HandleFunc("GET "+s.pathPrefix+"/jwks", s.jwks)
+	s.handle("GET /jwks", "JWKS", s.ipRateLimitMiddleware(http.HandlerFunc(s.jwks), false))
+}
+
+// handle registers the handler of an endpoint for a "METHOD /path" pattern
+// below the path prefix, instrumented with the endpoint name.
+func (s *Server) handle(pattern, endpoint string, handler http.Handler) {
+	method, path, _ := strings.Cut(pattern, " ")
+	s.serveMux.Handle(method+" "+s.pathPrefix+path, s.instrument(endpoint, handler))
+}
+
+// dataRoute wraps a data endpoint handler with the audit log, the rate limits
+// and the auth middleware.
+func (s *Server) dataRoute(
+	auth func(http.Handler) http.Handler,
+	handler http.HandlerFunc,
+) http.Handler {
+	inner := s.auditCallerMiddleware(s.clientRateLimitMiddleware(handler))
+	return s.auditMiddleware(s.ipRateLimitMiddleware(auth(inner), false))
+}
+
+// authRoute wraps an auth endpoint handler with the per-IP rate limit.
+func (s *Server) authRoute(handler http.HandlerFunc) http.Handler {
+	return s.ipRateLimitMiddleware(handler, true)
 }
 
 // pactAuthMiddleware validates the bearer token using the server's TokenValidator
@@ -125,10 +225,12 @@ func (s *Server) pactAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "missing authorization")
 			return
 		}
 		if !strings.HasPrefix(authHeader, "Bearer ") {
+			s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 			writeError(
 				w,
 				http.StatusBadRequest,
@@ -139,20 +241,26 @@ func (s *Server) pactAuthMiddleware(next http.Handler) http.Handler {
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "missing access token")
 			return
 		}
-		if _, err := s.auth.ValidateToken(r.Context(), token); err != nil {
+		info, err := s.validateToken(r, token)
+		if err != nil {
 			if connect.CodeOf(err) == connect.CodeUnimplemented {
+				s.recordAuthOutcome(r.Context(), authOutcomeError)
 				writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
 				return
 			}
+			s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 			slog.WarnContext(r.Context(), "token validation failed", "error", err)
 			// PACT conformance recommendations prefer BadRequest for invalid tokens.
 			writeError(w, http.StatusUnauthorized, ErrorCodeBadRequest, "invalid access token")
 			return
 		}
+		s.recordAuthOutcome(r.Context(), authOutcomeSuccess)
 		ctx := WithAuthToken(r.Context(), token)
+		ctx = WithTokenInfo(ctx, info)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -164,10 +272,12 @@ func (s *Server) pactEventsAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "missing authorization")
 			return
 		}
 		if !strings.HasPrefix(authHeader, "Bearer ") {
+			s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 			writeError(
 				w,
 				http.StatusBadRequest,
@@ -178,20 +288,26 @@ func (s *Server) pactEventsAuthMiddleware(next http.Handler) http.Handler {
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "missing access token")
 			return
 		}
-		if _, err := s.auth.ValidateToken(r.Context(), token); err != nil {
+		info, err := s.validateToken(r, token)
+		if err != nil {
 			if connect.CodeOf(err) == connect.CodeUnimplemented {
+				s.recordAuthOutcome(r.Context(), authOutcomeError)
 				writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
 				return
 			}
+			s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 			slog.WarnContext(r.Context(), "token validation failed", "error", err)
 			// PACT conformance source-of-truth (TC16) expects BadRequest here.
 			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "invalid access token")
 			return
 		}
+		s.recordAuthOutcome(r.Context(), authOutcomeSuccess)
 		ctx := WithAuthToken(r.Context(), token)
+		ctx = WithTokenInfo(ctx, info)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -203,10 +319,12 @@ func (s *Server) ileapAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusForbidden, ErrorCodeAccessDenied, "missing authorization")
 			return
 		}
 		if !strings.HasPrefix(authHeader, "Bearer ") {
+			s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 			writeError(
 				w,
 				http.StatusForbidden,
@@ -217,10 +335,12 @@ func (s *Server) ileapAuthMiddleware(next http.Handler) http.Handler {
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		if token == "" {
+			s.recordAuthOutcome(r.Context(), authOutcomeMissing)
 			writeError(w, http.StatusForbidden, ErrorCodeAccessDenied, "missing access token")
 			return
 		}
 		if isExpiredJWT(token) {
+			s.recordAuthOutcome(r.Context(), authOutcomeExpired)
 			slog.WarnContext(
 				r.Context(),
 				"token expired",
@@ -230,20 +350,26 @@ func (s *Server) ileapAuthMiddleware(next http.Handler) http.Handler {
 			writeError(w, http.StatusUnauthorized, ErrorCodeTokenExpired, "token expired")
 			return
 		}
-		if _, err := s.auth.ValidateToken(r.Context(), token); err != nil {
+		info, err := s.validateToken(r, token)
+		if err != nil {
 			switch connect.CodeOf(err) {
 			case connect.CodeUnimplemented:
+				s.recordAuthOutcome(r.Context(), authOutcomeError)
 				writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
 			case connect.CodeUnauthenticated:
+				s.recordAuthOutcome(r.Context(), authOutcomeExpired)
 				slog.WarnContext(r.Context(), "token expired", "error", err)
 				writeError(w, http.StatusUnauthorized, ErrorCodeTokenExpired, "token expired")
 			default:
+				s.recordAuthOutcome(r.Context(), authOutcomeInvalid)
 				slog.WarnContext(r.Context(), "token validation failed", "error", err)
 				writeError(w, http.StatusForbidden, ErrorCodeAccessDenied, "invalid access token")
 			}
 			return
 		}
+		s.recordAuthOutcome(r.Context(), authOutcomeSuccess)
 		ctx := WithAuthToken(r.Context(), token)
+		ctx = WithTokenInfo(ctx, info)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -251,34 +377,49 @@ func (s *Server) ileapAuthMiddleware(next http.Handler) http.Handler {
 // isExpiredJWT is a fallback for auth handlers that do not return
 // connect.CodeUnauthenticated for expired tokens.
 func isExpiredJWT(token string) bool {
-	parts := strings.Split(token, ".")
-	if len(parts) != 3 {
-		return false
-	}
-	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
-	if err != nil {
-		return false
-	}
-	var claims map[string]any
-	if err := json.Unmarshal(payloadBytes, &claims); err != nil {
-		return false
+	exp, ok := jwtExpiry(token)
+	return ok && time.Now().Unix() > exp.Unix()
+}
+
+// jwtExpiry returns the exp claim of a JWT, without verifying the JWT.
+func jwtExpiry(token string) (time.Time, bool) {
+	claims, ok := jwtClaims(token)
+	if !ok {
+		return time.Time{}, false
 	}
 	rawExp, ok := claims["exp"]
 	if !ok {
-		return false
+		return time.Time{}, false
 	}
 	switch exp := rawExp.(type) {
 	case float64:
-		return time.Now().Unix() > int64(exp)
+		return time.Unix(int64(exp), 0), true
 	case string:
 		n, err := strconv.ParseInt(exp, 10, 64)
 		if err != nil {
-			return false
+			return time.Time{}, false
 		}
-		return time.Now().Unix() > n
+		return time.Unix(n, 0), true
 	default:
-		return false
+		return time.Time{}, false
+	}
+}
+
+// jwtClaims returns the claims of a JWT, without verifying the JWT.
+func jwtClaims(token string) (map[string]any, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, false
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payloadBytes, &claims); err != nil {
+		return nil, false
 	}
+	return claims, true
 }
 
 func (s *Server) resolveBaseURL(r *http.Request) string {
@@ -293,6 +434,36 @@ func (s *Server) resolveBaseURL(r *http.Request) string {
 	return scheme + "://" + r.Host + s.pathPrefix
 }
 
+// setNextLink sets an RFC 8288 Link header with rel="next" pointing to the
+// page at offset. Query parameters other than limit and offset (such as
+// filters) are carried over from the current request.
+func (s *Server) setNextLink(
+	w http.ResponseWriter,
+	r *http.Request,
+	path string,
+	limit, offset int,
+) {
+	query := r.URL.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	linkURL := s.resolveBaseURL(r) + path + "?" + query.Encode()
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", linkURL))
+}
+
+// checkResponse validates response messages when response validation is enabled.
+// On violations, it writes an InternalError response and returns false.
+func (s *Server) checkResponse(w http.ResponseWriter, validate func() error) bool {
+	if !s.validateResponses {
+		return true
+	}
+	if err := validate(); err != nil {
+		slog.Error("invalid response", "error", err)
+		writeError(w, http.StatusInternalServerError, ErrorCodeInternalError, "internal error")
+		return false
+	}
+	return true
+}
+
 func (s *Server) authToken(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
 		writeOAuthError(
@@ -321,27 +492,18 @@ func (s *Server) authToken(w http.ResponseWriter, r *http.Request) {
 		)
 		return
 	}
-	username, password, ok := r.BasicAuth()
+	req, ok := s.parseClientAuthentication(w, r)
 	if !ok {
-		writeOAuthError(
-			w,
-			http.StatusBadRequest,
-			OAuthErrorCodeInvalidRequest,
-			"missing HTTP basic authorization",
-		)
 		return
 	}
-
-	clientID, err := url.QueryUnescape(username)
-	if err != nil {
-		clientID = username
+	if !s.checkTokenRequestLimits(w, r, req.ClientID) {
+		return
 	}
-	clientSecret, err := url.QueryUnescape(password)
-	if err != nil {
-		clientSecret = password
+	creds, err := s.issueToken(r.Context(), req)
+	if code := connect.CodeOf(err); err == nil || code == connect.CodePermissionDenied {
+		s.recordAuthentication(r, req.ClientID, err == nil)
 	}
-
-	creds, err := s.auth.IssueToken(r.Context(), clientID, clientSecret)
+	s.recordTokenRequest(r.Context(), req.AuthMethod, tokenRequestOutcome(err))
 	if err != nil {
 		switch connect.CodeOf(err) {
 		case connect.CodeUnimplemented:
@@ -362,8 +524,25 @@ func (s *Server) authToken(w http.ResponseWriter, r *http.Request) {
 				OAuthErrorCodeTemporarilyUnavailable,
 				"temporarily unavailable",
 			)
+		case connect.CodeInvalidArgument:
+			writeOAuthError(
+				w,
+				http.StatusBadRequest,
+				OAuthErrorCodeInvalidScope,
+				"invalid scope",
+			)
 		case connect.CodePermissionDenied:
 			slog.WarnContext(r.Context(), "failed to issue token", "error", err)
+			if req.AuthMethod != ClientAuthMethodSecretBasic {
+				writeOAuthError(
+					w,
+					http.StatusUnauthorized,
+					OAuthErrorCodeInvalidClient,
+					"invalid client authentication",
+				)
+				return
+			}
+			// PACT conformance expects 400 for invalid basic auth credentials.
 			writeOAuthError(
 				w,
 				http.StatusBadRequest,
@@ -385,12 +564,21 @@ func (s *Server) authToken(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) openIDConfig(w http.ResponseWriter, r *http.Request) {
-	cfg := s.auth.OpenIDConfiguration(s.resolveBaseURL(r))
-	if cfg == nil {
+	baseURL := s.resolveBaseURL(r)
+	handlerCfg := s.auth.OpenIDConfiguration(baseURL)
+	if handlerCfg == nil {
 		writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
 		return
 	}
-	writeJSON(w, cfg)
+	// Advertise the optional endpoints without modifying the handler's value.
+	cfg := *handlerCfg
+	if _, ok := s.auth.(TokenIntrospector); ok && cfg.IntrospectionURL == "" {
+		cfg.IntrospectionURL = baseURL + "/auth/introspect"
+	}
+	if _, ok := s.auth.(TokenRevoker); ok && cfg.RevocationURL == "" {
+		cfg.RevocationURL = baseURL + "/auth/revoke"
+	}
+	writeJSON(w, &cfg)
 }
 
 func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
@@ -414,38 +602,76 @@ func (s *Server) listFootprints(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req := new(ileapv1.ListFootprintsRequest)
-	req.SetLimit(int32(limit))
-	req.SetOffset(int32(offset))
-	req.SetFilters(odataFilterToFootprintFilters(r.URL.Query().Get("$filter")))
-	resp, err := s.service.ListFootprints(r.Context(), req)
+	if s.accessPolicy == nil {
+		req.SetLimit(int32(limit))
+		req.SetOffset(int32(offset))
+	}
+	filter := r.URL.Query().Get("$filter")
+	if !s.setFootprintFilter(w, req, filter) {
+		return
+	}
+	auditFilters(r.Context(), filter, req.GetFilters())
+	validators := make(http.Header)
+	resp, err := s.service.ListFootprints(withResponseHeader(r.Context(), validators), req)
 	if err != nil {
 		writeHandlerError(w, err)
 		return
 	}
 	data := resp.GetData()
 	total := int(resp.GetTotal())
+	if s.accessPolicy != nil {
+		data, total, err = filterAccessible(
+			r.Context(), data, s.accessPolicy.CanAccessFootprint, limit, offset,
+		)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+		// The handler's validators are for the unfiltered response.
+		clear(validators)
+	}
+	recordListAttributes(r.Context(), len(req.GetFilters()), len(data))
+	if !s.checkResponse(w, func() error { return validateAll(data) }) {
+		return
+	}
 	next := offset + len(data)
 	if next < total {
 		linkLimit := limit
 		if linkLimit == 0 {
 			linkLimit = len(data)
 		}
-		base := s.resolveBaseURL(r)
-		linkURL := fmt.Sprintf("%s/2/footprints?limit=%d&offset=%d", base, linkLimit, next)
-		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", linkURL))
+		s.setNextLink(w, r, "/2/footprints", linkLimit, next)
 	}
-	writeListFootprintsResponse(w, data)
+	auditFootprints(r.Context(), data...)
+	writeListFootprintsResponse(w, r, validators, data)
 }
 
 func (s *Server) getFootprint(w http.ResponseWriter, r *http.Request) {
 	req := new(ileapv1.GetFootprintRequest)
 	req.SetId(r.PathValue("id"))
-	resp, err := s.service.GetFootprint(r.Context(), req)
+	validators := make(http.Header)
+	resp, err := s.service.GetFootprint(withResponseHeader(r.Context(), validators), req)
 	if err != nil {
 		writeHandlerError(w, err)
 		return
 	}
-	writeGetFootprintResponse(w, resp.GetData())
+	if s.accessPolicy != nil {
+		info, _ := TokenInfoFromContext(r.Context())
+		ok, err := s.accessPolicy.CanAccessFootprint(r.Context(), info, resp.GetData())
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+		if !ok {
+			writeHandlerError(w, connect.NewError(connect.CodeNotFound, nil))
+			return
+		}
+	}
+	if !s.checkResponse(w, func() error { return validateData(resp.GetData()) }) {
+		return
+	}
+	auditFootprints(r.Context(), resp.GetData())
+	writeGetFootprintResponse(w, r, validators, resp.GetData())
 }
 
 func (s *Server) listTADs(w http.ResponseWriter, r *http.Request) {
@@ -460,10 +686,13 @@ func (s *Server) listTADs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req := new(ileapv1.ListTransportActivityDataRequest)
-	req.SetLimit(int32(limit))
-	req.SetOffset(int32(offset))
+	if s.accessPolicy == nil {
+		req.SetLimit(int32(limit))
+		req.SetOffset(int32(offset))
+	}
 	q := r.URL.Query()
 	req.SetFilters(queryToTADFilters(q, "limit", "offset"))
+	auditFilters(r.Context(), "", req.GetFilters())
 	resp, err := s.service.ListTransportActivityData(r.Context(), req)
 	if err != nil {
 		writeHandlerError(w, err)
@@ -471,20 +700,32 @@ func (s *Server) listTADs(w http.ResponseWriter, r *http.Request) {
 	}
 	data := resp.GetData()
 	total := int(resp.GetTotal())
+	if s.accessPolicy != nil {
+		data, total, err = filterAccessible(
+			r.Context(), data, s.accessPolicy.CanAccessTAD, limit, offset,
+		)
+		if err != nil {
+			writeHandlerError(w, err)
+			return
+		}
+	}
+	recordListAttributes(r.Context(), len(req.GetFilters()), len(data))
+	if !s.checkResponse(w, func() error { return validateAll(data) }) {
+		return
+	}
 	next := offset + len(data)
 	if next < total {
 		linkLimit := limit
 		if linkLimit == 0 {
 			linkLimit = len(data)
 		}
-		base := s.resolveBaseURL(r)
-		linkURL := fmt.Sprintf("%s/2/ileap/tad?offset=%d&limit=%d", base, next, linkLimit)
-		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", linkURL))
+		s.setNextLink(w, r, "/2/ileap/tad", linkLimit, next)
 	}
+	auditTADs(r.Context(), data)
 	writeListTADsResponse(w, data)
 }
 
-func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+func (s *Server) postEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-Type") == "" {
 		writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "missing content type")
 		return
@@ -514,6 +755,7 @@ func (s *Server) events(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "invalid request body")
 		return
 	}
+	auditEvent(r.Context(), event)
 	if event.Specversion != "1.0" || event.ID == "" || event.Source == "" {
 		writeError(
 			w,
@@ -540,14 +782,29 @@ func (s *Server) events(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "invalid event type")
 		return
 	}
+	if s.events == nil {
+		return
+	}
+	info, _ := TokenInfoFromContext(r.Context())
+	if err := dispatchEvent(r.Context(), s.events, info, event); err != nil {
+		var payloadErr *eventPayloadError
+		if errors.As(err, &payloadErr) {
+			writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "invalid request body")
+			return
+		}
+		writeHandlerError(w, err)
+		return
+	}
 }
 
 type cloudEventEnvelope struct {
-	Type        string          `json:"type"`
-	Specversion string          `json:"specversion"`
-	ID          string          `json:"id"`
-	Source      string          `json:"source"`
-	Data        json.RawMessage `json:"data"`
+	Type            string          `json:"type"`
+	Specversion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data"`
 }
 
 func decodeCloudEvent(body []byte) (*event, error) {
@@ -564,6 +821,7 @@ func decodeCloudEvent(body []byte) (*event, error) {
 		Specversion: envelope.Specversion,
 		ID:          envelope.ID,
 		Source:      envelope.Source,
+		Time:        envelope.Time,
 		Data:        data,
 	}, nil
 }
@@ -648,9 +906,37 @@ func parseOffset(r *http.Request) (int, error) {
 	return offset, nil
 }
 
-func odataFilterToFootprintFilters(filter string) []*ileapv1.Filter {
-	parsed := odata.ParseFilter(filter)
-	return parsed
+// setFootprintFilter sets the filter expression and filter pairs parsed from
+// an OData $filter on the request. In strict filtering mode, it writes an
+// error response and returns false if the filter cannot be parsed.
+func (s *Server) setFootprintFilter(
+	w http.ResponseWriter,
+	req *ileapv1.ListFootprintsRequest,
+	filter string,
+) bool {
+	expr, err := odata.ParseExpression(filter)
+	if err == nil {
+		req.SetFilter(expr)
+		// Filter pairs only carry the comparisons joined by a top-level "and",
+		// as documented on ListFootprintsRequest. Handlers evaluate the other
+		// clauses from the expression.
+		filters, _ := odata.Conjuncts(expr)
+		req.SetFilters(filters)
+		return true
+	}
+	if !s.strictFiltering {
+		// Fall back to the filter pairs that can be recovered from the
+		// expression, ignoring the rest.
+		req.SetFilters(odata.ParseFilter(filter))
+		return true
+	}
+	var unsupportedErr *odata.UnsupportedError
+	if errors.As(err, &unsupportedErr) {
+		writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "%v", err)
+	} else {
+		writeError(w, http.StatusBadRequest, ErrorCodeBadRequest, "%v", err)
+	}
+	return false
 }
 
 func queryToTADFilters(
@@ -768,40 +1054,36 @@ func writeJSON(w http.ResponseWriter, v any) {
 	}
 }
 
-func writeGetFootprintResponse(w http.ResponseWriter, fp *ileapv1.ProductFootprint) {
-	w.Header().Set("Content-Type", "application/json")
+func writeGetFootprintResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	validators http.Header,
+	fp *ileapv1.ProductFootprint,
+) {
 	data, err := protojson.Marshal(fp)
 	if err != nil {
 		slog.Error("failed to marshal footprint", "error", err)
 		writeError(w, http.StatusInternalServerError, ErrorCodeInternalError, "internal error")
 		return
 	}
-	if _, err := w.Write([]byte(`{"data":`)); err != nil {
-		slog.Error("failed to write response", "error", err)
-		return
-	}
-	if _, err := w.Write(data); err != nil {
-		slog.Error("failed to write response", "error", err)
-		return
-	}
-	if _, err := w.Write([]byte(`}`)); err != nil {
-		slog.Error("failed to write response", "error", err)
-		return
-	}
+	var body bytes.Buffer
+	body.WriteString(`{"data":`)
+	body.Write(data)
+	body.WriteString(`}`)
+	writeFootprintsResponse(w, r, validators, body.Bytes(), fp)
 }
 
-func writeListFootprintsResponse(w http.ResponseWriter, fps []*ileapv1.ProductFootprint) {
-	w.Header().Set("Content-Type", "application/json")
-	if _, err := w.Write([]byte(`{"data":[`)); err != nil {
-		slog.Error("failed to write response", "error", err)
-		return
-	}
+func writeListFootprintsResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	validators http.Header,
+	fps []*ileapv1.ProductFootprint,
+) {
+	var body bytes.Buffer
+	body.WriteString(`{"data":[`)
 	for i, fp := range fps {
 		if i > 0 {
-			if _, err := w.Write([]byte(",")); err != nil {
-				slog.Error("failed to write response", "error", err)
-				return
-			}
+			body.WriteString(",")
 		}
 		data, err := protojson.Marshal(fp)
 		if err != nil {
@@ -809,15 +1091,10 @@ func writeListFootprintsResponse(w http.ResponseWriter, fps []*ileapv1.ProductFo
 			writeError(w, http.StatusInternalServerError, ErrorCodeInternalError, "internal error")
 			return
 		}
-		if _, err := w.Write(data); err != nil {
-			slog.Error("failed to write response", "error", err)
-			return
-		}
-	}
-	if _, err := w.Write([]byte(`]}`)); err != nil {
-		slog.Error("failed to write response", "error", err)
-		return
+		body.Write(data)
 	}
+	body.WriteString(`]}`)
+	writeFootprintsResponse(w, r, validators, body.Bytes(), nil)
 }
 
 func writeListTADsResponse(w http.ResponseWriter, tads []*ileapv1.TAD) {
@@ -0,0 +1,30 @@
+package ileap
+
+import "context"
+
+// filterAccessible returns the page of records the caller may access, and the
+// total number of accessible records.
+func filterAccessible[T any](
+	ctx context.Context,
+	records []T,
+	canAccess func(context.Context, *TokenInfo, T) (bool, error),
+	limit, offset int,
+) ([]T, int, error) {
+	info, _ := TokenInfoFromContext(ctx)
+	accessible := make([]T, 0, len(records))
+	for _, record := range records {
+		ok, err := canAccess(ctx, info, record)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			accessible = append(accessible, record)
+		}
+	}
+	total := len(accessible)
+	accessible = accessible[min(offset, total):]
+	if limit > 0 && len(accessible) > limit {
+		accessible = accessible[:limit]
+	}
+	return accessible, total, nil
+}
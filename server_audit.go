@@ -0,0 +1,225 @@
+package ileap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// AuditRecord is the audit record of a request to a data endpoint, see
+// [WithAuditSink].
+type AuditRecord struct {
+	// Time is the time the request was received.
+	Time time.Time `json:"time"`
+	// Duration is the time taken to serve the request, in nanoseconds.
+	Duration time.Duration `json:"durationNs"`
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+	// Path is the URL path of the request.
+	Path string `json:"path"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// Subject is the subject of the caller's token. Empty if authentication
+	// failed.
+	Subject string `json:"subject,omitempty"`
+	// Organization is the organization of the caller's token.
+	Organization string `json:"organization,omitempty"`
+	// ClientIP is the IP address of the caller.
+	ClientIP string `json:"clientIp"`
+	// Filter is the OData $filter expression of a footprint list request.
+	Filter string `json:"filter,omitempty"`
+	// Filters are the filter pairs passed to the service handler.
+	Filters []AuditFilter `json:"filters,omitempty"`
+	// Footprints are the footprints returned to the caller.
+	Footprints []AuditFootprint `json:"footprints,omitempty"`
+	// TADs are the activity IDs of the TADs returned to the caller.
+	TADs []string `json:"tads,omitempty"`
+	// EventType is the type of an event received on POST /2/events.
+	EventType string `json:"eventType,omitempty"`
+	// EventID is the ID of an event received on POST /2/events.
+	EventID string `json:"eventId,omitempty"`
+}
+
+// AuditFilter is a filter pair of an [AuditRecord].
+type AuditFilter struct {
+	// FieldPath is the field path of the filter, e.g. "pcf.geographyCountry".
+	FieldPath string `json:"fieldPath"`
+	// Operator is the comparison operator of the filter, e.g. "EQ".
+	Operator string `json:"operator"`
+	// Value is the value compared against.
+	Value string `json:"value"`
+}
+
+// AuditFootprint identifies a footprint version returned to the caller.
+type AuditFootprint struct {
+	// ID is the footprint ID.
+	ID string `json:"id"`
+	// Version is the footprint version.
+	Version int32 `json:"version"`
+}
+
+// WithAuditSink writes an [AuditRecord] of every request to GET /2/footprints,
+// GET /2/footprints/{id}, GET /2/ileap/tad and POST /2/events to the sink,
+// including requests rejected by authentication or rate limits. Records are
+// written after the response, on the request's goroutine.
+func WithAuditSink(sink AuditSink) ServerOption {
+	return func(s *Server) { s.audit = sink }
+}
+
+// JSONLinesAuditSink is an [AuditSink] that writes each record as a line of
+// JSON. It is safe for concurrent use.
+type JSONLinesAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ AuditSink = (*JSONLinesAuditSink)(nil)
+
+// NewJSONLinesAuditSink creates a JSONLinesAuditSink writing to w.
+func NewJSONLinesAuditSink(w io.Writer) *JSONLinesAuditSink {
+	return &JSONLinesAuditSink{w: w}
+}
+
+// OpenJSONLinesAuditSink creates a JSONLinesAuditSink appending to the file at
+// path, which is created if it does not exist. Close the sink to close the
+// file.
+func OpenJSONLinesAuditSink(path string) (*JSONLinesAuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return NewJSONLinesAuditSink(f), nil
+}
+
+// WriteAudit implements [AuditSink].
+func (s *JSONLinesAuditSink) WriteAudit(_ context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+	data = append(data, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying writer, if it is an [io.Closer].
+func (s *JSONLinesAuditSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+type auditRecordContextKey struct{}
+
+// auditMiddleware writes an audit record of the request to the audit sink. The
+// handlers add the details of the request to the record in the context.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	if s.audit == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record := &AuditRecord{
+			Time:     time.Now(),
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			ClientIP: s.clientIP(r),
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		ctx := context.WithValue(r.Context(), auditRecordContextKey{}, record)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		record.Duration = time.Since(record.Time)
+		record.Status = rec.status
+		if err := s.audit.WriteAudit(r.Context(), record); err != nil {
+			slog.ErrorContext(r.Context(), "failed to write audit record", "error", err)
+		}
+	})
+}
+
+// auditCallerMiddleware adds the authenticated caller to the audit record. It
+// must run after an auth middleware.
+func (s *Server) auditCallerMiddleware(next http.Handler) http.Handler {
+	if s.audit == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if record := auditRecordFromContext(r.Context()); record != nil {
+			if info, ok := TokenInfoFromContext(r.Context()); ok {
+				record.Subject = info.Subject
+				record.Organization = info.Organization
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func auditRecordFromContext(ctx context.Context) *AuditRecord {
+	record, _ := ctx.Value(auditRecordContextKey{}).(*AuditRecord)
+	return record
+}
+
+// auditFilters adds the filters of a list request to the audit record.
+func auditFilters(ctx context.Context, filter string, filters []*ileapv1.Filter) {
+	record := auditRecordFromContext(ctx)
+	if record == nil {
+		return
+	}
+	record.Filter = filter
+	for _, f := range filters {
+		record.Filters = append(record.Filters, AuditFilter{
+			FieldPath: f.GetFieldPath(),
+			Operator:  f.GetOperator().String(),
+			Value:     f.GetValue(),
+		})
+	}
+}
+
+// auditFootprints adds the footprints returned to the caller to the audit
+// record.
+func auditFootprints(ctx context.Context, footprints ...*ileapv1.ProductFootprint) {
+	record := auditRecordFromContext(ctx)
+	if record == nil {
+		return
+	}
+	for _, fp := range footprints {
+		record.Footprints = append(record.Footprints, AuditFootprint{
+			ID:      fp.GetId(),
+			Version: fp.GetVersion(),
+		})
+	}
+}
+
+// auditTADs adds the TADs returned to the caller to the audit record.
+func auditTADs(ctx context.Context, tads []*ileapv1.TAD) {
+	record := auditRecordFromContext(ctx)
+	if record == nil {
+		return
+	}
+	for _, tad := range tads {
+		record.TADs = append(record.TADs, tad.GetActivityId())
+	}
+}
+
+// auditEvent adds a received event to the audit record.
+func auditEvent(ctx context.Context, e *event) {
+	record := auditRecordFromContext(ctx)
+	if record == nil {
+		return
+	}
+	record.EventType = e.Type
+	record.EventID = e.ID
+}
@@ -0,0 +1,182 @@
+package ileap
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"connectrpc.com/connect"
+	"golang.org/x/oauth2"
+)
+
+// jwtBearerAssertionType is the client_assertion_type of JWT client
+// assertions (RFC 7523, section 2.2).
+const jwtBearerAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// parseClientAuthentication determines the client authentication method of a
+// token request and returns the request for the auth handler. On failure, it
+// writes an OAuth error response and returns false.
+func (s *Server) parseClientAuthentication(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*TokenRequest, bool) {
+	cert, err := s.clientCertificate(r)
+	if err != nil {
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeInvalidRequest,
+			"invalid client certificate",
+		)
+		return nil, false
+	}
+	baseURL := s.resolveBaseURL(r)
+	req := &TokenRequest{
+		ClientID:          r.PostForm.Get("client_id"),
+		ClientCertificate: cert,
+		Scope:             strings.Fields(r.PostForm.Get("scope")),
+		Issuer:            baseURL,
+		TokenEndpoint:     baseURL + "/auth/token",
+	}
+	username, password, hasBasicAuth := r.BasicAuth()
+	assertionType := r.PostForm.Get("client_assertion_type")
+	assertion := r.PostForm.Get("client_assertion")
+	hasAssertion := assertionType != "" || assertion != ""
+	switch {
+	case hasBasicAuth && hasAssertion:
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeInvalidRequest,
+			"multiple client authentication methods",
+		)
+		return nil, false
+	case hasBasicAuth:
+		req.AuthMethod = ClientAuthMethodSecretBasic
+		req.ClientID = unescapeCredential(username)
+		req.ClientSecret = unescapeCredential(password)
+	case hasAssertion:
+		if assertionType != jwtBearerAssertionType || assertion == "" {
+			writeOAuthError(
+				w,
+				http.StatusBadRequest,
+				OAuthErrorCodeInvalidRequest,
+				"invalid client assertion",
+			)
+			return nil, false
+		}
+		// The client_id parameter is optional with client assertions, whose
+		// subject is the client ID (RFC 7523, section 3).
+		var subject string
+		if claims, ok := jwtClaims(assertion); ok {
+			subject, _ = claims["sub"].(string)
+		}
+		if req.ClientID == "" {
+			req.ClientID = subject
+		}
+		if req.ClientID == "" || subject != req.ClientID {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ileap"`)
+			writeOAuthError(
+				w,
+				http.StatusUnauthorized,
+				OAuthErrorCodeInvalidClient,
+				"invalid client assertion",
+			)
+			return nil, false
+		}
+		req.AuthMethod = ClientAuthMethodPrivateKeyJWT
+		req.ClientAssertion = assertion
+	case cert != nil && req.ClientID != "":
+		req.AuthMethod = ClientAuthMethodSelfSignedTLS
+		if s.clientCertHeader == "" && len(r.TLS.VerifiedChains) > 0 {
+			req.AuthMethod = ClientAuthMethodTLS
+		}
+	default:
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeInvalidRequest,
+			"missing HTTP basic authorization",
+		)
+		return nil, false
+	}
+	return req, true
+}
+
+// issueToken passes the token request to the auth handler. Auth handlers that
+// do not implement [TokenRequestHandler] only support client secrets sent with
+// HTTP basic auth.
+func (s *Server) issueToken(
+	ctx context.Context,
+	req *TokenRequest,
+) (_ *oauth2.Token, err error) {
+	ctx, end := s.startSpan(ctx, "AuthHandler.IssueToken")
+	defer func() { end(err) }()
+	if h, ok := s.auth.(TokenRequestHandler); ok {
+		return h.HandleTokenRequest(ctx, req)
+	}
+	if req.AuthMethod != ClientAuthMethodSecretBasic {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			fmt.Errorf("unsupported client authentication method: %s", req.AuthMethod),
+		)
+	}
+	return s.auth.IssueToken(ctx, req.ClientID, req.ClientSecret)
+}
+
+// clientCertificate returns the TLS client certificate of the request, or nil
+// if the client presented none.
+func (s *Server) clientCertificate(r *http.Request) (*x509.Certificate, error) {
+	if s.clientCertHeader != "" {
+		value := r.Header.Get(s.clientCertHeader)
+		if value == "" {
+			return nil, nil
+		}
+		// PathUnescape keeps the '+' characters of unescaped base64 data.
+		pemData, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("unescape client certificate: %w", err)
+		}
+		block, _ := pem.Decode([]byte(pemData))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return nil, errors.New("client certificate header is not a PEM certificate")
+		}
+		return x509.ParseCertificate(block.Bytes)
+	}
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+	return r.TLS.PeerCertificates[0], nil
+}
+
+// checkCertificateBinding checks that a certificate-bound token is presented
+// with the client certificate it is bound to (RFC 8705, section 3).
+func (s *Server) checkCertificateBinding(r *http.Request, info *TokenInfo) error {
+	if info.CertificateThumbprint == "" {
+		return nil
+	}
+	cert, err := s.clientCertificate(r)
+	if err != nil {
+		return connect.NewError(connect.CodePermissionDenied, err)
+	}
+	if cert == nil {
+		return connect.NewError(
+			connect.CodePermissionDenied,
+			errors.New("token is bound to a client certificate"),
+		)
+	}
+	thumbprint := CertificateThumbprint(cert)
+	if subtle.ConstantTimeCompare([]byte(thumbprint), []byte(info.CertificateThumbprint)) != 1 {
+		return connect.NewError(
+			connect.CodePermissionDenied,
+			errors.New("token is bound to another client certificate"),
+		)
+	}
+	return nil
+}
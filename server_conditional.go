@@ -0,0 +1,111 @@
+package ileap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// writeFootprintsResponse writes a footprint response body with its ETag and
+// Last-Modified validators, or a 304 Not Modified response if the request's
+// preconditions show that the client already has it (RFC 9110, section 13).
+//
+// The validators are taken from the service handler's response headers if it
+// set them, and are otherwise computed: a strong ETag from the body, and
+// Last-Modified from the updated or created time of the footprint. Lists, for
+// which footprint is nil, only carry an ETag: their latest modification time
+// does not change when footprints are deleted or stop matching the filter.
+func writeFootprintsResponse(
+	w http.ResponseWriter,
+	r *http.Request,
+	validators http.Header,
+	body []byte,
+	footprint *ileapv1.ProductFootprint,
+) {
+	etag := validators.Get("ETag")
+	if etag == "" {
+		etag = computeETag(body)
+	}
+	w.Header().Set("ETag", etag)
+	var lastModified string
+	if footprint != nil {
+		lastModified = validators.Get("Last-Modified")
+		if lastModified == "" {
+			if t := modificationTime(footprint); !t.IsZero() {
+				lastModified = t.UTC().Format(http.TimeFormat)
+			}
+		}
+	}
+	if lastModified != "" {
+		w.Header().Set("Last-Modified", lastModified)
+	}
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
+
+// computeETag returns a strong ETag of a response body.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// modificationTime returns the updated time of the footprint, or its created
+// time if it was never updated.
+func modificationTime(fp *ileapv1.ProductFootprint) time.Time {
+	ts := fp.GetUpdated()
+	if ts == nil {
+		ts = fp.GetCreated()
+	}
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since preconditions
+// of a GET request. If-Modified-Since is ignored when If-None-Match is present
+// (RFC 9110, section 13.2.2).
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagListMatches(ifNoneMatch, etag)
+	}
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// etagListMatches reports whether an If-None-Match list matches the ETag,
+// using the weak comparison function (RFC 9110, section 8.8.3.2).
+func etagListMatches(list, etag string) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(list, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
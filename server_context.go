@@ -1,10 +1,17 @@
 package ileap
 
-import "context"
+import (
+	"context"
+	"net/http"
+)
 
 type contextKey int
 
-const authTokenKey contextKey = iota
+const (
+	authTokenKey contextKey = iota
+	tokenInfoKey
+	responseHeaderKey
+)
 
 // WithAuthToken returns a new context with the given bearer token stored.
 // This is used by the auth middleware to propagate the validated token to
@@ -19,3 +26,34 @@ func AuthTokenFromContext(ctx context.Context) (string, bool) {
 	token, ok := ctx.Value(authTokenKey).(string)
 	return token, ok
 }
+
+// WithTokenInfo returns a new context with the given validated token info
+// stored. This is used by the auth middleware to propagate the caller's
+// identity to downstream handlers.
+func WithTokenInfo(ctx context.Context, info *TokenInfo) context.Context {
+	return context.WithValue(ctx, tokenInfoKey, info)
+}
+
+// TokenInfoFromContext retrieves the validated token info from the context.
+// Returns the token info and true if present, or nil and false otherwise.
+func TokenInfoFromContext(ctx context.Context) (*TokenInfo, bool) {
+	info, ok := ctx.Value(tokenInfoKey).(*TokenInfo)
+	return info, ok && info != nil
+}
+
+// ResponseHeader returns the headers of the iLEAP response being served, or nil
+// outside of a footprint request. Service handlers can set the ETag validator
+// of GET /2/footprints and GET /2/footprints/{id} responses, and the
+// Last-Modified validator of GET /2/footprints/{id} responses in it, when
+// they can determine them more cheaply or more accurately than the server.
+// Other headers are ignored, and so are the validators of lists when the
+// server has an [AccessPolicy], since the list then differs from the
+// handler's.
+func ResponseHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(responseHeaderKey).(http.Header)
+	return header
+}
+
+func withResponseHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, responseHeaderKey, header)
+}
@@ -2,7 +2,9 @@ package ileap
 
 import (
 	"context"
+	"time"
 
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"golang.org/x/oauth2"
 )
 
@@ -19,3 +21,116 @@ type AuthHandler interface {
 	// JWKS returns the JSON Web Key Set.
 	JWKS() *JWKSet
 }
+
+// TokenRequestHandler is an optional AuthHandler capability for client
+// authentication methods beyond client secrets sent with HTTP basic auth:
+// private_key_jwt (RFC 7523) and mutual TLS (RFC 8705). When the AuthHandler
+// implements it, the server passes all token requests to HandleTokenRequest
+// instead of IssueToken.
+type TokenRequestHandler interface {
+	// HandleTokenRequest authenticates the client with the credentials of the
+	// request's AuthMethod and returns an access token. It must return
+	// connect.CodePermissionDenied when client authentication fails, and
+	// connect.CodeInvalidArgument when none of the requested scopes can be
+	// granted. It should bind tokens to the request's ClientCertificate, if
+	// any.
+	HandleTokenRequest(ctx context.Context, req *TokenRequest) (*oauth2.Token, error)
+}
+
+// TokenIntrospector is an optional AuthHandler capability for OAuth 2.0 token
+// introspection (RFC 7662). When the AuthHandler implements it, the server
+// serves POST /auth/introspect and advertises it in the OpenID configuration.
+type TokenIntrospector interface {
+	// IntrospectToken authenticates the client and returns the introspection
+	// response for the token. Invalid and expired tokens must be reported as
+	// inactive rather than as an error. It must return
+	// connect.CodePermissionDenied when client authentication fails.
+	IntrospectToken(
+		ctx context.Context,
+		clientID, clientSecret, token string,
+	) (*TokenIntrospection, error)
+}
+
+// TokenRevoker is an optional AuthHandler capability for OAuth 2.0 token
+// revocation (RFC 7009). When the AuthHandler implements it, the server serves
+// POST /auth/revoke, advertises it in the OpenID configuration, and adds
+// revoked tokens to its [RevocationList].
+type TokenRevoker interface {
+	// RevokeToken authenticates the client and checks that the token was
+	// issued to it. It returns the expiration time of the token, until which
+	// the server keeps it in the [RevocationList]. Invalid tokens need no
+	// revocation: for them, it returns the zero time and no error. It must
+	// return connect.CodePermissionDenied when client authentication fails,
+	// and connect.CodeInvalidArgument when the token was issued to another
+	// client.
+	RevokeToken(ctx context.Context, clientID, clientSecret, token string) (time.Time, error)
+}
+
+// RevocationList stores revoked access tokens. The server's auth middlewares
+// reject revoked tokens, and introspection reports them as inactive.
+type RevocationList interface {
+	// Revoke adds the token to the list. The token can be forgotten after
+	// expiresAt, the expiration time of the token.
+	Revoke(ctx context.Context, token string, expiresAt time.Time) error
+	// IsRevoked reports whether the token has been revoked.
+	IsRevoked(ctx context.Context, token string) (bool, error)
+}
+
+// RateLimitStore stores the token buckets of the server's rate limits, see
+// [WithRateLimit]. Buckets start full. Use a shared store when running several
+// server instances.
+type RateLimitStore interface {
+	// Take takes a token from the bucket with the key, if one is available. It
+	// returns zero if a token was taken, and otherwise how long until a token
+	// becomes available.
+	Take(ctx context.Context, key string, limit RateLimit) (time.Duration, error)
+	// Peek returns how long until a token of the bucket with the key becomes
+	// available, without taking it.
+	Peek(ctx context.Context, key string, limit RateLimit) (time.Duration, error)
+	// Reset refills the bucket with the key.
+	Reset(ctx context.Context, key string) error
+}
+
+// AuditSink receives the audit records of requests to the data endpoints, see
+// [WithAuditSink]. WriteAudit is called on the request's goroutine after the
+// response has been written, so slow sinks should buffer records. Errors are
+// logged and do not affect the response.
+type AuditSink interface {
+	// WriteAudit writes the audit record of a request.
+	WriteAudit(ctx context.Context, record *AuditRecord) error
+}
+
+// EventHandler handles PACT CloudEvents received on POST /2/events.
+//
+// Each method receives the decoded event and the [TokenInfo] of the
+// authenticated caller. Returned errors are mapped to HTTP responses using
+// their connect.Code, as for ILeapServiceHandler errors.
+type EventHandler interface {
+	// HandleRequestCreated handles a ProductFootprintRequest.Created.v1 event.
+	HandleRequestCreated(ctx context.Context, info *TokenInfo, event *RequestCreatedEvent) error
+	// HandleRequestFulfilled handles a ProductFootprintRequest.Fulfilled.v1 event.
+	HandleRequestFulfilled(ctx context.Context, info *TokenInfo, event *RequestFulfilledEvent) error
+	// HandleRequestRejected handles a ProductFootprintRequest.Rejected.v1 event.
+	HandleRequestRejected(ctx context.Context, info *TokenInfo, event *RequestRejectedEvent) error
+	// HandlePublished handles a ProductFootprint.Published.v1 event.
+	HandlePublished(ctx context.Context, info *TokenInfo, event *PublishedEvent) error
+}
+
+// AccessPolicy decides which footprints and TADs a caller may access, e.g. to
+// isolate the data of customers served from one deployment. PACT recommends
+// that host systems only return footprints the data owner has shared with the
+// recipient.
+//
+// Each method receives the [TokenInfo] of the authenticated caller. Returned
+// errors are mapped to HTTP responses using their connect.Code, as for
+// ILeapServiceHandler errors.
+type AccessPolicy interface {
+	// CanAccessFootprint reports whether the caller may access the footprint.
+	CanAccessFootprint(
+		ctx context.Context,
+		info *TokenInfo,
+		footprint *ileapv1.ProductFootprint,
+	) (bool, error)
+	// CanAccessTAD reports whether the caller may access the TAD.
+	CanAccessTAD(ctx context.Context, info *TokenInfo, tad *ileapv1.TAD) (bool, error)
+}
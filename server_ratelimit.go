@@ -0,0 +1,351 @@
+package ileap
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket rate limit. The bucket holds up to Burst tokens
+// and is refilled at Rate tokens per second. Each request takes a token. The
+// zero value disables the limit.
+type RateLimit struct {
+	// Rate is the number of tokens added to the bucket per second.
+	Rate float64
+	// Burst is the size of the bucket.
+	Burst int
+}
+
+// PerMinute returns a RateLimit of n requests per minute, allowing bursts of n
+// requests.
+func PerMinute(n int) RateLimit {
+	return RateLimit{Rate: float64(n) / 60, Burst: n}
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// LockoutPolicy locks out clients after repeated failed authentication
+// attempts on POST /auth/token, /auth/introspect, and /auth/revoke. Attempts are counted per client ID and IP
+// address, so that failed attempts from one address cannot lock out a client
+// that authenticates from another.
+//
+// After MaxFailures failed attempts, the client may make one further attempt
+// per Duration. A successful attempt resets the count.
+type LockoutPolicy struct {
+	// MaxFailures is the number of failed attempts allowed before lockout.
+	// Zero disables lockout.
+	MaxFailures int
+	// Duration is the time a locked out client waits for each further attempt.
+	Duration time.Duration
+}
+
+func (p LockoutPolicy) limit() RateLimit {
+	if p.MaxFailures <= 0 || p.Duration <= 0 {
+		return RateLimit{}
+	}
+	return RateLimit{Rate: 1 / p.Duration.Seconds(), Burst: p.MaxFailures}
+}
+
+// RateLimitConfig configures the rate limits of the server, see
+// [WithRateLimit].
+type RateLimitConfig struct {
+	// PerIP limits the requests from each client IP address to any endpoint.
+	PerIP RateLimit
+	// PerClient limits the requests of each client ID on POST /auth/token,
+	// /auth/introspect, and /auth/revoke, and the data requests of each
+	// authenticated token subject.
+	PerClient RateLimit
+	// Lockout locks out clients after repeated failed authentication attempts.
+	Lockout LockoutPolicy
+	// Store stores the token buckets. Defaults to a [MemoryRateLimitStore].
+	Store RateLimitStore
+	// ClientIP returns the IP address of a request. Defaults to the host of
+	// the request's RemoteAddr. Behind a reverse proxy, return the address the
+	// proxy reports instead.
+	ClientIP func(r *http.Request) string
+}
+
+// MemoryRateLimitStore is an in-memory [RateLimitStore]. Buckets are forgotten
+// once they are full again.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+// tokenBucket is a bucket of a MemoryRateLimitStore.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is the time at which the bucket is full again.
+	full time.Time
+}
+
+// NewMemoryRateLimitStore creates a new, empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// Take implements [RateLimitStore].
+func (s *MemoryRateLimitStore) Take(
+	_ context.Context,
+	key string,
+	limit RateLimit,
+) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.pruneLocked(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+	b.refill(limit, now)
+	if b.tokens < 1 {
+		return b.wait(limit), nil
+	}
+	b.tokens--
+	b.full = now.Add(seconds((float64(limit.Burst) - b.tokens) / limit.Rate))
+	return 0, nil
+}
+
+// Peek implements [RateLimitStore].
+func (s *MemoryRateLimitStore) Peek(
+	_ context.Context,
+	key string,
+	limit RateLimit,
+) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key]
+	if !ok {
+		return 0, nil
+	}
+	b.refill(limit, time.Now())
+	if b.tokens < 1 {
+		return b.wait(limit), nil
+	}
+	return 0, nil
+}
+
+// Reset implements [RateLimitStore].
+func (s *MemoryRateLimitStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets, key)
+	return nil
+}
+
+// pruneLocked removes the full buckets, at most once per minute.
+func (s *MemoryRateLimitStore) pruneLocked(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func (b *tokenBucket) refill(limit RateLimit, now time.Time) {
+	b.tokens = min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+}
+
+// wait returns how long until the bucket holds a token.
+func (b *tokenBucket) wait(limit RateLimit) time.Duration {
+	return seconds((1 - b.tokens) / limit.Rate)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// clientIP returns the IP address of the request.
+func (s *Server) clientIP(r *http.Request) string {
+	if s.rateLimit != nil && s.rateLimit.ClientIP != nil {
+		return s.rateLimit.ClientIP(r)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// takeToken takes a token from the bucket with the key. It returns the time
+// until the next token is available and true if the limit is exceeded. Store
+// errors are logged and do not limit the request.
+func (s *Server) takeToken(ctx context.Context, key string, limit RateLimit) (time.Duration, bool) {
+	if !limit.enabled() {
+		return 0, false
+	}
+	retryAfter, err := s.rateLimit.Store.Take(ctx, key, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check rate limit", "key", key, "error", err)
+		return 0, false
+	}
+	return retryAfter, retryAfter > 0
+}
+
+// ipRateLimitMiddleware limits the requests from each client IP address.
+// Exceeded limits are reported as OAuth errors on the auth endpoints, and as
+// PACT errors otherwise.
+func (s *Server) ipRateLimitMiddleware(next http.Handler, oauth bool) http.Handler {
+	if s.rateLimit == nil || !s.rateLimit.PerIP.enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := s.clientIP(r)
+		if retryAfter, limited := s.takeToken(r.Context(), "ip:"+ip, s.rateLimit.PerIP); limited {
+			slog.WarnContext(r.Context(), "rate limit exceeded", "ip", ip)
+			if oauth {
+				writeOAuthRateLimited(w, retryAfter)
+			} else {
+				writeRateLimited(w, retryAfter)
+			}
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientRateLimitMiddleware limits the requests of each authenticated token
+// subject. It must run after an auth middleware.
+func (s *Server) clientRateLimitMiddleware(next http.Handler) http.Handler {
+	if s.rateLimit == nil || !s.rateLimit.PerClient.enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := TokenInfoFromContext(r.Context())
+		if !ok || info.Subject == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := "client:" + info.Subject
+		if retryAfter, limited := s.takeToken(r.Context(), key, s.rateLimit.PerClient); limited {
+			slog.WarnContext(r.Context(), "rate limit exceeded", "subject", info.Subject)
+			writeRateLimited(w, retryAfter)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkTokenRequestLimits checks the lockout policy and the per-client rate
+// limit of a request to an auth endpoint, without charging them: they are
+// charged by [Server.recordAuthentication], once the outcome of the client
+// authentication is known, so that requests with invalid credentials cannot
+// exhaust the rate limit of a client. On exceeded limits, it writes an OAuth
+// error response and returns false.
+func (s *Server) checkTokenRequestLimits(
+	w http.ResponseWriter,
+	r *http.Request,
+	clientID string,
+) bool {
+	if s.rateLimit == nil {
+		return true
+	}
+	ctx := r.Context()
+	if retryAfter, limited := s.peekToken(
+		ctx,
+		s.lockoutKey(r, clientID),
+		s.rateLimit.Lockout.limit(),
+	); limited {
+		slog.WarnContext(ctx, "client locked out", "clientID", clientID)
+		writeOAuthRateLimited(w, retryAfter)
+		return false
+	}
+	if retryAfter, limited := s.peekToken(
+		ctx,
+		tokenRequestKey(clientID),
+		s.rateLimit.PerClient,
+	); limited {
+		slog.WarnContext(ctx, "rate limit exceeded", "clientID", clientID)
+		writeOAuthRateLimited(w, retryAfter)
+		return false
+	}
+	return true
+}
+
+// recordAuthentication charges the limits of a client after a request to an
+// auth endpoint: failed attempts count towards the lockout, and successful
+// attempts reset it and take a token from the per-client rate limit.
+func (s *Server) recordAuthentication(r *http.Request, clientID string, ok bool) {
+	if s.rateLimit == nil {
+		return
+	}
+	ctx := r.Context()
+	if ok {
+		s.takeToken(ctx, tokenRequestKey(clientID), s.rateLimit.PerClient)
+	}
+	lockout := s.rateLimit.Lockout.limit()
+	if !lockout.enabled() {
+		return
+	}
+	key := s.lockoutKey(r, clientID)
+	var err error
+	if ok {
+		err = s.rateLimit.Store.Reset(ctx, key)
+	} else {
+		_, err = s.rateLimit.Store.Take(ctx, key, lockout)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record authentication attempt", "error", err)
+	}
+}
+
+// peekToken reports whether the bucket with the key is empty, without taking
+// a token. It returns the time until the next token is available and true if
+// the limit is exceeded. Store errors are logged and do not limit the request.
+func (s *Server) peekToken(ctx context.Context, key string, limit RateLimit) (time.Duration, bool) {
+	if !limit.enabled() {
+		return 0, false
+	}
+	retryAfter, err := s.rateLimit.Store.Peek(ctx, key, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check rate limit", "key", key, "error", err)
+		return 0, false
+	}
+	return retryAfter, retryAfter > 0
+}
+
+func tokenRequestKey(clientID string) string {
+	return "token:" + clientID
+}
+
+func (s *Server) lockoutKey(r *http.Request, clientID string) string {
+	return "lockout:" + s.clientIP(r) + ":" + clientID
+}
+
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+func writeRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	setRetryAfter(w, retryAfter)
+	writeError(w, http.StatusTooManyRequests, ErrorCodeTooManyRequests, "too many requests")
+}
+
+func writeOAuthRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	setRetryAfter(w, retryAfter)
+	writeOAuthError(
+		w,
+		http.StatusTooManyRequests,
+		OAuthErrorCodeTemporarilyUnavailable,
+		"too many requests",
+	)
+}
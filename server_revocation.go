@@ -0,0 +1,280 @@
+package ileap
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// maxMemoryRevocations is the maximum number of unexpired tokens in a
+// [MemoryRevocationList].
+const maxMemoryRevocations = 100_000
+
+// MemoryRevocationList is an in-memory [RevocationList]. Tokens are stored as
+// SHA-256 hashes and forgotten once they expire. It holds up to 100,000
+// unexpired tokens; further revocations fail with connect.CodeResourceExhausted.
+type MemoryRevocationList struct {
+	mu      sync.Mutex
+	revoked map[[sha256.Size]byte]time.Time
+}
+
+var _ RevocationList = (*MemoryRevocationList)(nil)
+
+// NewMemoryRevocationList creates a new, empty MemoryRevocationList.
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{revoked: make(map[[sha256.Size]byte]time.Time)}
+}
+
+// Revoke implements [RevocationList]. Tokens that have already expired are
+// not stored.
+func (l *MemoryRevocationList) Revoke(_ context.Context, token string, expiresAt time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return nil
+	}
+	key := sha256.Sum256([]byte(token))
+	if _, ok := l.revoked[key]; !ok && len(l.revoked) >= maxMemoryRevocations {
+		for key, exp := range l.revoked {
+			if !exp.After(now) {
+				delete(l.revoked, key)
+			}
+		}
+		if len(l.revoked) >= maxMemoryRevocations {
+			return connect.NewError(
+				connect.CodeResourceExhausted,
+				errors.New("too many revoked tokens"),
+			)
+		}
+	}
+	l.revoked[key] = expiresAt
+	return nil
+}
+
+// IsRevoked implements [RevocationList].
+func (l *MemoryRevocationList) IsRevoked(_ context.Context, token string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	exp, ok := l.revoked[sha256.Sum256([]byte(token))]
+	return ok && exp.After(time.Now()), nil
+}
+
+// validateToken validates the token of the request with the auth handler, and
+// rejects revoked tokens and certificate-bound tokens presented without their
+// client certificate.
+func (s *Server) validateToken(r *http.Request, token string) (*TokenInfo, error) {
+	ctx := r.Context()
+	handlerCtx, end := s.startSpan(ctx, "AuthHandler.ValidateToken")
+	info, err := s.auth.ValidateToken(handlerCtx, token)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCertificateBinding(r, info); err != nil {
+		return nil, err
+	}
+	if s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, connect.NewError(connect.CodePermissionDenied, errors.New("token revoked"))
+		}
+	}
+	return info, nil
+}
+
+func (s *Server) authIntrospect(w http.ResponseWriter, r *http.Request) {
+	introspector, ok := s.auth.(TokenIntrospector)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
+		return
+	}
+	clientID, clientSecret, token, ok := parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkTokenRequestLimits(w, r, clientID) {
+		return
+	}
+	result, err := introspector.IntrospectToken(r.Context(), clientID, clientSecret, token)
+	s.recordTokenEndpointAuthentication(r, clientID, err)
+	if err != nil {
+		writeTokenEndpointError(w, r, "failed to introspect token", err)
+		return
+	}
+	if result.Active && s.revocations != nil {
+		revoked, err := s.revocations.IsRevoked(r.Context(), token)
+		if err != nil {
+			writeTokenEndpointError(w, r, "failed to introspect token", err)
+			return
+		}
+		if revoked {
+			result = &TokenIntrospection{Active: false}
+		}
+	}
+	if !result.Active {
+		result = &TokenIntrospection{Active: false}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, result)
+}
+
+func (s *Server) authRevoke(w http.ResponseWriter, r *http.Request) {
+	revoker, ok := s.auth.(TokenRevoker)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
+		return
+	}
+	clientID, clientSecret, token, ok := parseTokenRequest(w, r)
+	if !ok {
+		return
+	}
+	if !s.checkTokenRequestLimits(w, r, clientID) {
+		return
+	}
+	expiresAt, err := revoker.RevokeToken(r.Context(), clientID, clientSecret, token)
+	s.recordTokenEndpointAuthentication(r, clientID, err)
+	if err != nil {
+		writeTokenEndpointError(w, r, "failed to revoke token", err)
+		return
+	}
+	// Only valid tokens issued to the client are recorded, so that clients
+	// cannot fill the list with arbitrary strings.
+	if expiresAt.After(time.Now()) {
+		if err := s.revocations.Revoke(r.Context(), token, expiresAt); err != nil {
+			writeTokenEndpointError(w, r, "failed to revoke token", err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// recordTokenEndpointAuthentication updates the lockout state of a client
+// after an introspection or revocation request. Requests for tokens of other
+// clients still authenticate the client.
+func (s *Server) recordTokenEndpointAuthentication(r *http.Request, clientID string, err error) {
+	switch connect.CodeOf(err) {
+	case connect.CodePermissionDenied:
+		s.recordAuthentication(r, clientID, false)
+	case connect.CodeInvalidArgument:
+		s.recordAuthentication(r, clientID, true)
+	default:
+		if err == nil {
+			s.recordAuthentication(r, clientID, true)
+		}
+	}
+}
+
+// parseTokenRequest parses the client credentials and the token parameter of
+// an introspection or revocation request. Client credentials are accepted with
+// HTTP basic auth or as client_id and client_secret form parameters. On
+// failure, it writes an OAuth error response and returns false.
+func parseTokenRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+) (clientID, clientSecret, token string, ok bool) {
+	if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeInvalidRequest,
+			"invalid content type",
+		)
+		return "", "", "", false
+	}
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeInvalidRequest,
+			"invalid request body",
+		)
+		return "", "", "", false
+	}
+	if username, password, hasBasicAuth := r.BasicAuth(); hasBasicAuth {
+		clientID, clientSecret = unescapeCredential(username), unescapeCredential(password)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="ileap"`)
+		writeOAuthError(
+			w,
+			http.StatusUnauthorized,
+			OAuthErrorCodeInvalidClient,
+			"missing client authentication",
+		)
+		return "", "", "", false
+	}
+	token = r.PostForm.Get("token")
+	if token == "" {
+		writeOAuthError(w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest, "missing token")
+		return "", "", "", false
+	}
+	return clientID, clientSecret, token, true
+}
+
+// unescapeCredential decodes a client credential sent with HTTP basic auth,
+// which is form-urlencoded (RFC 6749, section 2.3.1).
+func unescapeCredential(s string) string {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		return s
+	}
+	return unescaped
+}
+
+// writeTokenEndpointError writes the OAuth error response for an auth handler
+// error on the introspection and revocation endpoints.
+func writeTokenEndpointError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	switch connect.CodeOf(err) {
+	case connect.CodeUnimplemented:
+		writeError(w, http.StatusNotImplemented, ErrorCodeNotImplemented, "not implemented")
+	case connect.CodePermissionDenied:
+		slog.WarnContext(r.Context(), msg, "error", err)
+		w.Header().Set("WWW-Authenticate", `Basic realm="ileap"`)
+		writeOAuthError(
+			w,
+			http.StatusUnauthorized,
+			OAuthErrorCodeInvalidClient,
+			"invalid client authentication",
+		)
+	case connect.CodeInvalidArgument:
+		slog.WarnContext(r.Context(), msg, "error", err)
+		writeOAuthError(
+			w,
+			http.StatusBadRequest,
+			OAuthErrorCodeUnauthorizedClient,
+			"token was not issued to the client",
+		)
+	case connect.CodeResourceExhausted:
+		slog.WarnContext(r.Context(), msg, "error", err)
+		writeOAuthError(
+			w,
+			http.StatusTooManyRequests,
+			OAuthErrorCodeTemporarilyUnavailable,
+			"temporarily unavailable",
+		)
+	case connect.CodeUnavailable:
+		slog.WarnContext(r.Context(), msg, "error", err)
+		writeOAuthError(
+			w,
+			http.StatusServiceUnavailable,
+			OAuthErrorCodeTemporarilyUnavailable,
+			"temporarily unavailable",
+		)
+	default:
+		slog.ErrorContext(r.Context(), msg, "error", err)
+		writeOAuthError(w, http.StatusInternalServerError, OAuthErrorCodeServerError, msg)
+	}
+}
@@ -0,0 +1,238 @@
+package ileap
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the OpenTelemetry instrumentation scope of the SDK.
+const instrumentationName = "github.com/way-platform/ileap-go"
+
+// Attribute keys of the server's spans and metrics.
+const (
+	attrEndpoint    = attribute.Key("ileap.endpoint")
+	attrAuthOutcome = attribute.Key("ileap.auth.outcome")
+	attrAuthMethod  = attribute.Key("ileap.auth.method")
+	attrFilterCount = attribute.Key("ileap.filter.count")
+	attrResultCount = attribute.Key("ileap.result.count")
+	attrHTTPMethod  = attribute.Key("http.request.method")
+	attrHTTPRoute   = attribute.Key("http.route")
+	attrHTTPStatus  = attribute.Key("http.response.status_code")
+)
+
+// Outcomes of authentication, recorded as the ileap.auth.outcome attribute.
+const (
+	authOutcomeSuccess = "success"
+	authOutcomeMissing = "missing"
+	authOutcomeInvalid = "invalid"
+	authOutcomeExpired = "expired"
+	authOutcomeError   = "error"
+)
+
+// WithTelemetry instruments the server with OpenTelemetry tracing and metrics.
+// Each request is traced in a server span, continuing the trace propagated in
+// the request headers, and the request duration, token requests and rejected
+// access tokens are recorded as metrics. The trace context is extracted with
+// the global propagator at the time the server is created, see
+// [otel.SetTextMapPropagator]. Nil providers default to the global providers.
+func WithTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) ServerOption {
+	return func(s *Server) {
+		if tp == nil {
+			tp = otel.GetTracerProvider()
+		}
+		if mp == nil {
+			mp = otel.GetMeterProvider()
+		}
+		s.telemetry = newServerTelemetry(tp, mp)
+	}
+}
+
+// serverTelemetry holds the OpenTelemetry instruments of a Server. Without
+// telemetry configured, the instruments are no-ops.
+type serverTelemetry struct {
+	tracer             trace.Tracer
+	propagator         propagation.TextMapPropagator
+	requestDuration    metric.Float64Histogram
+	tokenRequests      metric.Int64Counter
+	validationFailures metric.Int64Counter
+}
+
+func newServerTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *serverTelemetry {
+	meter := mp.Meter(instrumentationName)
+	t := &serverTelemetry{
+		tracer:     tp.Tracer(instrumentationName),
+		propagator: otel.GetTextMapPropagator(),
+	}
+	// Instrument creation only fails for invalid names, and returns a no-op
+	// instrument along with the error.
+	t.requestDuration, _ = meter.Float64Histogram(
+		"ileap.server.request.duration",
+		metric.WithDescription("Duration of iLEAP server requests."),
+		metric.WithUnit("s"),
+	)
+	t.tokenRequests, _ = meter.Int64Counter(
+		"ileap.server.token.requests",
+		metric.WithDescription("Token requests on the token endpoint, by outcome."),
+		metric.WithUnit("{request}"),
+	)
+	t.validationFailures, _ = meter.Int64Counter(
+		"ileap.server.token.validation.failures",
+		metric.WithDescription("Rejected access tokens, by outcome."),
+		metric.WithUnit("{token}"),
+	)
+	return t
+}
+
+// instrument wraps the handler of an endpoint with a server span and the
+// request duration metric. The incoming trace context is extracted from the
+// request headers.
+func (s *Server) instrument(endpoint string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := strings.TrimPrefix(r.Pattern, r.Method+" ")
+		ctx := s.telemetry.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := s.telemetry.tracer.Start(
+			ctx,
+			r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attrEndpoint.String(endpoint),
+				attrHTTPMethod.String(r.Method),
+				attrHTTPRoute.String(route),
+			),
+		)
+		defer span.End()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(attrHTTPStatus.Int(rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+		s.telemetry.requestDuration.Record(
+			ctx,
+			time.Since(start).Seconds(),
+			metric.WithAttributes(
+				attrEndpoint.String(endpoint),
+				attrHTTPMethod.String(r.Method),
+				attrHTTPStatus.Int(rec.status),
+			),
+		)
+	})
+}
+
+// recordAuthOutcome records the outcome of bearer token authentication on the
+// request span, and counts rejected tokens.
+func (s *Server) recordAuthOutcome(ctx context.Context, outcome string) {
+	trace.SpanFromContext(ctx).SetAttributes(attrAuthOutcome.String(outcome))
+	if outcome != authOutcomeSuccess {
+		s.telemetry.validationFailures.Add(
+			ctx,
+			1,
+			metric.WithAttributes(attrAuthOutcome.String(outcome)),
+		)
+	}
+}
+
+// recordTokenRequest records the outcome of a token request on the request
+// span, and counts token requests.
+func (s *Server) recordTokenRequest(ctx context.Context, method ClientAuthMethod, outcome string) {
+	attrs := []attribute.KeyValue{
+		attrAuthMethod.String(string(method)),
+		attrAuthOutcome.String(outcome),
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attrs...)
+	s.telemetry.tokenRequests.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// tokenRequestOutcome returns the auth outcome of a token request with the
+// error of the auth handler.
+func tokenRequestOutcome(err error) string {
+	switch {
+	case err == nil:
+		return authOutcomeSuccess
+	case connect.CodeOf(err) == connect.CodePermissionDenied:
+		return authOutcomeInvalid
+	default:
+		return authOutcomeError
+	}
+}
+
+// recordListAttributes records the number of filters and results of a list
+// request on the request span.
+func recordListAttributes(ctx context.Context, filterCount, resultCount int) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attrFilterCount.Int(filterCount),
+		attrResultCount.Int(resultCount),
+	)
+}
+
+// startSpan starts an internal span for a call to an auth or service handler,
+// to tell apart the time spent in the handlers from the time spent in the
+// server. Call end with the result of the call.
+func (s *Server) startSpan(ctx context.Context, name string) (context.Context, func(error)) {
+	ctx, span := s.telemetry.tracer.Start(ctx, name)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// tracedServiceHandler wraps an ILeapServiceHandler with a span per call.
+type tracedServiceHandler struct {
+	s    *Server
+	next ileapv1connect.ILeapServiceHandler
+}
+
+var _ ileapv1connect.ILeapServiceHandler = tracedServiceHandler{}
+
+func (h tracedServiceHandler) ListFootprints(
+	ctx context.Context,
+	req *ileapv1.ListFootprintsRequest,
+) (_ *ileapv1.ListFootprintsResponse, err error) {
+	ctx, end := h.s.startSpan(ctx, "ILeapService.ListFootprints")
+	defer func() { end(err) }()
+	return h.next.ListFootprints(ctx, req)
+}
+
+func (h tracedServiceHandler) GetFootprint(
+	ctx context.Context,
+	req *ileapv1.GetFootprintRequest,
+) (_ *ileapv1.GetFootprintResponse, err error) {
+	ctx, end := h.s.startSpan(ctx, "ILeapService.GetFootprint")
+	defer func() { end(err) }()
+	return h.next.GetFootprint(ctx, req)
+}
+
+func (h tracedServiceHandler) ListTransportActivityData(
+	ctx context.Context,
+	req *ileapv1.ListTransportActivityDataRequest,
+) (_ *ileapv1.ListTransportActivityDataResponse, err error) {
+	ctx, end := h.s.startSpan(ctx, "ILeapService.ListTransportActivityData")
+	defer func() { end(err) }()
+	return h.next.ListTransportActivityData(ctx, req)
+}
@@ -1,13 +1,27 @@
 package ileap
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -15,8 +29,17 @@ import (
 	"connectrpc.com/connect"
 	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
 	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // mockServiceHandler embeds the generated unimplemented handler and
@@ -150,6 +173,75 @@ func (m *mockAuthHandler) JWKS() *JWKSet {
 	}
 }
 
+// mockRevokingAuthHandler implements AuthHandler, TokenIntrospector and
+// TokenRevoker for tests. Tokens prefixed with "other-" belong to another client,
+// and tokens prefixed with "invalid-" are invalid.
+type mockRevokingAuthHandler struct {
+	mockAuthHandler
+}
+
+func (m *mockRevokingAuthHandler) IntrospectToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (*TokenIntrospection, error) {
+	if clientID != "hello" || clientSecret != "pathfinder" {
+		return nil, connect.NewError(
+			connect.CodePermissionDenied,
+			errors.New("invalid credentials"),
+		)
+	}
+	return &TokenIntrospection{Active: true, ClientID: "hello", Subject: "test-user"}, nil
+}
+
+func (m *mockRevokingAuthHandler) RevokeToken(
+	_ context.Context, clientID, clientSecret, token string,
+) (time.Time, error) {
+	if clientID != "hello" || clientSecret != "pathfinder" {
+		return time.Time{}, connect.NewError(
+			connect.CodePermissionDenied,
+			errors.New("invalid credentials"),
+		)
+	}
+	switch {
+	case strings.HasPrefix(token, "other-"):
+		return time.Time{}, connect.NewError(
+			connect.CodeInvalidArgument,
+			errors.New("not issued to client"),
+		)
+	case strings.HasPrefix(token, "invalid-"):
+		return time.Time{}, nil
+	}
+	return time.Now().Add(time.Hour), nil
+}
+
+// mockClientAuthHandler implements AuthHandler and TokenRequestHandler for
+// tests. Tokens issued to clients with a certificate are bound to it.
+type mockClientAuthHandler struct {
+	mockAuthHandler
+	request    *TokenRequest
+	thumbprint string
+}
+
+func (m *mockClientAuthHandler) HandleTokenRequest(
+	_ context.Context, req *TokenRequest,
+) (*oauth2.Token, error) {
+	m.request = req
+	if req.ClientID != "hello" {
+		return nil, connect.NewError(connect.CodePermissionDenied, errors.New("invalid client"))
+	}
+	if req.ClientCertificate != nil {
+		m.thumbprint = CertificateThumbprint(req.ClientCertificate)
+		return &oauth2.Token{AccessToken: "bound-token", TokenType: "bearer"}, nil
+	}
+	return &oauth2.Token{AccessToken: "mock-token", TokenType: "bearer"}, nil
+}
+
+func (m *mockClientAuthHandler) ValidateToken(_ context.Context, token string) (*TokenInfo, error) {
+	if token == "bound-token" {
+		return &TokenInfo{Subject: "hello", CertificateThumbprint: m.thumbprint}, nil
+	}
+	return &TokenInfo{Subject: "hello"}, nil
+}
+
 func newTestServer() *Server {
 	return NewServer(
 		WithAuthHandler(&mockAuthHandler{validToken: true}),
@@ -266,6 +358,22 @@ func TestAuthToken(t *testing.T) {
 		srv.ServeHTTP(w, req)
 		checkOAuthError(t, w, http.StatusTooManyRequests, OAuthErrorCodeTemporarilyUnavailable)
 	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		srv := authTestServer(WithAuthHandler(&mockAuthHandler{
+			issueErr: connect.NewError(connect.CodeInvalidArgument, errors.New("invalid scope")),
+		}))
+		req := httptest.NewRequest(
+			"POST",
+			"/auth/token",
+			strings.NewReader("grant_type=client_credentials&scope=other"),
+		)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("hello", "pathfinder")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidScope)
+	})
 }
 
 func TestVersionHeaderSuccess(t *testing.T) {
@@ -302,30 +410,1013 @@ func TestVersionHeaderError(t *testing.T) {
 	if len(values) != 1 {
 		t.Fatalf("expected 1 header value, got %d", len(values))
 	}
-	if got, want := values[0], buildVersionHeaderValue(); got != want {
-		t.Errorf("Way-ILeap-Go-Version = %q, want %q", got, want)
+	if got, want := values[0], buildVersionHeaderValue(); got != want {
+		t.Errorf("Way-ILeap-Go-Version = %q, want %q", got, want)
+	}
+}
+
+func TestOpenIDConfig(t *testing.T) {
+	srv := authTestServer()
+	req := httptest.NewRequest("GET", "/.well-known/openid-configuration", nil)
+	req.Host = "localhost:8080"
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var cfg OpenIDConfiguration
+	if err := json.NewDecoder(w.Body).Decode(&cfg); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if cfg.IssuerURL != "http://localhost:8080" {
+		t.Errorf("expected issuer http://localhost:8080, got %s", cfg.IssuerURL)
+	}
+	if cfg.JWKSURL != "http://localhost:8080/jwks" {
+		t.Errorf("expected jwks_uri http://localhost:8080/jwks, got %s", cfg.JWKSURL)
+	}
+}
+
+func TestTokenRevocation(t *testing.T) {
+	revocations := NewMemoryRevocationList()
+	srv := NewServer(
+		WithAuthHandler(&mockRevokingAuthHandler{mockAuthHandler{validToken: true}}),
+		WithServiceHandler(&mockServiceHandler{}),
+		WithRevocationList(revocations),
+	)
+	tokenRequest := func(path, body, clientID, clientSecret string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if clientID != "" {
+			req.SetBasicAuth(clientID, clientSecret)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	introspect := func(token string) TokenIntrospection {
+		t.Helper()
+		w := tokenRequest("/auth/introspect", "token="+token, "hello", "pathfinder")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result TokenIntrospection
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return result
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer token-1")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/2/ileap/tad"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before revocation, got %d: %s", w.Code, w.Body.String())
+	}
+	if result := introspect("token-1"); !result.Active || result.ClientID != "hello" {
+		t.Errorf("expected active token of hello, got %+v", result)
+	}
+
+	t.Run("invalid client", func(t *testing.T) {
+		w := tokenRequest("/auth/revoke", "token=token-1", "bad", "creds")
+		checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+		if w.Header().Get("WWW-Authenticate") == "" {
+			t.Error("expected WWW-Authenticate header")
+		}
+	})
+
+	t.Run("missing client authentication", func(t *testing.T) {
+		w := tokenRequest("/auth/introspect", "token=token-1", "", "")
+		checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		w := tokenRequest("/auth/revoke", "token_type_hint=access_token", "hello", "pathfinder")
+		checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest)
+	})
+
+	t.Run("token of another client", func(t *testing.T) {
+		w := tokenRequest("/auth/revoke", "token=other-token", "hello", "pathfinder")
+		checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeUnauthorizedClient)
+		if revoked, _ := revocations.IsRevoked(t.Context(), "other-token"); revoked {
+			t.Error("expected the token of another client not to be recorded")
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		w := tokenRequest("/auth/revoke", "token=invalid-token", "hello", "pathfinder")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if revoked, _ := revocations.IsRevoked(t.Context(), "invalid-token"); revoked {
+			t.Error("expected the invalid token not to be recorded")
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		w := tokenRequest(
+			"/auth/revoke",
+			"client_id=hello&client_secret=pathfinder&token=token-1",
+			"",
+			"",
+		)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		checkErrorResponse(t, get("/2/ileap/tad"), http.StatusForbidden, ErrorCodeAccessDenied)
+		checkErrorResponse(t, get("/2/footprints"), http.StatusUnauthorized, ErrorCodeBadRequest)
+		if result := introspect("token-1"); result.Active || result.ClientID != "" {
+			t.Errorf("expected inactive token, got %+v", result)
+		}
+		if result := introspect("token-2"); !result.Active {
+			t.Errorf("expected other tokens to stay active, got %+v", result)
+		}
+	})
+
+	t.Run("openid configuration", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/.well-known/openid-configuration", nil)
+		req.Host = "localhost:8080"
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		var cfg OpenIDConfiguration
+		if err := json.NewDecoder(w.Body).Decode(&cfg); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if cfg.IntrospectionURL != "http://localhost:8080/auth/introspect" {
+			t.Errorf("unexpected introspection_endpoint %q", cfg.IntrospectionURL)
+		}
+		if cfg.RevocationURL != "http://localhost:8080/auth/revoke" {
+			t.Errorf("unexpected revocation_endpoint %q", cfg.RevocationURL)
+		}
+	})
+}
+
+func newTestCertificate(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "hello"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func testAssertion(sub string) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ES256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString(
+		[]byte(fmt.Sprintf(`{"iss":%q,"sub":%q}`, sub, sub)),
+	)
+	return header + "." + payload + ".signature"
+}
+
+func TestClientAuthentication(t *testing.T) {
+	auth := &mockClientAuthHandler{}
+	srv := authTestServer(WithAuthHandler(auth))
+	cert := newTestCertificate(t)
+	tokenRequest := func(body string, modify func(*http.Request)) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(
+			"POST",
+			"/auth/token",
+			strings.NewReader("grant_type=client_credentials&"+body),
+		)
+		req.Host = "localhost:8080"
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if modify != nil {
+			modify(req)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	assertionBody := func(sub string) string {
+		return url.Values{
+			"client_assertion_type": {jwtBearerAssertionType},
+			"client_assertion":      {testAssertion(sub)},
+			"scope":                 {"footprints:read tad:read"},
+		}.Encode()
+	}
+	withCert := func(cert *x509.Certificate) func(*http.Request) {
+		return func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+	}
+
+	t.Run("private_key_jwt", func(t *testing.T) {
+		w := tokenRequest(assertionBody("hello"), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		req := auth.request
+		if req.AuthMethod != ClientAuthMethodPrivateKeyJWT || req.ClientID != "hello" {
+			t.Errorf("unexpected token request %+v", req)
+		}
+		if req.TokenEndpoint != "http://localhost:8080/auth/token" ||
+			req.Issuer != "http://localhost:8080" {
+			t.Errorf("unexpected audiences %q, %q", req.TokenEndpoint, req.Issuer)
+		}
+		if len(req.Scope) != 2 || req.Scope[1] != "tad:read" {
+			t.Errorf("unexpected scope %v", req.Scope)
+		}
+	})
+
+	t.Run("client_id does not match assertion", func(t *testing.T) {
+		w := tokenRequest("client_id=other&"+assertionBody("hello"), nil)
+		checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+	})
+
+	t.Run("unsupported assertion type", func(t *testing.T) {
+		w := tokenRequest("client_assertion_type=saml&client_assertion=x", nil)
+		checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest)
+	})
+
+	t.Run("multiple methods", func(t *testing.T) {
+		w := tokenRequest(assertionBody("hello"), func(req *http.Request) {
+			req.SetBasicAuth("hello", "pathfinder")
+		})
+		checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest)
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		w := tokenRequest(assertionBody("other"), nil)
+		checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+	})
+
+	t.Run("self-signed tls", func(t *testing.T) {
+		w := tokenRequest("client_id=hello", withCert(cert))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := auth.request.AuthMethod; got != ClientAuthMethodSelfSignedTLS {
+			t.Errorf("expected %s, got %s", ClientAuthMethodSelfSignedTLS, got)
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		w := tokenRequest("client_id=hello", func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			}
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := auth.request.AuthMethod; got != ClientAuthMethodTLS {
+			t.Errorf("expected %s, got %s", ClientAuthMethodTLS, got)
+		}
+	})
+
+	t.Run("unsupported by auth handler", func(t *testing.T) {
+		srv := authTestServer()
+		req := httptest.NewRequest(
+			"POST",
+			"/auth/token",
+			strings.NewReader("grant_type=client_credentials&"+assertionBody("hello")),
+		)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+	})
+}
+
+func TestCertificateBoundTokens(t *testing.T) {
+	cert, otherCert := newTestCertificate(t), newTestCertificate(t)
+	auth := &mockClientAuthHandler{thumbprint: CertificateThumbprint(cert)}
+	srv := authTestServer(
+		WithAuthHandler(auth),
+		WithServiceHandler(&mockServiceHandler{}),
+		WithClientCertificateHeader("X-Client-Cert"),
+	)
+	get := func(path string, cert *x509.Certificate) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer bound-token")
+		if cert != nil {
+			pemCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+			req.Header.Set("X-Client-Cert", url.PathEscape(string(pemCert)))
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/2/ileap/tad", cert); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with bound certificate, got %d: %s", w.Code, w.Body.String())
+	}
+	checkErrorResponse(t, get("/2/ileap/tad", nil), http.StatusForbidden, ErrorCodeAccessDenied)
+	checkErrorResponse(
+		t,
+		get("/2/ileap/tad", otherCert),
+		http.StatusForbidden,
+		ErrorCodeAccessDenied,
+	)
+	checkErrorResponse(
+		t,
+		get("/2/footprints", otherCert),
+		http.StatusUnauthorized,
+		ErrorCodeBadRequest,
+	)
+}
+
+func TestRateLimit(t *testing.T) {
+	newServer := func(cfg RateLimitConfig) *Server {
+		return authTestServer(WithRateLimit(cfg), WithServiceHandler(&mockServiceHandler{}))
+	}
+	tokenRequest := func(srv *Server, addr, id, secret string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(
+			"POST",
+			"/auth/token",
+			strings.NewReader("grant_type=client_credentials"),
+		)
+		req.RemoteAddr = addr
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(id, secret)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	checkRetryAfter := func(t *testing.T, w *httptest.ResponseRecorder, maxSeconds int) {
+		t.Helper()
+		value := w.Header().Get("Retry-After")
+		if seconds, err := strconv.Atoi(value); err != nil || seconds < 1 || seconds > maxSeconds {
+			t.Errorf("expected Retry-After between 1 and %d, got %q", maxSeconds, value)
+		}
+	}
+
+	t.Run("per IP", func(t *testing.T) {
+		srv := newServer(RateLimitConfig{PerIP: PerMinute(2)})
+		get := func(remoteAddr string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("GET", "/2/ileap/tad", nil)
+			req.RemoteAddr = remoteAddr
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			return w
+		}
+		for range 2 {
+			if w := get("192.0.2.1:1234"); w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		w := get("192.0.2.1:5678")
+		checkErrorResponse(t, w, http.StatusTooManyRequests, ErrorCodeTooManyRequests)
+		checkRetryAfter(t, w, 30)
+		if w := get("192.0.2.2:1234"); w.Code != http.StatusOK {
+			t.Fatalf("expected other IP to be allowed, got %d: %s", w.Code, w.Body.String())
+		}
+		w = tokenRequest(srv, "192.0.2.1:1234", "hello", "pathfinder")
+		checkOAuthError(t, w, http.StatusTooManyRequests, OAuthErrorCodeTemporarilyUnavailable)
+	})
+
+	t.Run("per client", func(t *testing.T) {
+		srv := newServer(RateLimitConfig{PerClient: PerMinute(1)})
+		if w := tokenRequest(
+			srv,
+			"192.0.2.1:1234",
+			"hello",
+			"pathfinder",
+		); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		w := tokenRequest(srv, "192.0.2.2:1234", "hello", "pathfinder")
+		checkOAuthError(t, w, http.StatusTooManyRequests, OAuthErrorCodeTemporarilyUnavailable)
+		checkRetryAfter(t, w, 60)
+	})
+
+	t.Run("per client ignores failed authentication", func(t *testing.T) {
+		srv := newServer(RateLimitConfig{PerClient: PerMinute(1)})
+		for range 3 {
+			w := tokenRequest(srv, "198.51.100.1:1234", "hello", "wrong")
+			checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest)
+		}
+		if w := tokenRequest(
+			srv,
+			"192.0.2.1:1234",
+			"hello",
+			"pathfinder",
+		); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("lockout", func(t *testing.T) {
+		srv := newServer(RateLimitConfig{
+			Lockout: LockoutPolicy{MaxFailures: 2, Duration: time.Hour},
+		})
+		for range 2 {
+			w := tokenRequest(srv, "192.0.2.1:1234", "hello", "wrong")
+			checkOAuthError(t, w, http.StatusBadRequest, OAuthErrorCodeInvalidRequest)
+		}
+		w := tokenRequest(srv, "192.0.2.1:1234", "hello", "pathfinder")
+		checkOAuthError(t, w, http.StatusTooManyRequests, OAuthErrorCodeTemporarilyUnavailable)
+		checkRetryAfter(t, w, 3600)
+		if w := tokenRequest(
+			srv,
+			"192.0.2.2:1234",
+			"hello",
+			"pathfinder",
+		); w.Code != http.StatusOK {
+			t.Fatalf("expected other IP to be allowed, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("lockout on introspection and revocation", func(t *testing.T) {
+		srv := NewServer(
+			WithAuthHandler(&mockRevokingAuthHandler{mockAuthHandler{validToken: true}}),
+			WithServiceHandler(&mockServiceHandler{}),
+			WithRateLimit(RateLimitConfig{
+				Lockout: LockoutPolicy{MaxFailures: 2, Duration: time.Hour},
+			}),
+		)
+		post := func(path, secret string) *httptest.ResponseRecorder {
+			body := strings.NewReader("grant_type=client_credentials&token=token-1")
+			req := httptest.NewRequest("POST", path, body)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("hello", secret)
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			return w
+		}
+		for _, path := range []string{"/auth/introspect", "/auth/revoke"} {
+			w := post(path, "wrong")
+			checkOAuthError(t, w, http.StatusUnauthorized, OAuthErrorCodeInvalidClient)
+		}
+		for _, path := range []string{"/auth/introspect", "/auth/revoke", "/auth/token"} {
+			w := post(path, "pathfinder")
+			if w.Code != http.StatusTooManyRequests {
+				t.Errorf("%s: expected 429 for locked out client, got %d", path, w.Code)
+			}
+		}
+	})
+
+	t.Run("lockout reset", func(t *testing.T) {
+		srv := newServer(RateLimitConfig{
+			Lockout: LockoutPolicy{MaxFailures: 2, Duration: time.Hour},
+		})
+		for _, secret := range []string{"wrong", "pathfinder", "wrong", "pathfinder"} {
+			w := tokenRequest(srv, "192.0.2.1:1234", "hello", secret)
+			if w.Code == http.StatusTooManyRequests {
+				t.Fatalf("expected successful attempts to reset the lockout: %s", w.Body.String())
+			}
+		}
+	})
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	ctx := t.Context()
+	limit := RateLimit{Rate: 1000, Burst: 1}
+	if wait, err := s.Take(ctx, "a", limit); err != nil || wait != 0 {
+		t.Fatalf("expected first token, got %v, %v", wait, err)
+	}
+	wait, err := s.Take(ctx, "a", limit)
+	if err != nil || wait <= 0 || wait > time.Millisecond {
+		t.Fatalf("expected wait of at most 1ms, got %v, %v", wait, err)
+	}
+	if peek, err := s.Peek(ctx, "a", limit); err != nil || peek <= 0 {
+		t.Fatalf("expected empty bucket, got %v, %v", peek, err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if peek, err := s.Peek(ctx, "a", limit); err != nil || peek != 0 {
+		t.Fatalf("expected refilled bucket, got %v, %v", peek, err)
+	}
+	if wait, err := s.Take(ctx, "b", RateLimit{Rate: 1, Burst: 1}); err != nil || wait != 0 {
+		t.Fatalf("expected separate bucket, got %v, %v", wait, err)
+	}
+	if err := s.Reset(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if wait, err := s.Take(ctx, "b", RateLimit{Rate: 1, Burst: 1}); err != nil || wait != 0 {
+		t.Fatalf("expected reset bucket, got %v, %v", wait, err)
+	}
+}
+
+func TestMemoryRevocationList(t *testing.T) {
+	l := NewMemoryRevocationList()
+	ctx := t.Context()
+	if err := l.Revoke(ctx, "expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Revoke(ctx, "active", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for token, want := range map[string]bool{"active": true, "expired": false, "other": false} {
+		if got, err := l.IsRevoked(ctx, token); err != nil || got != want {
+			t.Errorf("IsRevoked(%q) = %v, %v, want %v", token, got, err, want)
+		}
+	}
+
+	t.Run("full", func(t *testing.T) {
+		l := NewMemoryRevocationList()
+		for i := range maxMemoryRevocations - 1 {
+			l.revoked[[sha256.Size]byte{byte(i), byte(i >> 8), byte(i >> 16)}] = time.Now().
+				Add(time.Hour)
+		}
+		if err := l.Revoke(ctx, "last", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		err := l.Revoke(ctx, "overflow", time.Now().Add(time.Hour))
+		if connect.CodeOf(err) != connect.CodeResourceExhausted {
+			t.Errorf("expected ResourceExhausted for a full list, got %v", err)
+		}
+		if err := l.Revoke(ctx, "last", time.Now().Add(time.Hour)); err != nil {
+			t.Errorf("expected revoking a listed token again to succeed, got %v", err)
+		}
+	})
+}
+
+func TestTelemetry(t *testing.T) {
+	prevPropagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prevPropagator) })
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	newServer := func(auth AuthHandler) *Server {
+		service := &mockServiceHandler{}
+		for _, id := range []string{"fp-1", "fp-2"} {
+			fp := &ileapv1.ProductFootprint{}
+			fp.SetId(id)
+			service.footprints = append(service.footprints, fp)
+		}
+		return NewServer(WithTelemetry(tp, mp), WithAuthHandler(auth), WithServiceHandler(service))
+	}
+	findSpan := func(t *testing.T, name string) sdktrace.ReadOnlySpan {
+		t.Helper()
+		ended := spans.Ended()
+		for i := len(ended) - 1; i >= 0; i-- {
+			if ended[i].Name() == name {
+				return ended[i]
+			}
+		}
+		t.Fatalf("span %q not recorded", name)
+		return nil
+	}
+	checkAttributes := func(t *testing.T, span sdktrace.ReadOnlySpan, want ...attribute.KeyValue) {
+		t.Helper()
+		got := attribute.NewSet(span.Attributes()...)
+		for _, kv := range want {
+			if v, ok := got.Value(kv.Key); !ok || v != kv.Value {
+				t.Errorf(
+					"span %q: %s = %v, want %v",
+					span.Name(),
+					kv.Key,
+					v.Emit(),
+					kv.Value.Emit(),
+				)
+			}
+		}
+	}
+	counterValue := func(t *testing.T, name string, attrs ...attribute.KeyValue) int64 {
+		t.Helper()
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(t.Context(), &rm); err != nil {
+			t.Fatalf("collect metrics: %v", err)
+		}
+		want := attribute.NewSet(attrs...)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if m.Name != name || !ok {
+					continue
+				}
+				for _, dp := range sum.DataPoints {
+					if dp.Attributes.Equals(&want) {
+						return dp.Value
+					}
+				}
+			}
+		}
+		return 0
+	}
+
+	t.Run("list footprints", func(t *testing.T) {
+		srv := newServer(&mockAuthHandler{validToken: true})
+		traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+		req := httptest.NewRequest("GET", "/2/footprints?$filter="+
+			url.QueryEscape("companyName eq 'Acme'"), nil)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		span := findSpan(t, "GET /2/footprints")
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("expected trace ID %s from traceparent, got %s", traceID, got)
+		}
+		if span.SpanKind() != trace.SpanKindServer {
+			t.Errorf("expected server span, got %v", span.SpanKind())
+		}
+		checkAttributes(
+			t,
+			span,
+			attrEndpoint.String("ListFootprints"),
+			attrHTTPRoute.String("/2/footprints"),
+			attrHTTPStatus.Int(http.StatusOK),
+			attrAuthOutcome.String(authOutcomeSuccess),
+			attrFilterCount.Int(1),
+			attrResultCount.Int(2),
+		)
+		for _, name := range []string{"AuthHandler.ValidateToken", "ILeapService.ListFootprints"} {
+			if child := findSpan(t, name); child.Parent().SpanID() != span.SpanContext().SpanID() {
+				t.Errorf("expected %q to be a child of the request span", name)
+			}
+		}
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		srv := newServer(&mockAuthHandler{})
+		before := counterValue(
+			t,
+			"ileap.server.token.validation.failures",
+			attrAuthOutcome.String(authOutcomeInvalid),
+		)
+		req := httptest.NewRequest("GET", "/2/ileap/tad", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		checkErrorResponse(t, w, http.StatusForbidden, ErrorCodeAccessDenied)
+		checkAttributes(
+			t,
+			findSpan(t, "GET /2/ileap/tad"),
+			attrEndpoint.String("ListTADs"),
+			attrAuthOutcome.String(authOutcomeInvalid),
+			attrHTTPStatus.Int(http.StatusForbidden),
+		)
+		after := counterValue(
+			t,
+			"ileap.server.token.validation.failures",
+			attrAuthOutcome.String(authOutcomeInvalid),
+		)
+		if after != before+1 {
+			t.Errorf("expected validation failures to increase by 1, got %d to %d", before, after)
+		}
+	})
+
+	t.Run("token requests", func(t *testing.T) {
+		srv := newServer(&mockAuthHandler{validToken: true})
+		for _, secret := range []string{"pathfinder", "wrong"} {
+			req := httptest.NewRequest(
+				"POST",
+				"/auth/token",
+				strings.NewReader("grant_type=client_credentials"),
+			)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("hello", secret)
+			srv.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		for _, outcome := range []string{authOutcomeSuccess, authOutcomeInvalid} {
+			if got := counterValue(
+				t,
+				"ileap.server.token.requests",
+				attrAuthMethod.String(string(ClientAuthMethodSecretBasic)),
+				attrAuthOutcome.String(outcome),
+			); got != 1 {
+				t.Errorf("expected 1 token request with outcome %s, got %d", outcome, got)
+			}
+		}
+	})
+
+	t.Run("client propagation", func(t *testing.T) {
+		httpSrv := httptest.NewServer(newServer(&mockAuthHandler{validToken: true}))
+		t.Cleanup(httpSrv.Close)
+		client := NewClient(
+			WithBaseURL(httpSrv.URL),
+			WithReuseTokenAuth(&oauth2.Token{AccessToken: "valid", TokenType: "Bearer"}),
+			WithClientTelemetry(tp, mp),
+		)
+		if _, err := client.GetFootprint(
+			t.Context(),
+			&GetFootprintRequest{ID: "fp-1"},
+		); err != nil {
+			t.Fatalf("GetFootprint: %v", err)
+		}
+		serverSpan := findSpan(t, "GET /2/footprints/{id}")
+		var clientSpan sdktrace.ReadOnlySpan
+		for _, span := range spans.Ended() {
+			if span.SpanKind() == trace.SpanKindClient {
+				clientSpan = span
+			}
+		}
+		if clientSpan == nil {
+			t.Fatal("expected a client span")
+		}
+		if serverSpan.Parent().SpanID() != clientSpan.SpanContext().SpanID() {
+			t.Error("expected the server span to be a child of the client span")
+		}
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	service := &mockServiceHandler{}
+	for _, id := range []string{"fp-1", "fp-2"} {
+		fp := &ileapv1.ProductFootprint{}
+		fp.SetId(id)
+		fp.SetVersion(3)
+		service.footprints = append(service.footprints, fp)
+	}
+	tad := &ileapv1.TAD{}
+	tad.SetActivityId("tad-1")
+	service.tads = []*ileapv1.TAD{tad}
+	srv := authTestServer(
+		WithServiceHandler(service),
+		WithAuditSink(NewJSONLinesAuditSink(&buf)),
+	)
+	lastRecord := func(t *testing.T) AuditRecord {
+		t.Helper()
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+			t.Fatalf("decode audit record: %v", err)
+		}
+		return record
+	}
+	get := func(t *testing.T, target, auth string) AuditRecord {
+		t.Helper()
+		req := httptest.NewRequest("GET", target, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+		return lastRecord(t)
+	}
+
+	t.Run("list footprints", func(t *testing.T) {
+		filter := "pcf/geographyCountry eq 'DE'"
+		record := get(t, "/2/footprints?$filter="+url.QueryEscape(filter), "Bearer token")
+		want := AuditRecord{
+			Method:   "GET",
+			Path:     "/2/footprints",
+			Status:   http.StatusOK,
+			Subject:  "test-user",
+			ClientIP: "192.0.2.1",
+			Filter:   filter,
+			Filters: []AuditFilter{
+				{FieldPath: "pcf.geographyCountry", Operator: "EQ", Value: "DE"},
+			},
+			Footprints: []AuditFootprint{{ID: "fp-1", Version: 3}, {ID: "fp-2", Version: 3}},
+		}
+		checkAuditRecord(t, record, want)
+	})
+
+	t.Run("get footprint", func(t *testing.T) {
+		record := get(t, "/2/footprints/fp-2", "Bearer token")
+		checkAuditRecord(t, record, AuditRecord{
+			Method:     "GET",
+			Path:       "/2/footprints/fp-2",
+			Status:     http.StatusOK,
+			Subject:    "test-user",
+			ClientIP:   "192.0.2.1",
+			Footprints: []AuditFootprint{{ID: "fp-2", Version: 3}},
+		})
+	})
+
+	t.Run("list TADs", func(t *testing.T) {
+		record := get(t, "/2/ileap/tad?mode=Road", "Bearer token")
+		checkAuditRecord(t, record, AuditRecord{
+			Method:   "GET",
+			Path:     "/2/ileap/tad",
+			Status:   http.StatusOK,
+			Subject:  "test-user",
+			ClientIP: "192.0.2.1",
+			Filters:  []AuditFilter{{FieldPath: "mode", Operator: "EQ", Value: "Road"}},
+			TADs:     []string{"tad-1"},
+		})
+	})
+
+	t.Run("event", func(t *testing.T) {
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprint.Published.v1",` +
+			`"specversion":"1.0","id":"evt-1","source":"test","data":{"pfIds":[]}}`
+		req := httptest.NewRequest("POST", "/2/events", strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+		srv.ServeHTTP(httptest.NewRecorder(), req)
+		checkAuditRecord(t, lastRecord(t), AuditRecord{
+			Method:    "POST",
+			Path:      "/2/events",
+			Status:    http.StatusOK,
+			Subject:   "test-user",
+			ClientIP:  "192.0.2.1",
+			EventType: "org.wbcsd.pathfinder.ProductFootprint.Published.v1",
+			EventID:   "evt-1",
+		})
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		record := get(t, "/2/footprints", "")
+		checkAuditRecord(t, record, AuditRecord{
+			Method:   "GET",
+			Path:     "/2/footprints",
+			Status:   http.StatusBadRequest,
+			ClientIP: "192.0.2.1",
+		})
+	})
+
+	t.Run("auth endpoints not audited", func(t *testing.T) {
+		before := buf.Len()
+		get(t, "/jwks", "")
+		if buf.Len() != before {
+			t.Errorf("expected no audit record for /jwks, got %s", buf.String()[before:])
+		}
+	})
+
+	t.Run("file sink", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		for range 2 {
+			sink, err := OpenJSONLinesAuditSink(path)
+			if err != nil {
+				t.Fatalf("OpenJSONLinesAuditSink: %v", err)
+			}
+			if err := sink.WriteAudit(
+				t.Context(),
+				&AuditRecord{Path: "/2/footprints"},
+			); err != nil {
+				t.Fatalf("WriteAudit: %v", err)
+			}
+			if err := sink.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read audit log: %v", err)
+		}
+		if lines := strings.Count(string(data), "\n"); lines != 2 {
+			t.Errorf("expected records to be appended, got %d lines:\n%s", lines, data)
+		}
+	})
+}
+
+// checkAuditRecord compares an audit record with the expected record,
+// ignoring the time and duration.
+func checkAuditRecord(t *testing.T, got, want AuditRecord) {
+	t.Helper()
+	if got.Time.IsZero() || got.Duration <= 0 {
+		t.Errorf("expected time and duration to be set, got %v and %v", got.Time, got.Duration)
+	}
+	got.Time, got.Duration = time.Time{}, 0
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("audit record mismatch:\n got: %s\nwant: %s", gotJSON, wantJSON)
 	}
 }
 
-func TestOpenIDConfig(t *testing.T) {
-	srv := authTestServer()
-	req := httptest.NewRequest("GET", "/.well-known/openid-configuration", nil)
-	req.Host = "localhost:8080"
-	w := httptest.NewRecorder()
-	srv.ServeHTTP(w, req)
-	if w.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
-	}
-	var cfg OpenIDConfiguration
-	if err := json.NewDecoder(w.Body).Decode(&cfg); err != nil {
-		t.Fatalf("decode: %v", err)
+// validatorServiceHandler sets the validators of its footprint responses.
+type validatorServiceHandler struct {
+	*mockServiceHandler
+	etag string
+}
+
+func (m *validatorServiceHandler) GetFootprint(
+	ctx context.Context, req *ileapv1.GetFootprintRequest,
+) (*ileapv1.GetFootprintResponse, error) {
+	if header := ResponseHeader(ctx); header != nil {
+		header.Set("ETag", m.etag)
 	}
-	if cfg.IssuerURL != "http://localhost:8080" {
-		t.Errorf("expected issuer http://localhost:8080, got %s", cfg.IssuerURL)
+	return m.mockServiceHandler.GetFootprint(ctx, req)
+}
+
+func TestConditionalRequests(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := created.Add(24 * time.Hour)
+	service := &mockServiceHandler{}
+	for _, id := range []string{"fp-1", "fp-2"} {
+		fp := &ileapv1.ProductFootprint{}
+		fp.SetId(id)
+		fp.SetCreated(timestamppb.New(created))
+		service.footprints = append(service.footprints, fp)
 	}
-	if cfg.JWKSURL != "http://localhost:8080/jwks" {
-		t.Errorf("expected jwks_uri http://localhost:8080/jwks, got %s", cfg.JWKSURL)
+	service.footprints[1].SetUpdated(timestamppb.New(updated))
+	srv := authTestServer(WithServiceHandler(service))
+	get := func(
+		t *testing.T,
+		srv *Server,
+		target string,
+		header ...string,
+	) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
 	}
+
+	t.Run("validators", func(t *testing.T) {
+		w := get(t, srv, "/2/footprints/fp-1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if want := computeETag(w.Body.Bytes()); w.Header().Get("ETag") != want {
+			t.Errorf("ETag = %q, want %q", w.Header().Get("ETag"), want)
+		}
+		if want := created.Format(http.TimeFormat); w.Header().Get("Last-Modified") != want {
+			t.Errorf("Last-Modified = %q, want %q", w.Header().Get("Last-Modified"), want)
+		}
+		w = get(t, srv, "/2/footprints/fp-2")
+		if want := updated.Format(http.TimeFormat); w.Header().Get("Last-Modified") != want {
+			t.Errorf("Last-Modified = %q, want %q", w.Header().Get("Last-Modified"), want)
+		}
+		w = get(t, srv, "/2/footprints")
+		if got := w.Header().Get("Last-Modified"); got != "" {
+			t.Errorf("expected no Last-Modified for lists, got %q", got)
+		}
+	})
+
+	t.Run("if-none-match", func(t *testing.T) {
+		for _, target := range []string{"/2/footprints", "/2/footprints/fp-1"} {
+			etag := get(t, srv, target).Header().Get("ETag")
+			for _, ifNoneMatch := range []string{etag, `"other", W/` + etag, "*"} {
+				w := get(t, srv, target, "If-None-Match", ifNoneMatch)
+				if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+					t.Errorf("%s If-None-Match %s: expected empty 304, got %d: %s",
+						target, ifNoneMatch, w.Code, w.Body.String())
+				}
+				if w.Header().Get("ETag") != etag {
+					t.Errorf("304 ETag = %q, want %q", w.Header().Get("ETag"), etag)
+				}
+			}
+			w := get(t, srv, target, "If-None-Match", `"other"`)
+			if w.Code != http.StatusOK {
+				t.Errorf("%s: expected 200 for changed ETag, got %d", target, w.Code)
+			}
+		}
+	})
+
+	t.Run("if-modified-since", func(t *testing.T) {
+		for _, tc := range []struct {
+			since time.Time
+			want  int
+		}{
+			{updated, http.StatusNotModified},
+			{updated.Add(time.Hour), http.StatusNotModified},
+			{updated.Add(-time.Second), http.StatusOK},
+		} {
+			since := tc.since.Format(http.TimeFormat)
+			w := get(t, srv, "/2/footprints/fp-2", "If-Modified-Since", since)
+			if w.Code != tc.want {
+				t.Errorf("If-Modified-Since %s: expected %d, got %d", since, tc.want, w.Code)
+			}
+		}
+		// Lists are only validated by their ETag.
+		since := updated.Add(time.Hour).Format(http.TimeFormat)
+		if w := get(t, srv, "/2/footprints", "If-Modified-Since", since); w.Code != http.StatusOK {
+			t.Errorf("list If-Modified-Since %s: expected 200, got %d", since, w.Code)
+		}
+		// If-None-Match takes precedence over If-Modified-Since.
+		w := get(t, srv, "/2/footprints/fp-2",
+			"If-None-Match", `"other"`,
+			"If-Modified-Since", updated.Format(http.TimeFormat))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200 for changed ETag, got %d", w.Code)
+		}
+	})
+
+	t.Run("handler validators", func(t *testing.T) {
+		srv := authTestServer(WithServiceHandler(&validatorServiceHandler{
+			mockServiceHandler: service,
+			etag:               `"v1"`,
+		}))
+		w := get(t, srv, "/2/footprints/fp-1")
+		if w.Header().Get("ETag") != `"v1"` {
+			t.Errorf("ETag = %q, want %q", w.Header().Get("ETag"), `"v1"`)
+		}
+		if w := get(t, srv, "/2/footprints/fp-1", "If-None-Match", `"v1"`); w.Code != 304 {
+			t.Errorf("expected 304, got %d", w.Code)
+		}
+	})
 }
 
 func TestJWKS(t *testing.T) {
@@ -691,6 +1782,101 @@ func TestListFootprints(t *testing.T) {
 		if len(got) != 0 {
 			t.Fatalf("expected 0 translated filters, got %d", len(got))
 		}
+		if handler.lastListFootprintsReq.HasFilter() {
+			t.Error("expected no filter expression for invalid odata")
+		}
+	})
+
+	t.Run("odata filter translated to filter expression", func(t *testing.T) {
+		filter := url.QueryEscape(
+			"productCategoryCpc eq '83117' or not (pcf/declaredUnit in ('liter', 'kilogram'))",
+		)
+		req := httptest.NewRequest("GET", "/2/footprints?$filter="+filter, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		expr := handler.lastListFootprintsReq.GetFilter()
+		if got := expr.GetOr().GetOperands(); len(got) != 2 {
+			t.Fatalf("expected or expression with 2 operands, got %v", expr)
+		}
+		if got := handler.lastListFootprintsReq.GetFilters(); len(got) != 0 {
+			t.Errorf("expected no filter pairs for a top-level or, got %v", got)
+		}
+	})
+}
+
+func TestWithStrictFiltering(t *testing.T) {
+	handler := &mockServiceHandler{}
+	srv := NewServer(
+		WithAuthHandler(&mockAuthHandler{validToken: true}),
+		WithServiceHandler(handler),
+		WithStrictFiltering(),
+	)
+	testCases := []struct {
+		name       string
+		filter     string
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		{
+			name:       "invalid syntax",
+			filter:     "productCategoryCpc eq",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrorCodeBadRequest,
+		},
+		{
+			name:       "unbalanced parentheses",
+			filter:     "(productCategoryCpc eq '83117'",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ErrorCodeBadRequest,
+		},
+		{
+			name:       "unsupported function",
+			filter:     "contains(productDescription, 'steel')",
+			wantStatus: http.StatusNotImplemented,
+			wantCode:   ErrorCodeNotImplemented,
+		},
+		{
+			name:       "unsupported lambda",
+			filter:     "productIds/all(p:p eq 'x')",
+			wantStatus: http.StatusNotImplemented,
+			wantCode:   ErrorCodeNotImplemented,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(
+				"GET",
+				"/2/footprints?$filter="+url.QueryEscape(tc.filter),
+				nil,
+			)
+			req.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			checkErrorResponse(t, w, tc.wantStatus, tc.wantCode)
+		})
+	}
+
+	t.Run("valid filter", func(t *testing.T) {
+		filter := url.QueryEscape(
+			"pcf/pCfExcludingBiogenic gt 1.5 and updated ge 2024-01-01T00:00:00Z",
+		)
+		req := httptest.NewRequest("GET", "/2/footprints?$filter="+filter, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		assertFootprintFilterSet(
+			t,
+			handler.lastListFootprintsReq.GetFilters(),
+			"pcf.pCfExcludingBiogenic|GT|1.5",
+			"updated|GE|2024-01-01T00:00:00Z",
+		)
 	})
 }
 
@@ -774,6 +1960,150 @@ func TestGetFootprint(t *testing.T) {
 	})
 }
 
+// mockAccessPolicy allows the test user to access the listed records.
+type mockAccessPolicy struct {
+	footprints map[string]bool
+	tads       map[string]bool
+	err        error
+}
+
+func (m *mockAccessPolicy) CanAccessFootprint(
+	_ context.Context, info *TokenInfo, footprint *ileapv1.ProductFootprint,
+) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	return info.Subject == "test-user" && m.footprints[footprint.GetId()], nil
+}
+
+func (m *mockAccessPolicy) CanAccessTAD(
+	_ context.Context, info *TokenInfo, tad *ileapv1.TAD,
+) (bool, error) {
+	return info.Subject == "test-user" && m.tads[tad.GetActivityId()], nil
+}
+
+func TestWithAccessPolicy(t *testing.T) {
+	handler := &mockServiceHandler{}
+	for _, id := range []string{"fp-1", "fp-2", "fp-3", "fp-4"} {
+		pf := &ileapv1.ProductFootprint{}
+		pf.SetId(id)
+		handler.footprints = append(handler.footprints, pf)
+	}
+	for _, id := range []string{"tad-1", "tad-2"} {
+		tad := &ileapv1.TAD{}
+		tad.SetActivityId(id)
+		handler.tads = append(handler.tads, tad)
+	}
+	policy := &mockAccessPolicy{
+		footprints: map[string]bool{"fp-2": true, "fp-3": true, "fp-4": true},
+		tads:       map[string]bool{"tad-2": true},
+	}
+	srv := NewServer(
+		WithAuthHandler(&mockAuthHandler{validToken: true}),
+		WithServiceHandler(handler),
+		WithAccessPolicy(policy),
+	)
+	do := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Host = "example.com"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+	ids := func(t *testing.T, w *httptest.ResponseRecorder, idField string) []string {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Data []map[string]any `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var result []string
+		for _, item := range resp.Data {
+			result = append(result, fmt.Sprint(item[idField]))
+		}
+		return result
+	}
+
+	t.Run("list footprints paginates accessible footprints", func(t *testing.T) {
+		w := do("/2/footprints?limit=2")
+		if got := ids(t, w, "id"); strings.Join(got, ",") != "fp-2,fp-3" {
+			t.Errorf("got %v, want [fp-2 fp-3]", got)
+		}
+		want := `<https://example.com/2/footprints?limit=2&offset=2>; rel="next"`
+		if got := w.Header().Get("Link"); got != want {
+			t.Errorf("Link = %q, want %q", got, want)
+		}
+		if handler.lastListFootprintsReq.GetLimit() != 0 {
+			t.Errorf("expected the server to request all footprints")
+		}
+		w = do("/2/footprints?limit=2&offset=2")
+		if req := handler.lastListFootprintsReq; req.GetLimit() != 0 || req.GetOffset() != 0 {
+			t.Errorf("got limit %d and offset %d, want all footprints",
+				req.GetLimit(), req.GetOffset())
+		}
+		if got := ids(t, w, "id"); strings.Join(got, ",") != "fp-4" {
+			t.Errorf("got %v, want [fp-4]", got)
+		}
+		if got := w.Header().Get("Link"); got != "" {
+			t.Errorf("expected no Link header on last page, got %q", got)
+		}
+	})
+
+	t.Run("get footprint", func(t *testing.T) {
+		if w := do("/2/footprints/fp-2"); w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		checkErrorResponse(
+			t,
+			do("/2/footprints/fp-1"),
+			http.StatusNotFound,
+			ErrorCodeNoSuchFootprint,
+		)
+	})
+
+	t.Run("list TADs", func(t *testing.T) {
+		w := do("/2/ileap/tad?limit=1&offset=0")
+		if got := ids(t, w, "activityId"); strings.Join(got, ",") != "tad-2" {
+			t.Errorf("got %v, want [tad-2]", got)
+		}
+		if req := handler.lastListTADReq; req.GetLimit() != 0 || req.GetOffset() != 0 {
+			t.Errorf("got limit %d and offset %d, want all TADs",
+				req.GetLimit(), req.GetOffset())
+		}
+	})
+
+	t.Run("policy error", func(t *testing.T) {
+		policy.err = errors.New("policy store down")
+		defer func() { policy.err = nil }()
+		checkErrorResponse(
+			t,
+			do("/2/footprints"),
+			http.StatusInternalServerError,
+			ErrorCodeInternalError,
+		)
+	})
+}
+
+func TestTokenInfoFromContext(t *testing.T) {
+	if _, ok := TokenInfoFromContext(context.Background()); ok {
+		t.Error("expected no token info in empty context")
+	}
+	info := &TokenInfo{Subject: "test-user", Scopes: []string{"footprints:read"}}
+	got, ok := TokenInfoFromContext(WithTokenInfo(context.Background(), info))
+	if !ok || got != info {
+		t.Errorf("got %v, %v, want %v", got, ok, info)
+	}
+	if !got.HasScope("footprints:read") || got.HasScope("tad:read") {
+		t.Errorf("unexpected HasScope results for %v", got.Scopes)
+	}
+}
+
 func TestListTads(t *testing.T) {
 	handler := &mockServiceHandler{
 		tads: []*ileapv1.TAD{
@@ -835,6 +2165,21 @@ func TestListTads(t *testing.T) {
 		}
 	})
 
+	t.Run("pagination link header preserves filters", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/2/ileap/tad?limit=1&mode=Road", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		got := w.Header().Get("Link")
+		want := `<http://example.com/2/ileap/tad?limit=1&mode=Road&offset=1>; rel="next"`
+		if got != want {
+			t.Errorf("Link = %q, want %q", got, want)
+		}
+	})
+
 	t.Run("no link header when all data returned", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/2/ileap/tad?limit=10", nil)
 		req.Header.Set("Authorization", "Bearer valid")
@@ -925,6 +2270,18 @@ func TestEvents(t *testing.T) {
 		checkErrorResponse(t, w, http.StatusBadRequest, ErrorCodeBadRequest)
 	})
 
+	t.Run("payload not decoded without event handler", func(t *testing.T) {
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprintRequest.Created.v1","specversion":"1.0","id":"evt-5","source":"https://webhook.example.com","time":"2024-06-01","data":{"comment":"Please send PCF data."}}`
+		req := httptest.NewRequest("POST", "/2/events", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
 	t.Run("missing content type", func(t *testing.T) {
 		req := httptest.NewRequest("POST", "/2/events", strings.NewReader("{}"))
 		req.Header.Set("Authorization", "Bearer valid")
@@ -992,6 +2349,170 @@ func TestEventsValidationMissingFields(t *testing.T) {
 	}
 }
 
+// recordingEventHandler records received events for tests.
+type recordingEventHandler struct {
+	NopEventHandler
+	err       error
+	info      *TokenInfo
+	created   *RequestCreatedEvent
+	fulfilled *RequestFulfilledEvent
+	rejected  *RequestRejectedEvent
+	published *PublishedEvent
+}
+
+func (h *recordingEventHandler) HandleRequestCreated(
+	_ context.Context,
+	info *TokenInfo,
+	e *RequestCreatedEvent,
+) error {
+	h.info, h.created = info, e
+	return h.err
+}
+
+func (h *recordingEventHandler) HandleRequestFulfilled(
+	_ context.Context,
+	info *TokenInfo,
+	e *RequestFulfilledEvent,
+) error {
+	h.info, h.fulfilled = info, e
+	return h.err
+}
+
+func (h *recordingEventHandler) HandleRequestRejected(
+	_ context.Context,
+	info *TokenInfo,
+	e *RequestRejectedEvent,
+) error {
+	h.info, h.rejected = info, e
+	return h.err
+}
+
+func (h *recordingEventHandler) HandlePublished(
+	_ context.Context,
+	info *TokenInfo,
+	e *PublishedEvent,
+) error {
+	h.info, h.published = info, e
+	return h.err
+}
+
+func TestEventHandler(t *testing.T) {
+	postEvent := func(t *testing.T, handler EventHandler, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		srv := NewServer(
+			WithAuthHandler(&mockAuthHandler{validToken: true}),
+			WithEventHandler(handler),
+		)
+		req := httptest.NewRequest("POST", "/2/events", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid")
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("request created", func(t *testing.T) {
+		handler := &recordingEventHandler{}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprintRequest.Created.v1","specversion":"1.0","id":"evt-1","source":"https://webhook.example.com","time":"2024-06-01T12:00:00Z","data":{"pf":{"productIds":["urn:gtin:4712345060507"]},"comment":"Please send PCF data."}}`
+		w := postEvent(t, handler, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if handler.created == nil {
+			t.Fatal("expected HandleRequestCreated to be called")
+		}
+		if handler.info == nil || handler.info.Subject != "test-user" {
+			t.Errorf("expected token info for test-user, got %+v", handler.info)
+		}
+		if got := handler.created.ID; got != "evt-1" {
+			t.Errorf("ID = %q, want evt-1", got)
+		}
+		if got := handler.created.Source; got != "https://webhook.example.com" {
+			t.Errorf("Source = %q", got)
+		}
+		if want := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC); !handler.created.Time.Equal(want) {
+			t.Errorf("Time = %v, want %v", handler.created.Time, want)
+		}
+		if got := handler.created.Footprint.GetProductIds(); len(got) != 1 ||
+			got[0] != "urn:gtin:4712345060507" {
+			t.Errorf("ProductIds = %v", got)
+		}
+		if got := handler.created.Comment; got != "Please send PCF data." {
+			t.Errorf("Comment = %q", got)
+		}
+	})
+
+	t.Run("request fulfilled", func(t *testing.T) {
+		handler := &recordingEventHandler{}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprintRequest.Fulfilled.v1","specversion":"1.0","id":"evt-2","source":"https://owner.example.com","data":{"requestEventId":"evt-1","pfs":[{"id":"91715e5e-fd0b-4d1c-8fab-76290c46e6ed"}]}}`
+		w := postEvent(t, handler, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if handler.fulfilled == nil {
+			t.Fatal("expected HandleRequestFulfilled to be called")
+		}
+		if got := handler.fulfilled.RequestEventID; got != "evt-1" {
+			t.Errorf("RequestEventID = %q, want evt-1", got)
+		}
+		if got := handler.fulfilled.Footprints; len(got) != 1 ||
+			got[0].GetId() != "91715e5e-fd0b-4d1c-8fab-76290c46e6ed" {
+			t.Errorf("Footprints = %v", got)
+		}
+	})
+
+	t.Run("request rejected", func(t *testing.T) {
+		handler := &recordingEventHandler{}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprintRequest.Rejected.v1","specversion":"1.0","id":"evt-3","source":"https://owner.example.com","data":{"requestEventId":"evt-1","error":{"code":"NotFound","message":"no footprints"}}}`
+		w := postEvent(t, handler, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if handler.rejected == nil {
+			t.Fatal("expected HandleRequestRejected to be called")
+		}
+		if got := handler.rejected.Error; got == nil || got.Code != ErrorCodeNotFound ||
+			got.Message != "no footprints" {
+			t.Errorf("Error = %+v", got)
+		}
+	})
+
+	t.Run("published", func(t *testing.T) {
+		handler := &recordingEventHandler{}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprint.Published.v1","specversion":"1.0","id":"evt-4","source":"https://owner.example.com","data":{"pfIds":["91715e5e-fd0b-4d1c-8fab-76290c46e6ed"]}}`
+		w := postEvent(t, handler, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if handler.published == nil {
+			t.Fatal("expected HandlePublished to be called")
+		}
+		if got := handler.published.FootprintIDs; len(got) != 1 ||
+			got[0] != "91715e5e-fd0b-4d1c-8fab-76290c46e6ed" {
+			t.Errorf("FootprintIDs = %v", got)
+		}
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		handler := &recordingEventHandler{}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprintRequest.Created.v1","specversion":"1.0","id":"evt-5","source":"https://webhook.example.com","data":{"comment":"missing pf"}}`
+		w := postEvent(t, handler, body)
+		checkErrorResponse(t, w, http.StatusBadRequest, ErrorCodeBadRequest)
+		if handler.created != nil {
+			t.Error("expected handler not to be called")
+		}
+	})
+
+	t.Run("handler error", func(t *testing.T) {
+		handler := &recordingEventHandler{
+			err: connect.NewError(connect.CodePermissionDenied, errors.New("not allowed")),
+		}
+		body := `{"type":"org.wbcsd.pathfinder.ProductFootprint.Published.v1","specversion":"1.0","id":"evt-6","source":"https://owner.example.com","data":{"pfIds":[]}}`
+		w := postEvent(t, handler, body)
+		checkErrorResponse(t, w, http.StatusForbidden, ErrorCodeAccessDenied)
+	})
+}
+
 func TestNotImplemented(t *testing.T) {
 	t.Run("data handlers with auth configured", func(t *testing.T) {
 		srv := NewServer(
@@ -1072,6 +2593,17 @@ func TestNotImplemented(t *testing.T) {
 			srv.ServeHTTP(w, req)
 			checkErrorResponse(t, w, http.StatusNotImplemented, ErrorCodeNotImplemented)
 		})
+
+		for _, path := range []string{"/auth/introspect", "/auth/revoke"} {
+			t.Run(path, func(t *testing.T) {
+				req := httptest.NewRequest("POST", path, strings.NewReader("token=any-token"))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.SetBasicAuth("user", "pass")
+				w := httptest.NewRecorder()
+				srv.ServeHTTP(w, req)
+				checkErrorResponse(t, w, http.StatusNotImplemented, ErrorCodeNotImplemented)
+			})
+		}
 	})
 }
 
@@ -1,9 +1,121 @@
 package ileap
 
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"slices"
+)
+
 // TokenInfo contains information extracted from a validated token.
 type TokenInfo struct {
 	// Subject is the subject (user) of the token.
 	Subject string
+	// Scopes are the scopes granted to the token.
+	Scopes []string
+	// Audience are the intended recipients of the token.
+	Audience []string
+	// Organization is the organization (tenant) the token was issued for.
+	Organization string
+	// Claims are all claims of the token, for auth handlers that issue JWTs.
+	Claims map[string]any
+	// CertificateThumbprint is the SHA-256 thumbprint of the client certificate
+	// the token is bound to (RFC 8705), see [CertificateThumbprint]. The server
+	// rejects bound tokens presented without the certificate.
+	CertificateThumbprint string
+}
+
+// HasScope reports whether the token was granted the scope.
+func (t *TokenInfo) HasScope(scope string) bool {
+	return t != nil && slices.Contains(t.Scopes, scope)
+}
+
+// TokenIntrospection is an OAuth 2.0 token introspection response (RFC 7662).
+type TokenIntrospection struct {
+	// Active reports whether the token is currently active. All other fields
+	// are omitted for inactive tokens.
+	Active bool `json:"active"`
+	// Scope is the space-separated list of scopes of the token.
+	Scope string `json:"scope,omitempty"`
+	// ClientID is the ID of the client the token was issued to.
+	ClientID string `json:"client_id,omitempty"`
+	// Username is a human-readable identifier of the resource owner.
+	Username string `json:"username,omitempty"`
+	// TokenType is the type of the token, e.g. "Bearer".
+	TokenType string `json:"token_type,omitempty"`
+	// ExpiresAt is the Unix timestamp of the token expiration.
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// IssuedAt is the Unix timestamp of the token issuance.
+	IssuedAt int64 `json:"iat,omitempty"`
+	// NotBefore is the Unix timestamp before which the token is not valid.
+	NotBefore int64 `json:"nbf,omitempty"`
+	// Subject is the subject of the token.
+	Subject string `json:"sub,omitempty"`
+	// Audience are the intended recipients of the token.
+	Audience []string `json:"aud,omitempty"`
+	// Issuer is the issuer of the token.
+	Issuer string `json:"iss,omitempty"`
+	// JWTID is the identifier of the token.
+	JWTID string `json:"jti,omitempty"`
+	// Confirmation binds the token to a client certificate (RFC 8705).
+	Confirmation *TokenConfirmation `json:"cnf,omitempty"`
+}
+
+// TokenConfirmation is the confirmation (cnf) claim of a certificate-bound
+// access token (RFC 8705, section 3.1).
+type TokenConfirmation struct {
+	// CertificateThumbprint is the SHA-256 thumbprint of the client certificate.
+	CertificateThumbprint string `json:"x5t#S256,omitempty"`
+}
+
+// ClientAuthMethod is an OAuth 2.0 client authentication method.
+type ClientAuthMethod string
+
+const (
+	// ClientAuthMethodSecretBasic authenticates the client with a client secret
+	// sent with HTTP basic auth.
+	ClientAuthMethodSecretBasic ClientAuthMethod = "client_secret_basic"
+	// ClientAuthMethodPrivateKeyJWT authenticates the client with a JWT client
+	// assertion signed with its private key (RFC 7523).
+	ClientAuthMethodPrivateKeyJWT ClientAuthMethod = "private_key_jwt"
+	// ClientAuthMethodTLS authenticates the client with a TLS client certificate
+	// that was verified against a trusted CA (RFC 8705, section 2.1).
+	ClientAuthMethodTLS ClientAuthMethod = "tls_client_auth"
+	// ClientAuthMethodSelfSignedTLS authenticates the client with a TLS client
+	// certificate registered with the client (RFC 8705, section 2.2).
+	ClientAuthMethodSelfSignedTLS ClientAuthMethod = "self_signed_tls_client_auth"
+)
+
+// TokenRequest is a client credentials token request received on
+// POST /auth/token.
+type TokenRequest struct {
+	// AuthMethod is the client authentication method of the request.
+	AuthMethod ClientAuthMethod
+	// ClientID is the ID the client claims. It is only authenticated once the
+	// credentials of the AuthMethod have been verified.
+	ClientID string
+	// ClientSecret is the client secret, for client_secret_basic.
+	ClientSecret string
+	// ClientAssertion is the JWT client assertion, for private_key_jwt.
+	ClientAssertion string
+	// ClientCertificate is the TLS client certificate, if the client presented
+	// one. Tokens issued to clients with a certificate should be bound to it.
+	ClientCertificate *x509.Certificate
+	// Scope is the requested scope.
+	Scope []string
+	// Issuer is the issuer URL of the server, an accepted audience of client
+	// assertions.
+	Issuer string
+	// TokenEndpoint is the URL of the token endpoint, the audience of client
+	// assertions.
+	TokenEndpoint string
+}
+
+// CertificateThumbprint returns the SHA-256 thumbprint of a certificate, as
+// used in the x5t#S256 confirmation method of certificate-bound tokens.
+func CertificateThumbprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
 }
 
 // OpenIDConfiguration is an OpenID Connect discovery document.
@@ -14,9 +126,19 @@ type OpenIDConfiguration struct {
 	DeviceAuthURL          string   `json:"device_authorization_endpoint,omitempty"`
 	UserInfoURL            string   `json:"userinfo_endpoint,omitempty"`
 	JWKSURL                string   `json:"jwks_uri"`
+	IntrospectionURL       string   `json:"introspection_endpoint,omitempty"`
+	RevocationURL          string   `json:"revocation_endpoint,omitempty"`
 	Algorithms             []string `json:"id_token_signing_alg_values_supported"`
 	ResponseTypesSupported []string `json:"response_types_supported"`
 	SubjectTypesSupported  []string `json:"subject_types_supported"`
+
+	// TokenEndpointAuthMethods are the supported client authentication methods.
+	TokenEndpointAuthMethods []ClientAuthMethod `json:"token_endpoint_auth_methods_supported,omitempty"`
+	// TokenEndpointAuthAlgorithms are the supported client assertion algorithms.
+	TokenEndpointAuthAlgorithms []string `json:"token_endpoint_auth_signing_alg_values_supported,omitempty"`
+	// CertificateBoundAccessTokens reports support for certificate-bound access
+	// tokens (RFC 8705).
+	CertificateBoundAccessTokens bool `json:"tls_client_certificate_bound_access_tokens,omitempty"`
 }
 
 // JWKSet is a JSON Web Key Set.
@@ -30,6 +152,14 @@ type JWK struct {
 	Use       string `json:"use,omitempty"`
 	Algorithm string `json:"alg,omitempty"`
 	KeyID     string `json:"kid,omitempty"`
-	N         string `json:"n"`
-	E         string `json:"e"`
+	// N is the modulus of an RSA key.
+	N string `json:"n,omitempty"`
+	// E is the exponent of an RSA key.
+	E string `json:"e,omitempty"`
+	// Curve is the curve of an EC or OKP key, e.g. "P-256" or "Ed25519".
+	Curve string `json:"crv,omitempty"`
+	// X is the x coordinate of an EC key, or the public key of an OKP key.
+	X string `json:"x,omitempty"`
+	// Y is the y coordinate of an EC key.
+	Y string `json:"y,omitempty"`
 }
@@ -6,6 +6,8 @@ import (
 
 	"connectrpc.com/connect"
 	"github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1/ileapv1connect"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
 	"golang.org/x/oauth2"
 )
 
@@ -42,4 +44,18 @@ func (s *Server) setDefaults() {
 	if s.auth == nil {
 		s.auth = unimplementedAuthHandler{}
 	}
+	if _, ok := s.auth.(TokenRevoker); ok && s.revocations == nil {
+		s.revocations = NewMemoryRevocationList()
+	}
+	if s.rateLimit != nil && s.rateLimit.Store == nil {
+		s.rateLimit.Store = NewMemoryRateLimitStore()
+	}
+	if s.telemetry == nil {
+		s.telemetry = newServerTelemetry(
+			tracenoop.NewTracerProvider(),
+			metricnoop.NewMeterProvider(),
+		)
+	} else {
+		s.service = tracedServiceHandler{s: s, next: s.service}
+	}
 }
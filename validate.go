@@ -0,0 +1,214 @@
+package ileap
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"buf.build/go/protovalidate"
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ValidationError is returned by [Validate] when a message violates the
+// validation rules of the iLEAP data model.
+type ValidationError struct {
+	// Violations are the rule violations found in the message.
+	Violations []*Violation
+}
+
+// Violation is a single validation rule violation.
+type Violation struct {
+	// Path is the path of the violating field, using JSON field names,
+	// e.g. "extensions[0].data.tces[1].co2eWTW".
+	Path string `json:"path"`
+	// RuleID identifies the violated rule, e.g. "string.pattern".
+	RuleID string `json:"ruleId"`
+	// Message is a human readable description of the violation.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid iLEAP message: ")
+	for i, v := range e.Violations {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(v.String())
+	}
+	return b.String()
+}
+
+// String returns the violation formatted as "path: message".
+func (v *Violation) String() string {
+	message := v.Message
+	if message == "" {
+		message = "[" + v.RuleID + "]"
+	}
+	if v.Path == "" {
+		return message
+	}
+	return v.Path + ": " + message
+}
+
+var getValidator = sync.OnceValues(func() (protovalidate.Validator, error) {
+	return protovalidate.New()
+})
+
+// Validate checks a message against the validation rules of the iLEAP data model.
+//
+// For a [ileapv1.ProductFootprint], the data of iLEAP extensions is decoded to
+// its typed ShipmentFootprint, TOC or HOC form and validated as well.
+// Violations are reported as a [*ValidationError].
+func Validate(msg proto.Message) error {
+	return validatePrefixed(msg, "")
+}
+
+// validateData validates the data of a single-message response.
+// Violation paths are prefixed with "data".
+func validateData(msg proto.Message) error {
+	return validatePrefixed(msg, "data")
+}
+
+func validatePrefixed(msg proto.Message, prefix string) error {
+	violations, err := validateMessage(msg, prefix)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// validateAll validates a list of messages, such as the data of a list response.
+// Violation paths are prefixed with the index of the message, e.g. "data[2]".
+func validateAll[T proto.Message](msgs []T) error {
+	var violations []*Violation
+	for i, msg := range msgs {
+		msgViolations, err := validateMessage(msg, "data["+strconv.Itoa(i)+"]")
+		if err != nil {
+			return err
+		}
+		violations = append(violations, msgViolations...)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func validateMessage(msg proto.Message, prefix string) ([]*Violation, error) {
+	validator, err := getValidator()
+	if err != nil {
+		return nil, fmt.Errorf("create validator: %w", err)
+	}
+	var violations []*Violation
+	if err := validator.Validate(msg); err != nil {
+		var validationErr *protovalidate.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("validate: %w", err)
+		}
+		desc := msg.ProtoReflect().Descriptor()
+		for _, v := range validationErr.Violations {
+			violations = append(violations, &Violation{
+				Path:    joinPath(prefix, jsonFieldPath(desc, v.Proto.GetField())),
+				RuleID:  v.Proto.GetRuleId(),
+				Message: v.Proto.GetMessage(),
+			})
+		}
+	}
+	if pf, ok := msg.(*ileapv1.ProductFootprint); ok {
+		for i, ext := range pf.GetExtensions() {
+			extViolations, err := validateExtension(
+				ext,
+				joinPath(prefix, "extensions["+strconv.Itoa(i)+"].data"),
+			)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, extViolations...)
+		}
+	}
+	return violations, nil
+}
+
+// validateExtension validates the data of an iLEAP extension. Extensions with
+// other data schemas are not validated.
+func validateExtension(ext *ileapv1.DataModelExtension, path string) ([]*Violation, error) {
+	data := newExtensionData(ext.GetDataSchema())
+	if data == nil {
+		return nil, nil
+	}
+	if err := decodeExtensionData(ext, data); err != nil {
+		return []*Violation{
+			{
+				Path:   path,
+				RuleID: "ileap.extension.data",
+				Message: fmt.Sprintf(
+					"invalid %s data: %v",
+					data.ProtoReflect().Descriptor().Name(),
+					err,
+				),
+			},
+		}, nil
+	}
+	return validateMessage(data, path)
+}
+
+// jsonFieldPath formats a protovalidate field path using JSON field names.
+func jsonFieldPath(desc protoreflect.MessageDescriptor, path *validate.FieldPath) string {
+	var b strings.Builder
+	for _, element := range path.GetElements() {
+		name := element.GetFieldName()
+		var field protoreflect.FieldDescriptor
+		if desc != nil {
+			field = desc.Fields().ByNumber(protoreflect.FieldNumber(element.GetFieldNumber()))
+		}
+		if field != nil {
+			name = field.JSONName()
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(name)
+		switch element.WhichSubscript() {
+		case validate.FieldPathElement_Index_case:
+			b.WriteString("[" + strconv.FormatUint(element.GetIndex(), 10) + "]")
+		case validate.FieldPathElement_StringKey_case:
+			b.WriteString("[" + strconv.Quote(element.GetStringKey()) + "]")
+		case validate.FieldPathElement_IntKey_case:
+			b.WriteString("[" + strconv.FormatInt(element.GetIntKey(), 10) + "]")
+		case validate.FieldPathElement_UintKey_case:
+			b.WriteString("[" + strconv.FormatUint(element.GetUintKey(), 10) + "]")
+		case validate.FieldPathElement_BoolKey_case:
+			b.WriteString("[" + strconv.FormatBool(element.GetBoolKey()) + "]")
+		}
+		switch {
+		case field == nil:
+			desc = nil
+		case field.IsMap():
+			desc = field.MapValue().Message()
+		default:
+			desc = field.Message()
+		}
+	}
+	return b.String()
+}
+
+func joinPath(prefix, path string) string {
+	switch {
+	case prefix == "":
+		return path
+	case path == "":
+		return prefix
+	default:
+		return prefix + "." + path
+	}
+}
@@ -0,0 +1,170 @@
+package ileap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ileapv1 "github.com/way-platform/ileap-go/proto/gen/wayplatform/connect/ileap/v1"
+)
+
+// newTestTCE returns the first TCE of the fixture shipment footprint.
+func newTestTCE(t *testing.T) *ileapv1.TCE {
+	t.Helper()
+	return newTestShipment(t).GetTces()[0]
+}
+
+// newTestShipmentFootprint returns a footprint with the fixture shipment
+// footprint extension, with its TCEs replaced by tce.
+func newTestShipmentFootprint(t *testing.T, tce *ileapv1.TCE) *ileapv1.ProductFootprint {
+	t.Helper()
+	sf := newTestShipment(t)
+	sf.SetTces([]*ileapv1.TCE{tce})
+	ext, err := NewShipmentFootprintExtension(sf)
+	if err != nil {
+		t.Fatalf("create extension: %v", err)
+	}
+	pf := &ileapv1.ProductFootprint{}
+	pf.SetExtensions([]*ileapv1.DataModelExtension{ext})
+	return pf
+}
+
+func assertViolations(t *testing.T, err error, want ...Violation) {
+	t.Helper()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	for _, w := range want {
+		found := false
+		for _, got := range validationErr.Violations {
+			if got.Path == w.Path && got.RuleID == w.RuleID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing violation %s [%s] in %v", w.Path, w.RuleID, validationErr)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid TCE", func(t *testing.T) {
+		if err := Validate(newTestTCE(t)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("malformed decimal", func(t *testing.T) {
+		tce := newTestTCE(t)
+		tce.SetCo2EWtw("10,5")
+		assertViolations(t, Validate(tce), Violation{Path: "co2eWTW", RuleID: "string.pattern"})
+	})
+
+	t.Run("missing tocId and hocId", func(t *testing.T) {
+		tce := newTestTCE(t)
+		tce.ClearTocId()
+		assertViolations(t, Validate(tce), Violation{Path: "", RuleID: "toc_id_or_hoc_id"})
+	})
+
+	t.Run("invalid in list", func(t *testing.T) {
+		tce := newTestTCE(t)
+		tce.SetIncoterms("XYZ")
+		assertViolations(t, Validate(tce), Violation{Path: "incoterms", RuleID: "string.in"})
+	})
+
+	t.Run("nested message", func(t *testing.T) {
+		tce := newTestTCE(t)
+		tce.GetDistance().SetActual("far")
+		assertViolations(
+			t,
+			Validate(tce),
+			Violation{Path: "distance.actual", RuleID: "string.pattern"},
+		)
+	})
+
+	t.Run("shipment footprint extension", func(t *testing.T) {
+		tce := newTestTCE(t)
+		tce.SetCo2EWtw("n/a")
+		pf := newTestShipmentFootprint(t, tce)
+		assertViolations(
+			t,
+			Validate(pf),
+			Violation{Path: "extensions[0].data.tces[0].co2eWTW", RuleID: "string.pattern"},
+		)
+	})
+}
+
+func TestWithResponseValidation(t *testing.T) {
+	invalid := newTestTCE(t)
+	invalid.SetCo2EWtw("n/a")
+	invalidFootprint := newTestShipmentFootprint(t, invalid)
+	invalidFootprint.SetId("fp-invalid")
+	handler := &mockServiceHandler{footprints: []*ileapv1.ProductFootprint{invalidFootprint}}
+
+	t.Run("disabled", func(t *testing.T) {
+		srv := NewServer(
+			WithAuthHandler(&mockAuthHandler{validToken: true}),
+			WithServiceHandler(handler),
+		)
+		req := httptest.NewRequest("GET", "/2/footprints", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		srv := NewServer(
+			WithAuthHandler(&mockAuthHandler{validToken: true}),
+			WithServiceHandler(handler),
+			WithResponseValidation(),
+		)
+		for _, path := range []string{"/2/footprints", "/2/footprints/fp-invalid"} {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", "Bearer valid")
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+			checkErrorResponse(t, w, http.StatusInternalServerError, ErrorCodeInternalError)
+			if strings.Contains(w.Body.String(), "co2eWTW") {
+				t.Errorf("expected generic error, got %s", w.Body.String())
+			}
+		}
+	})
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	invalid := newTestTCE(t)
+	invalid.SetCo2EWtw("n/a")
+	invalidFootprint := newTestShipmentFootprint(t, invalid)
+	invalidFootprint.SetId("fp-invalid")
+	handler := &mockServiceHandler{footprints: []*ileapv1.ProductFootprint{invalidFootprint}}
+
+	t.Run("lenient", func(t *testing.T) {
+		client := newPaginationTestClient(t, handler)
+		if _, err := client.ListFootprints(t.Context(), &ListFootprintsParams{}); err != nil {
+			t.Fatalf("ListFootprints: %v", err)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		client := newPaginationTestClient(t, handler, WithStrictDecoding(true))
+		_, err := client.ListFootprints(t.Context(), &ListFootprintsParams{})
+		assertViolations(
+			t,
+			err,
+			Violation{Path: "data[0].extensions[0].data.tces[0].co2eWTW", RuleID: "string.pattern"},
+		)
+		_, err = client.GetFootprint(t.Context(), &GetFootprintRequest{ID: "fp-invalid"})
+		assertViolations(
+			t,
+			err,
+			Violation{Path: "data.extensions[0].data.tces[0].co2eWTW", RuleID: "string.pattern"},
+		)
+	})
+}